@@ -0,0 +1,148 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// ACLTokens is used to bootstrap the ACL system and manage ACL tokens.
+type ACLTokens struct {
+	client *Client
+}
+
+// ACLTokens returns a handle on the ACL token endpoints.
+func (c *Client) ACLTokens() *ACLTokens {
+	return &ACLTokens{client: c}
+}
+
+// Bootstrap is used to create the initial management token.
+func (a *ACLTokens) Bootstrap(q *WriteOptions) (*structs.ACLToken, *WriteMeta, error) {
+	var resp structs.ACLToken
+
+	wm, err := a.client.write("POST", "/v1/acl/bootstrap", nil, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, wm, nil
+}
+
+// List is used to query every token, without their secret IDs.
+func (a *ACLTokens) List(q *QueryOptions) ([]*structs.ACLToken, *QueryMeta, error) {
+	var resp []*structs.ACLToken
+
+	qm, err := a.client.query("/v1/acl/tokens", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, qm, nil
+}
+
+// Info is used to query the token with the accessor ID provided.
+func (a *ACLTokens) Info(accessorID string, q *QueryOptions) (*structs.ACLToken, *QueryMeta, error) {
+	var resp structs.ACLToken
+
+	qm, err := a.client.query(aclTokenPath(accessorID), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, qm, nil
+}
+
+// Self is used to query the token of the client.
+func (a *ACLTokens) Self(q *QueryOptions) (*structs.ACLToken, *QueryMeta, error) {
+	return a.Info("self", q)
+}
+
+// Create is used to create a token. The accessor and secret IDs of the token
+// are generated by the agent.
+func (a *ACLTokens) Create(token *structs.ACLToken, q *WriteOptions) (*structs.ACLToken, *WriteMeta, error) {
+	var resp structs.ACLToken
+
+	wm, err := a.client.write("POST", "/v1/acl/token", token, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, wm, nil
+}
+
+// Update is used to update the name and policies of the token with the
+// accessor ID of the token provided.
+func (a *ACLTokens) Update(token *structs.ACLToken, q *WriteOptions) (*structs.ACLToken, *WriteMeta, error) {
+	var resp structs.ACLToken
+
+	wm, err := a.client.write("POST", aclTokenPath(token.AccessorID), token, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, wm, nil
+}
+
+// Delete is used to delete the token with the accessor ID provided.
+func (a *ACLTokens) Delete(accessorID string, q *WriteOptions) (*WriteMeta, error) {
+	return a.client.write("DELETE", aclTokenPath(accessorID), nil, nil, q)
+}
+
+// ACLPolicies is used to manage ACL policies.
+type ACLPolicies struct {
+	client *Client
+}
+
+// ACLPolicies returns a handle on the ACL policy endpoints.
+func (c *Client) ACLPolicies() *ACLPolicies {
+	return &ACLPolicies{client: c}
+}
+
+// List is used to query every policy.
+func (a *ACLPolicies) List(q *QueryOptions) ([]*structs.ACLPolicy, *QueryMeta, error) {
+	var resp []*structs.ACLPolicy
+
+	qm, err := a.client.query("/v1/acl/policies", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, qm, nil
+}
+
+// Info is used to query the policy with the name provided.
+func (a *ACLPolicies) Info(name string, q *QueryOptions) (*structs.ACLPolicy, *QueryMeta, error) {
+	var resp structs.ACLPolicy
+
+	qm, err := a.client.query(aclPolicyPath(name), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, qm, nil
+}
+
+// Upsert is used to create or replace a policy.
+func (a *ACLPolicies) Upsert(policy *structs.ACLPolicy, q *WriteOptions) (*structs.ACLPolicy, *WriteMeta, error) {
+	var resp structs.ACLPolicy
+
+	wm, err := a.client.write("POST", aclPolicyPath(policy.Name), policy, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, wm, nil
+}
+
+// Delete is used to delete the policy with the name provided.
+func (a *ACLPolicies) Delete(name string, q *WriteOptions) (*WriteMeta, error) {
+	return a.client.write("DELETE", aclPolicyPath(name), nil, nil, q)
+}
+
+func aclTokenPath(accessorID string) string {
+	return "/v1/acl/token/" + url.PathEscape(accessorID)
+}
+
+func aclPolicyPath(name string) string {
+	return "/v1/acl/policy/" + url.PathEscape(name)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Agent is used to query the health and status of the agent.
+type Agent struct {
+	client *Client
+}
+
+// Agent returns a handle on the agent endpoints.
+func (c *Client) Agent() *Agent {
+	return &Agent{client: c}
+}
+
+// Health is used to run the health checks of the agent. The result of the
+// health checks is returned whether or not the agent is healthy.
+func (a *Agent) Health(q *QueryOptions) (*structs.AgentHealthResponse, *QueryMeta, error) {
+	r, err := a.client.newRequest("GET", "/v1/agent/health")
+	if err != nil {
+		return nil, nil, err
+	}
+	r.setQueryOptions(q)
+
+	rtt, resp, err := a.client.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The agent responds with a 503 status code and the result of the health
+	// checks when unhealthy.
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		if _, resp, err = requireOK(rtt, resp, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	qm := &QueryMeta{RequestTime: rtt}
+	parseQueryMeta(resp, qm)
+
+	var out structs.AgentHealthResponse
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, qm, nil
+}
+
+// Self is used to query the status of the agent.
+func (a *Agent) Self(q *QueryOptions) (*structs.AgentSelfResponse, *QueryMeta, error) {
+	var resp structs.AgentSelfResponse
+
+	qm, err := a.client.query("/v1/agent/self", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, qm, nil
+}
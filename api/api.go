@@ -3,14 +3,91 @@ package api
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
 	"time"
+
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
 )
 
+// Define the environment variables from which the default configuration of
+// the client is read.
+const (
+	// AddrEnvName is the address of the Replicator agent.
+	AddrEnvName = "REPLICATOR_ADDR"
+
+	// TokenEnvName is the secret ID of the ACL token of the client.
+	TokenEnvName = "REPLICATOR_TOKEN"
+
+	// CACertEnvName is the path to the CA certificate used to verify the
+	// certificate of the agent.
+	CACertEnvName = "REPLICATOR_CACERT"
+
+	// ClientCertEnvName and ClientKeyEnvName are the paths to the client
+	// certificate and key presented to the agent.
+	ClientCertEnvName = "REPLICATOR_CLIENT_CERT"
+	ClientKeyEnvName  = "REPLICATOR_CLIENT_KEY"
+
+	// TLSServerNameEnvName is the server name used as the SNI host when
+	// connecting via TLS.
+	TLSServerNameEnvName = "REPLICATOR_TLS_SERVER_NAME"
+
+	// SkipVerifyEnvName disables verification of the certificate of the agent
+	// when set to a true value.
+	SkipVerifyEnvName = "REPLICATOR_SKIP_VERIFY"
+)
+
+// QueryOptions are used to parameterize a query.
+type QueryOptions struct {
+	// WaitIndex is used to perform a blocking query which waits until the
+	// index of the resource queried exceeds the index provided.
+	WaitIndex uint64
+
+	// WaitTime is the maximum time a blocking query waits, the agent default
+	// is used if not set.
+	WaitTime time.Duration
+
+	// AuthToken is the secret ID of the ACL token used for the request,
+	// overriding the token of the client.
+	AuthToken string
+
+	// Params are additional query parameters of the request.
+	Params map[string]string
+}
+
+// WriteOptions are used to parameterize a write.
+type WriteOptions struct {
+	// AuthToken is the secret ID of the ACL token used for the request,
+	// overriding the token of the client.
+	AuthToken string
+}
+
+// QueryMeta is used to return meta data about a query.
+type QueryMeta struct {
+	// LastIndex is the index of the resource queried when the query returned,
+	// which can be used as the WaitIndex of a subsequent blocking query.
+	LastIndex uint64
+
+	// RequestTime is the time taken by the request.
+	RequestTime time.Duration
+}
+
+// WriteMeta is used to return meta data about a write.
+type WriteMeta struct {
+	// RequestTime is the time taken by the request.
+	RequestTime time.Duration
+}
+
 // Client provides a client to the Replicator API.
 type Client struct {
 	config Config
@@ -21,8 +98,161 @@ type Config struct {
 	// Address is the address of the Replicator agent.
 	Address string
 
-	// httpClient is the client to use.
-	httpClient *http.Client
+	// Token is the secret ID of the ACL token presented with each request.
+	Token string
+
+	// TLSConfig is the TLS configuration used when the agent serves the HTTP
+	// API over TLS.
+	TLSConfig *TLSConfig
+
+	// HTTPClient is the client to use, a client with a clean transport is used
+	// if not set.
+	HTTPClient *http.Client
+}
+
+// TLSConfig contains the parameters needed to configure TLS on the HTTP
+// client used to communicate with the Replicator agent.
+type TLSConfig struct {
+	// CACert is the path to a PEM-encoded CA certificate file used to verify
+	// the certificate of the agent.
+	CACert string
+
+	// ClientCert and ClientKey are the paths to the PEM-encoded certificate
+	// and key presented to the agent when it verifies incoming connections.
+	ClientCert string
+	ClientKey  string
+
+	// TLSServerName is used to set the SNI host when connecting via TLS.
+	TLSServerName string
+
+	// Insecure disables verification of the certificate of the agent.
+	Insecure bool
+}
+
+// DefaultConfig returns a default configuration for the client which
+// connects to a local Replicator agent. The address, ACL token and TLS
+// configuration are read from the REPLICATOR_* environment variables, if set.
+func DefaultConfig() *Config {
+	config := &Config{
+		Address:    "http://127.0.0.1:1313",
+		Token:      os.Getenv(TokenEnvName),
+		HTTPClient: cleanhttp.DefaultClient(),
+	}
+
+	if addr := os.Getenv(AddrEnvName); addr != "" {
+		config.Address = addr
+	}
+
+	tlsConfig := &TLSConfig{
+		CACert:        os.Getenv(CACertEnvName),
+		ClientCert:    os.Getenv(ClientCertEnvName),
+		ClientKey:     os.Getenv(ClientKeyEnvName),
+		TLSServerName: os.Getenv(TLSServerNameEnvName),
+	}
+
+	if v := os.Getenv(SkipVerifyEnvName); v != "" {
+		if insecure, err := strconv.ParseBool(v); err == nil {
+			tlsConfig.Insecure = insecure
+		}
+	}
+
+	if *tlsConfig != (TLSConfig{}) {
+		config.TLSConfig = tlsConfig
+	}
+
+	return config
+}
+
+// NewClient returns a new client using the supplied configuration, any
+// unset values are populated from the default configuration.
+func NewClient(config *Config) (*Client, error) {
+	defConfig := DefaultConfig()
+
+	if config.Address == "" {
+		config.Address = defConfig.Address
+	}
+
+	if _, err := url.Parse(config.Address); err != nil {
+		return nil, fmt.Errorf("invalid address %q: %v", config.Address, err)
+	}
+
+	if config.Token == "" {
+		config.Token = defConfig.Token
+	}
+
+	if config.TLSConfig == nil {
+		config.TLSConfig = defConfig.TLSConfig
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = defConfig.HTTPClient
+	}
+
+	if config.TLSConfig != nil {
+		if err := config.ConfigureTLS(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Client{config: *config}, nil
+}
+
+// Address returns the address of the agent the client connects to.
+func (c *Client) Address() string {
+	return c.config.Address
+}
+
+// SetToken sets the secret ID of the ACL token presented with each request.
+func (c *Client) SetToken(token string) {
+	c.config.Token = token
+}
+
+// ConfigureTLS applies the TLS configuration to the transport of the HTTP
+// client.
+func (c *Config) ConfigureTLS() error {
+	if c.TLSConfig == nil {
+		return nil
+	}
+
+	if c.HTTPClient == nil {
+		c.HTTPClient = cleanhttp.DefaultClient()
+	}
+
+	transport, ok := c.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unable to configure TLS on the HTTP client transport")
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.TLSConfig.Insecure,
+		ServerName:         c.TLSConfig.TLSServerName,
+	}
+
+	if c.TLSConfig.ClientCert != "" || c.TLSConfig.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSConfig.ClientCert,
+			c.TLSConfig.ClientKey)
+		if err != nil {
+			return fmt.Errorf("unable to load the client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.TLSConfig.CACert != "" {
+		data, err := ioutil.ReadFile(c.TLSConfig.CACert)
+		if err != nil {
+			return fmt.Errorf("unable to read the CA certificate %v: %v",
+				c.TLSConfig.CACert, err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates were found in the CA certificate %v",
+				c.TLSConfig.CACert)
+		}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return nil
 }
 
 type request struct {
@@ -42,21 +272,89 @@ type multiCloser struct {
 	inorderClose []io.Closer
 }
 
-func (c *Client) query(endpoint string, out interface{}) error {
+// setQueryOptions applies the query options to the request.
+func (r *request) setQueryOptions(q *QueryOptions) {
+	if q == nil {
+		return
+	}
+
+	if q.AuthToken != "" {
+		r.token = q.AuthToken
+	}
+	if q.WaitIndex != 0 {
+		r.params.Set("index", strconv.FormatUint(q.WaitIndex, 10))
+	}
+	if q.WaitTime != 0 {
+		r.params.Set("wait", q.WaitTime.String())
+	}
+	for key, value := range q.Params {
+		r.params.Set(key, value)
+	}
+}
+
+// setWriteOptions applies the write options to the request.
+func (r *request) setWriteOptions(q *WriteOptions) {
+	if q == nil {
+		return
+	}
+
+	if q.AuthToken != "" {
+		r.token = q.AuthToken
+	}
+}
+
+// query is used to perform a GET request, decoding the response into out.
+func (c *Client) query(endpoint string, out interface{}, q *QueryOptions) (*QueryMeta, error) {
 	r, err := c.newRequest("GET", endpoint)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, resp, err := requireOK(c.doRequest(r))
+	r.setQueryOptions(q)
+	rtt, resp, err := requireOK(c.doRequest(r))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	qm := &QueryMeta{RequestTime: rtt}
+	parseQueryMeta(resp, qm)
+
 	if err := decodeBody(resp, out); err != nil {
-		return err
+		return nil, err
+	}
+	return qm, nil
+}
+
+// write is used to perform a request which modifies state. The request body
+// is encoded from in, if provided, and the response decoded into out, if
+// provided.
+func (c *Client) write(method, endpoint string, in, out interface{}, q *WriteOptions) (*WriteMeta, error) {
+	r, err := c.newRequest(method, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	r.setWriteOptions(q)
+	r.obj = in
+	rtt, resp, err := requireOK(c.doRequest(r))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := decodeBody(resp, out); err != nil {
+			return nil, err
+		}
+	}
+	return &WriteMeta{RequestTime: rtt}, nil
+}
+
+// parseQueryMeta is used to populate the query meta data from the response
+// headers.
+func parseQueryMeta(resp *http.Response, q *QueryMeta) {
+	if index, err := strconv.ParseUint(resp.Header.Get(structs.QueryIndexHeader), 10, 64); err == nil {
+		q.LastIndex = index
 	}
-	return nil
 }
 
 func (c *Client) newRequest(method, path string) (*request, error) {
@@ -75,6 +373,7 @@ func (c *Client) newRequest(method, path string) (*request, error) {
 			Path:   u.Path,
 		},
 		params: make(map[string][]string),
+		token:  c.config.Token,
 	}
 
 	// Add in the query parameters, if any
@@ -110,7 +409,7 @@ func (c *Client) doRequest(r *request) (time.Duration, *http.Response, error) {
 		return 0, nil, err
 	}
 	start := time.Now()
-	resp, err := c.config.httpClient.Do(req)
+	resp, err := c.config.HTTPClient.Do(req)
 	diff := time.Now().Sub(start)
 
 	// If the response is compressed, we swap the body's reader.
@@ -175,6 +474,10 @@ func (r *request) toHTTP() (*http.Request, error) {
 	req.URL.Host = r.url.Host
 	req.URL.Scheme = r.url.Scheme
 	req.Host = r.url.Host
+
+	if r.token != "" {
+		req.Header.Set(structs.ACLTokenHeader, r.token)
+	}
 	return req, nil
 }
 
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestAPI_DefaultConfig(t *testing.T) {
+	env := map[string]string{
+		AddrEnvName:          "https://replicator.example.com:1313",
+		TokenEnvName:         "secret",
+		CACertEnvName:        "ca.pem",
+		TLSServerNameEnvName: "replicator.example.com",
+		SkipVerifyEnvName:    "true",
+	}
+	for key, value := range env {
+		old, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		if ok {
+			defer os.Setenv(key, old)
+		} else {
+			defer os.Unsetenv(key)
+		}
+	}
+
+	config := DefaultConfig()
+
+	if config.Address != env[AddrEnvName] {
+		t.Fatalf("expected address %v but got %v", env[AddrEnvName], config.Address)
+	}
+	if config.Token != "secret" {
+		t.Fatalf("expected token secret but got %v", config.Token)
+	}
+
+	expected := TLSConfig{
+		CACert:        "ca.pem",
+		TLSServerName: "replicator.example.com",
+		Insecure:      true,
+	}
+	if config.TLSConfig == nil || *config.TLSConfig != expected {
+		t.Fatalf("expected TLS config %+v but got %+v", expected, config.TLSConfig)
+	}
+}
+
+func TestAPI_QueryOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("index") != "5" || query.Get("wait") != "1m0s" {
+			w.WriteHeader(400)
+			fmt.Fprintf(w, "unexpected query %v", r.URL.RawQuery)
+			return
+		}
+		if token := r.Header.Get(structs.ACLTokenHeader); token != "override" {
+			w.WriteHeader(403)
+			fmt.Fprintf(w, "unexpected token %q", token)
+			return
+		}
+
+		w.Header().Set(structs.QueryIndexHeader, "7")
+		fmt.Fprint(w, `[{"Name":"default"}]`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{Address: srv.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pools, qm, err := client.Pools().List(&QueryOptions{
+		AuthToken: "override",
+		WaitIndex: 5,
+		WaitTime:  time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pools) != 1 || pools[0].Name != "default" {
+		t.Fatalf("expected the default pool but got %v", pools)
+	}
+	if qm.LastIndex != 7 {
+		t.Fatalf("expected last index 7 but got %v", qm.LastIndex)
+	}
+}
+
+func TestAPI_EventStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if topic := r.URL.Query().Get("topic"); topic != "pool:default" {
+			w.WriteHeader(400)
+			return
+		}
+
+		fmt.Fprintln(w, `{}`)
+		fmt.Fprintln(w, `{"index":1,"key":"default","topic":"pool","type":"evaluation",`+
+			`"payload":{"direction":"out","metrics":{"cpu":80}}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{Address: srv.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.EventStream().Stream(ctx, []string{"pool:default"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	received := <-events
+	if received.Err != nil {
+		t.Fatalf("unexpected error: %v", received.Err)
+	}
+
+	payload, ok := received.Event.Payload.(*structs.EvaluationEvent)
+	if !ok || payload.Direction != "out" || payload.Metrics["cpu"] != 80 {
+		t.Fatalf("expected an evaluation event but got %#v", received.Event.Payload)
+	}
+
+	// The stream ends with the error which ended it.
+	if received = <-events; received == nil || received.Err == nil {
+		t.Fatalf("expected the stream to end with an error")
+	}
+	if _, ok := <-events; ok {
+		t.Fatalf("expected the events channel to be closed")
+	}
+}
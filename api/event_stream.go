@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// EventStream is used to stream the events published by the agent.
+type EventStream struct {
+	client *Client
+}
+
+// EventStream returns a handle on the event stream endpoint.
+func (c *Client) EventStream() *EventStream {
+	return &EventStream{client: c}
+}
+
+// StreamEvent is an event received from the event stream, or the error
+// which ended the stream.
+type StreamEvent struct {
+	Event *structs.StreamEvent
+	Err   error
+}
+
+// streamEvent is used to decode an event before its payload type is known.
+type streamEvent struct {
+	structs.StreamEvent
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Stream is used to stream the events matching the topic filters, in the form
+// topic or topic:key, or every event if no topics are provided. The payload
+// of each event is decoded into an EvaluationEvent, ScalingEvent or
+// LeadershipEvent depending on the type of the event. The channel is closed
+// when the context is cancelled or the stream ends, in which case the error
+// which ended the stream is sent first.
+func (e *EventStream) Stream(ctx context.Context, topics []string, q *QueryOptions) (<-chan *StreamEvent, error) {
+	r, err := e.client.newRequest("GET", "/v1/event/stream")
+	if err != nil {
+		return nil, err
+	}
+	r.setQueryOptions(q)
+	for _, topic := range topics {
+		r.params.Add("topic", topic)
+	}
+
+	req, err := r.toHTTP()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.config.HTTPClient.Do(req.WithContext(ctx))
+	if _, resp, err = requireOK(0, resp, err); err != nil {
+		return nil, err
+	}
+
+	events := make(chan *StreamEvent, 10)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var raw streamEvent
+			if err := dec.Decode(&raw); err != nil {
+				if ctx.Err() == nil {
+					e.send(ctx, events, &StreamEvent{Err: err})
+				}
+				return
+			}
+
+			// Heartbeats written to idle streams have no type.
+			if raw.Type == "" {
+				continue
+			}
+
+			event, err := decodeStreamEvent(&raw)
+			if !e.send(ctx, events, &StreamEvent{Event: event, Err: err}) {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// send delivers the event unless the context is done first.
+func (e *EventStream) send(ctx context.Context, events chan<- *StreamEvent, event *StreamEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// decodeStreamEvent decodes the payload of the event according to its type.
+func decodeStreamEvent(raw *streamEvent) (*structs.StreamEvent, error) {
+	event := raw.StreamEvent
+
+	switch event.Type {
+	case structs.StreamEventEvaluation:
+		event.Payload = &structs.EvaluationEvent{}
+	case structs.StreamEventFailsafe, structs.StreamEventScaling:
+		event.Payload = &structs.ScalingEvent{}
+	case structs.StreamEventLeadership:
+		event.Payload = &structs.LeadershipEvent{}
+	default:
+		return &event, nil
+	}
+
+	if len(raw.Payload) > 0 {
+		if err := json.Unmarshal(raw.Payload, event.Payload); err != nil {
+			return &event, fmt.Errorf("unable to decode the payload of event %v: %v",
+				event.Index, err)
+		}
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Failsafe is used to query and control the failsafe mode of worker pools
+// and job groups.
+type Failsafe struct {
+	client *Client
+}
+
+// Failsafe returns a handle on the failsafe endpoints.
+func (c *Client) Failsafe() *Failsafe {
+	return &Failsafe{client: c}
+}
+
+// List is used to query every worker pool and job group currently in
+// failsafe mode.
+func (f *Failsafe) List(q *QueryOptions) ([]*structs.FailsafeStatus, *QueryMeta, error) {
+	var resp []*structs.FailsafeStatus
+
+	qm, err := f.client.query("/v1/failsafe", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, qm, nil
+}
+
+// EnablePool is used to enable failsafe mode for a worker pool.
+func (f *Failsafe) EnablePool(name string, q *WriteOptions) (*structs.FailsafeStatus, *WriteMeta, error) {
+	return f.set("PUT", poolFailsafePath(name), q)
+}
+
+// DisablePool is used to disable failsafe mode for a worker pool.
+func (f *Failsafe) DisablePool(name string, q *WriteOptions) (*structs.FailsafeStatus, *WriteMeta, error) {
+	return f.set("DELETE", poolFailsafePath(name), q)
+}
+
+// EnableJobGroup is used to enable failsafe mode for a job group.
+func (f *Failsafe) EnableJobGroup(job, group string, q *WriteOptions) (*structs.FailsafeStatus, *WriteMeta, error) {
+	return f.set("PUT", jobGroupFailsafePath(job, group), q)
+}
+
+// DisableJobGroup is used to disable failsafe mode for a job group.
+func (f *Failsafe) DisableJobGroup(job, group string, q *WriteOptions) (*structs.FailsafeStatus, *WriteMeta, error) {
+	return f.set("DELETE", jobGroupFailsafePath(job, group), q)
+}
+
+func (f *Failsafe) set(method, endpoint string, q *WriteOptions) (*structs.FailsafeStatus, *WriteMeta, error) {
+	var resp structs.FailsafeStatus
+
+	wm, err := f.client.write(method, endpoint, nil, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, wm, nil
+}
+
+func poolFailsafePath(name string) string {
+	return "/v1/failsafe/pool/" + url.PathEscape(name)
+}
+
+func jobGroupFailsafePath(job, group string) string {
+	return "/v1/failsafe/job/" + url.PathEscape(job) + "/" + url.PathEscape(group)
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// History is used to query the scaling event history of worker pools and
+// job groups.
+type History struct {
+	client *Client
+}
+
+// History returns a handle on the history endpoints.
+func (c *Client) History() *History {
+	return &History{client: c}
+}
+
+// Pool is used to query the scaling event history of a worker pool.
+func (h *History) Pool(pool string, q *QueryOptions) ([]*structs.ScalingEvent, *QueryMeta, error) {
+	return h.list(url.Values{"pool": []string{pool}}, q)
+}
+
+// Job is used to query the scaling event history of a job. If a group is
+// provided, only the history of that job group is returned.
+func (h *History) Job(job, group string, q *QueryOptions) ([]*structs.ScalingEvent, *QueryMeta, error) {
+	params := url.Values{"job": []string{job}}
+	if group != "" {
+		params.Set("group", group)
+	}
+
+	return h.list(params, q)
+}
+
+func (h *History) list(params url.Values, q *QueryOptions) ([]*structs.ScalingEvent, *QueryMeta, error) {
+	var resp []*structs.ScalingEvent
+
+	qm, err := h.client.query("/v1/history?"+params.Encode(), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, qm, nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Jobs is used to query the scaling policies and status of job groups.
+type Jobs struct {
+	client *Client
+}
+
+// Jobs returns a handle on the job endpoints.
+func (c *Client) Jobs() *Jobs {
+	return &Jobs{client: c}
+}
+
+// List is used to query the scaling status of every job group with a scaling
+// policy.
+func (j *Jobs) List(q *QueryOptions) ([]*structs.JobGroupStatus, *QueryMeta, error) {
+	var resp []*structs.JobGroupStatus
+
+	qm, err := j.client.query("/v1/jobs", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, qm, nil
+}
+
+// Info is used to query the scaling status of every group of a job.
+func (j *Jobs) Info(jobID string, q *QueryOptions) ([]*structs.JobGroupStatus, *QueryMeta, error) {
+	var resp []*structs.JobGroupStatus
+
+	qm, err := j.client.query("/v1/job/"+url.PathEscape(jobID), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, qm, nil
+}
+
+// Scale is used to scale a job group to the count or one step in the
+// direction specified by the request. The events describing the scaling
+// operation performed are returned.
+func (j *Jobs) Scale(jobID, group string, req *structs.ScaleRequest, q *WriteOptions) ([]*structs.ScalingEvent, *WriteMeta, error) {
+	var resp []*structs.ScalingEvent
+
+	endpoint := "/v1/job/" + url.PathEscape(jobID) + "/" + url.PathEscape(group) +
+		"/scale"
+	wm, err := j.client.write("POST", endpoint, req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, wm, nil
+}
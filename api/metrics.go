@@ -0,0 +1,42 @@
+package api
+
+import (
+	"io/ioutil"
+)
+
+// Metrics is used to query the metrics of the agent.
+type Metrics struct {
+	client *Client
+}
+
+// Metrics returns a handle on the metrics endpoints.
+func (c *Client) Metrics() *Metrics {
+	return &Metrics{client: c}
+}
+
+// Prometheus is used to query the metrics of the agent in the Prometheus text
+// exposition format. Prometheus metrics must be enabled in the telemetry
+// configuration of the agent.
+func (m *Metrics) Prometheus(q *QueryOptions) ([]byte, *QueryMeta, error) {
+	r, err := m.client.newRequest("GET", "/v1/metrics?format=prometheus")
+	if err != nil {
+		return nil, nil, err
+	}
+	r.setQueryOptions(q)
+
+	rtt, resp, err := requireOK(m.client.doRequest(r))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	qm := &QueryMeta{RequestTime: rtt}
+	parseQueryMeta(resp, qm)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, qm, nil
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Pools is used to query the worker pools discovered by Replicator.
+type Pools struct {
+	client *Client
+}
+
+// Pools returns a handle on the worker pool endpoints.
+func (c *Client) Pools() *Pools {
+	return &Pools{client: c}
+}
+
+// List is used to query the status of every worker pool.
+func (p *Pools) List(q *QueryOptions) ([]*structs.PoolStatus, *QueryMeta, error) {
+	var resp []*structs.PoolStatus
+
+	qm, err := p.client.query("/v1/pools", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, qm, nil
+}
+
+// Info is used to query the status of a single worker pool.
+func (p *Pools) Info(name string, q *QueryOptions) (*structs.PoolStatus, *QueryMeta, error) {
+	var resp structs.PoolStatus
+
+	qm, err := p.client.query("/v1/pool/"+url.PathEscape(name), &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &resp, qm, nil
+}
+
+// Scale is used to scale a worker pool to the count or one step in the
+// direction specified by the request. The events describing each scaling
+// operation performed are returned.
+func (p *Pools) Scale(name string, req *structs.ScaleRequest, q *WriteOptions) ([]*structs.ScalingEvent, *WriteMeta, error) {
+	var resp []*structs.ScalingEvent
+
+	endpoint := "/v1/pool/" + url.PathEscape(name) + "/scale"
+	wm, err := p.client.write("POST", endpoint, req, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, wm, nil
+}
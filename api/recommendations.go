@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Recommendations is used to query task resource right-sizing
+// recommendations.
+type Recommendations struct {
+	client *Client
+}
+
+// Recommendations returns a handle on the recommendation endpoints.
+func (c *Client) Recommendations() *Recommendations {
+	return &Recommendations{client: c}
+}
+
+// List is used to query right-sizing recommendations. If a job is provided,
+// only recommendations for the tasks of that job are returned.
+func (r *Recommendations) List(job string, q *QueryOptions) ([]*structs.Recommendation, *QueryMeta, error) {
+	var resp []*structs.Recommendation
+
+	endpoint := "/v1/recommendations"
+	if job != "" {
+		endpoint = endpoint + "?job=" + url.QueryEscape(job)
+	}
+
+	qm, err := r.client.query(endpoint, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, qm, nil
+}
@@ -13,13 +13,13 @@ func (c *Client) Status() *Status {
 }
 
 // Leader is used to query information regarding the current Replicator leader.
-func (s *Status) Leader() (structs.LeaderResponse, error) {
+func (s *Status) Leader(q *QueryOptions) (*structs.LeaderResponse, *QueryMeta, error) {
 	var resp structs.LeaderResponse
 
-	err := s.client.query("/v1/status/leader", &resp)
+	qm, err := s.client.query("/v1/status/leader", &resp, q)
 	if err != nil {
-		return resp, err
+		return nil, nil, err
 	}
 
-	return resp, nil
+	return &resp, qm, nil
 }
@@ -24,6 +24,17 @@ type consulClient struct {
 // configuration and supporting the ability to specify a Consul API address
 // endpoint in the form of address:port.
 func NewConsulClient(addr, token string) (structs.ConsulClient, error) {
+	c, err := newConsulAPIClient(addr, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &consulClient{consul: c}, nil
+}
+
+// newConsulAPIClient builds a Consul API client for the specified address and
+// ACL token.
+func newConsulAPIClient(addr, token string) (*consul.Client, error) {
 	// TODO (e.westfall): Add a quick health check call to an API endpoint to
 	// validate connectivity or return an error back to the caller.
 	config := consul.DefaultConfig()
@@ -41,31 +52,42 @@ func NewConsulClient(addr, token string) (structs.ConsulClient, error) {
 		config.Token = token
 	}
 
-	c, err := consul.NewClient(config)
-	if err != nil {
-		// TODO (e.westfall): Raise error here.
-		return nil, err
-	}
+	return consul.NewClient(config)
+}
 
-	return &consulClient{consul: c}, nil
+// Leader returns the address of the Consul cluster leader.
+func (c *consulClient) Leader() (string, error) {
+	return c.consul.Status().Leader()
 }
 
 // GetLeaderInfo is used to inspect the leadership KV and session to provide
 // details of the Replicator agent currently holding leadership.
 func (c *consulClient) GetLeaderInfo(info *structs.LeaderResponse, key *string, session string) error {
 
+	k, _, err := c.consul.KV().Get(*key, nil)
+	if err != nil {
+		return err
+	}
+
 	if session == "" {
-		k, _, err := c.consul.KV().Get(*key, nil)
-		if err != nil {
-			return err
+		if k == nil || k.Session == "" {
+			return fmt.Errorf("no Replicator leader is currently elected")
 		}
 		session = k.Session
 	}
 
+	// The leader publishes its advertised RPC address as the lock value.
+	if k != nil {
+		info.RPCAddr = string(k.Value)
+	}
+
 	s, _, err := c.consul.Session().Info(session, nil)
 	if err != nil {
 		return err
 	}
+	if s == nil {
+		return fmt.Errorf("the leader session %v has expired", session)
+	}
 
 	info.NodeID = s.Node
 	info.SessionID = s.ID
@@ -117,9 +139,11 @@ func (c *consulClient) LoadPoolConfig(key string) (map[string]string, error) {
 }
 
 // AcquireLeadership attempts to acquire a Consul leadersip lock using the
-// provided session. If the lock is already taken this will return false in
-// a show that there is already a leader.
-func (c *consulClient) AcquireLeadership(key string, session *string) (acquired bool) {
+// provided session, publishing the advertised RPC address of the agent as
+// the lock value so followers are able to forward requests. If the lock is
+// already taken this will return false in a show that there is already a
+// leader.
+func (c *consulClient) AcquireLeadership(key string, session *string, advertise string) (acquired bool) {
 
 	// Attempt to inspect the leadership key if it is available and present.
 	k, _, err := c.consul.KV().Get(key, nil)
@@ -154,6 +178,7 @@ func (c *consulClient) AcquireLeadership(key string, session *string) (acquired
 	kp := &consul.KVPair{
 		Key:     key,
 		Session: *session,
+		Value:   []byte(advertise),
 	}
 
 	logging.Debug("client/consul: attempting to acquire leadership lock at %s", key)
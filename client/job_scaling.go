@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -17,9 +18,21 @@ const (
 	evaluationTimeOut = 30 * time.Second
 )
 
+// Confirmation strategies describe how Replicator verifies that a job group
+// scaling operation completed successfully. Groups with an update stanza are
+// confirmed by tracking the resulting deployment; all other groups are
+// confirmed by tracking the evaluation and resulting allocations.
+const (
+	ConfirmationStrategyAllocation = "allocation"
+	ConfirmationStrategyDeployment = "deployment"
+)
+
 // JobGroupScale scales a particular job group, confirming that the action
-// completes successfully.
-func (c *nomadClient) JobGroupScale(jobName string, group *structs.GroupScalingPolicy, state *structs.ScalingState) {
+// completes successfully. The returned event describes the outcome of the
+// operation and is nil if no operation was attempted. Confirmation stops
+// when the context is cancelled.
+func (c *nomadClient) JobGroupScale(ctx context.Context, jobName string, group *structs.GroupScalingPolicy,
+	state *structs.ScalingState) *structs.ScalingEvent {
 
 	// In order to scale the job, we need information on the current status of the
 	// running job from Nomad.
@@ -27,9 +40,14 @@ func (c *nomadClient) JobGroupScale(jobName string, group *structs.GroupScalingP
 
 	if err != nil {
 		logging.Error("client/job_scaling: unable to determine job info of %v: %v", jobName, err)
-		return
+		return nil
 	}
 
+	// Track the current and desired count and confirmation strategy of the
+	// group being scaled so the outcome of the operation can be verified.
+	var currentCount, desiredCount int
+	strategy := ConfirmationStrategyDeployment
+
 	// Use the current task count in order to determine whether or not a scaling
 	// event will violate the min/max job policy.
 	for i, taskGroup := range jobResp.TaskGroups {
@@ -37,12 +55,16 @@ func (c *nomadClient) JobGroupScale(jobName string, group *structs.GroupScalingP
 			group.ScaleDirection == ScalingDirectionIn && *taskGroup.Count <= group.Min {
 			logging.Debug("client/job_scaling: scale %v not permitted due to constraints on job \"%v\" and group \"%v\"",
 				group.ScaleDirection, *jobResp.ID, group.GroupName)
-			return
+			return nil
 		}
 
 		logging.Info("client/job_scaling: scale %v will now be initiated against job \"%v\" and group \"%v\"",
 			group.ScaleDirection, jobName, group.GroupName)
 
+		if *taskGroup.Name == group.GroupName {
+			currentCount = *taskGroup.Count
+		}
+
 		// Depending on the scaling direction decrement/incrament the count;
 		// currently replicator only supports addition/subtraction of 1.
 		if *taskGroup.Name == group.GroupName && group.ScaleDirection == ScalingDirectionOut {
@@ -54,6 +76,20 @@ func (c *nomadClient) JobGroupScale(jobName string, group *structs.GroupScalingP
 			*jobResp.TaskGroups[i].Count--
 			state.ScaleInRequests++
 		}
+
+		if *taskGroup.Name == group.GroupName {
+			desiredCount = *jobResp.TaskGroups[i].Count
+			strategy = confirmationStrategy(taskGroup)
+		}
+	}
+
+	// Describe the scaling operation for the scaling event history.
+	event := &structs.ScalingEvent{
+		CountAfter:  desiredCount,
+		CountBefore: currentCount,
+		Direction:   group.ScaleDirection,
+		Outcome:     structs.EventOutcomeSuccess,
+		Type:        structs.EventTypeScale,
 	}
 
 	// Submit the job to the Register API endpoint with the altered count number
@@ -61,34 +97,257 @@ func (c *nomadClient) JobGroupScale(jobName string, group *structs.GroupScalingP
 	resp, _, err := c.nomad.Jobs().Register(jobResp, &nomad.WriteOptions{})
 
 	// Track the scaling submission time.
-	state.LastScalingEvent = time.Now()
+	state.RecordScalingEvent(group.ScaleDirection)
 	if err != nil {
 		logging.Error("client/job_scaling: issue submitting job %s for scaling action: %v", jobName, err)
-		return
+		event.Outcome = structs.EventOutcomeFailure
+		event.Reason = fmt.Sprintf("unable to submit job: %v", err)
+		return event
 	}
 
 	// Setup our metric scaling direction namespace.
 	m := fmt.Sprintf("scale_%s", strings.ToLower(group.ScaleDirection))
 
-	success := c.scaleConfirmation(resp.EvalID)
+	if !c.confirmScaling(ctx, jobName, group.GroupName, resp.EvalID, strategy, desiredCount) {
+		if ctx.Err() != nil {
+			return interruptedEvent(event, ctx.Err())
+		}
 
-	if !success {
 		metrics.IncrCounter([]string{"job", jobName, group.GroupName, m, "failure"}, 1)
 		state.FailureCount++
 
-		return
+		event.Outcome = structs.EventOutcomeFailure
+		event.Reason = "the scaling operation could not be confirmed"
+		return event
 	}
 
 	metrics.IncrCounter([]string{"job", jobName, group.GroupName, m, "success"}, 1)
 	logging.Info("client/job_scaling: scaling of job \"%v\" and group \"%v\" successfully completed",
 		jobName, group.GroupName)
+
+	return event
+}
+
+// JobGroupScaleTo sets the count of a particular job group, confirming that
+// the action completes successfully. The count is expected to have already
+// been bounded by the group policy. The returned event describes the outcome
+// of the operation and is nil if no operation was attempted. Confirmation
+// stops when the context is cancelled.
+func (c *nomadClient) JobGroupScaleTo(ctx context.Context, jobName string, group *structs.GroupScalingPolicy,
+	count int, state *structs.ScalingState) *structs.ScalingEvent {
+
+	jobResp, _, err := c.nomad.Jobs().Info(jobName, c.queryOptions())
+	if err != nil {
+		logging.Error("client/job_scaling: unable to determine job info of %v: %v", jobName, err)
+		return nil
+	}
+
+	var taskGroup *nomad.TaskGroup
+	for _, tg := range jobResp.TaskGroups {
+		if *tg.Name == group.GroupName {
+			taskGroup = tg
+			break
+		}
+	}
+
+	if taskGroup == nil {
+		logging.Error("client/job_scaling: group \"%v\" was not found in job \"%v\"",
+			group.GroupName, jobName)
+		return nil
+	}
+
+	if *taskGroup.Count == count {
+		logging.Debug("client/job_scaling: job \"%v\" and group \"%v\" is already "+
+			"running the desired count of %v", jobName, group.GroupName, count)
+		return nil
+	}
+
+	direction := ScalingDirectionOut
+	if count < *taskGroup.Count {
+		direction = ScalingDirectionIn
+	}
+
+	switch direction {
+	case ScalingDirectionOut:
+		state.ScaleOutRequests++
+	case ScalingDirectionIn:
+		state.ScaleInRequests++
+	}
+
+	logging.Info("client/job_scaling: scale %v from %v to %v will now be initiated "+
+		"against job \"%v\" and group \"%v\"", direction, *taskGroup.Count, count,
+		jobName, group.GroupName)
+
+	event := &structs.ScalingEvent{
+		CountAfter:  count,
+		CountBefore: *taskGroup.Count,
+		Direction:   direction,
+		Outcome:     structs.EventOutcomeSuccess,
+		Type:        structs.EventTypeScale,
+	}
+
+	*taskGroup.Count = count
+
+	resp, _, err := c.nomad.Jobs().Register(jobResp, &nomad.WriteOptions{})
+
+	// Track the scaling submission time.
+	state.RecordScalingEvent(direction)
+	if err != nil {
+		logging.Error("client/job_scaling: issue submitting job %s for scaling action: %v", jobName, err)
+		event.Outcome = structs.EventOutcomeFailure
+		event.Reason = fmt.Sprintf("unable to submit job: %v", err)
+		return event
+	}
+
+	m := fmt.Sprintf("scale_%s", strings.ToLower(direction))
+
+	if !c.confirmScaling(ctx, jobName, group.GroupName, resp.EvalID,
+		confirmationStrategy(taskGroup), count) {
+		if ctx.Err() != nil {
+			return interruptedEvent(event, ctx.Err())
+		}
+
+		metrics.IncrCounter([]string{"job", jobName, group.GroupName, m, "failure"}, 1)
+		state.FailureCount++
+
+		event.Outcome = structs.EventOutcomeFailure
+		event.Reason = "the scaling operation could not be confirmed"
+		return event
+	}
+
+	metrics.IncrCounter([]string{"job", jobName, group.GroupName, m, "success"}, 1)
+	logging.Info("client/job_scaling: scaling of job \"%v\" and group \"%v\" successfully completed",
+		jobName, group.GroupName)
+
+	return event
+}
+
+// JobGroupResize updates the CPU and memory resources of tasks within a job
+// group, confirming that the resulting rollout completes successfully. The
+// returned event describes the outcome of the operation and is nil if no
+// operation was attempted. Confirmation stops when the context is cancelled.
+func (c *nomadClient) JobGroupResize(ctx context.Context, jobName string, group *structs.GroupScalingPolicy,
+	targets []*structs.TaskResources, state *structs.ScalingState) *structs.ScalingEvent {
+
+	jobResp, _, err := c.nomad.Jobs().Info(jobName, c.queryOptions())
+	if err != nil {
+		logging.Error("client/job_scaling: unable to determine job info of %v: %v", jobName, err)
+		return nil
+	}
+
+	var taskGroup *nomad.TaskGroup
+	for _, tg := range jobResp.TaskGroups {
+		if *tg.Name == group.GroupName {
+			taskGroup = tg
+			break
+		}
+	}
+
+	if taskGroup == nil {
+		logging.Error("client/job_scaling: group \"%v\" was not found in job \"%v\"",
+			group.GroupName, jobName)
+		return nil
+	}
+
+	event := &structs.ScalingEvent{
+		CountAfter:  *taskGroup.Count,
+		CountBefore: *taskGroup.Count,
+		Outcome:     structs.EventOutcomeSuccess,
+		Type:        structs.EventTypeResize,
+	}
+
+	// Apply the target resources to the matching tasks within the group.
+	var resized []string
+	for _, target := range targets {
+		for _, task := range taskGroup.Tasks {
+			if task.Name != target.TaskName {
+				continue
+			}
+
+			if task.Resources == nil {
+				task.Resources = &nomad.Resources{}
+			}
+
+			cpu, mem := target.CPUMHz, target.MemoryMB
+			task.Resources.CPU = &cpu
+			task.Resources.MemoryMB = &mem
+
+			resized = append(resized, fmt.Sprintf("%v: %v MHz CPU, %v MB memory",
+				task.Name, cpu, mem))
+
+			logging.Info("client/job_scaling: task \"%v\" of job \"%v\" and group "+
+				"\"%v\" will be resized to %v MHz CPU and %v MB memory",
+				task.Name, jobName, group.GroupName, cpu, mem)
+		}
+	}
+
+	event.Reason = strings.Join(resized, "; ")
+
+	resp, _, err := c.nomad.Jobs().Register(jobResp, &nomad.WriteOptions{})
+
+	// Track the resize submission time so vertical scaling is subject to the
+	// same cooldown as horizontal scaling.
+	state.RecordScalingEvent(ScalingDirectionNone)
+	if err != nil {
+		logging.Error("client/job_scaling: issue submitting job %s for resize action: %v", jobName, err)
+		event.Outcome = structs.EventOutcomeFailure
+		event.Reason = fmt.Sprintf("unable to submit job: %v", err)
+		return event
+	}
+
+	if !c.confirmScaling(ctx, jobName, group.GroupName, resp.EvalID,
+		confirmationStrategy(taskGroup), *taskGroup.Count) {
+		if ctx.Err() != nil {
+			return interruptedEvent(event, ctx.Err())
+		}
+
+		metrics.IncrCounter([]string{"job", jobName, group.GroupName, "resize", "failure"}, 1)
+		state.FailureCount++
+
+		event.Outcome = structs.EventOutcomeFailure
+		event.Reason = "the resize operation could not be confirmed"
+		return event
+	}
+
+	metrics.IncrCounter([]string{"job", jobName, group.GroupName, "resize", "success"}, 1)
+	logging.Info("client/job_scaling: resize of job \"%v\" and group \"%v\" successfully completed",
+		jobName, group.GroupName)
+
+	return event
+}
+
+// interruptedEvent marks an event as interrupted by cancellation of the
+// context before the outcome of the operation could be confirmed. Interrupted
+// operations are not counted as failures.
+func interruptedEvent(event *structs.ScalingEvent, err error) *structs.ScalingEvent {
+	event.Outcome = structs.EventOutcomeInterrupted
+	event.Reason = fmt.Sprintf("the operation was interrupted before it could "+
+		"be confirmed: %v", err)
+	return event
+}
+
+// confirmScaling verifies the outcome of a job group update using the
+// appropriate confirmation strategy for the group. Confirmation stops and
+// false is returned if the context is cancelled.
+func (c *nomadClient) confirmScaling(ctx context.Context, jobName, groupName,
+	evalID, strategy string, desiredCount int) bool {
+
+	switch strategy {
+	case ConfirmationStrategyAllocation:
+		logging.Debug("client/job_scaling: job \"%v\" and group \"%v\" has no "+
+			"update stanza, scaling will be confirmed using allocation health",
+			jobName, groupName)
+		return c.allocationConfirmation(ctx, jobName, groupName, evalID, desiredCount)
+	default:
+		return c.scaleConfirmation(ctx, evalID)
+	}
 }
 
 // scaleConfirmation takes the EvaluationID from the job registration and checks
 // via a timer and blocking queries that the resulting deployment completes
 // successfully.
-func (c *nomadClient) scaleConfirmation(evalID string) (success bool) {
-	depID, err := c.getDeploymentID(evalID)
+func (c *nomadClient) scaleConfirmation(ctx context.Context, evalID string) (success bool) {
+	depID, err := c.getDeploymentID(ctx, evalID)
 	if err != nil {
 		logging.Error("client/job_scaling: unable to obtain evaluation info or "+
 			"deployment ID for evaluation %v: %v", evalID, err)
@@ -103,6 +362,11 @@ func (c *nomadClient) scaleConfirmation(evalID string) (success bool) {
 
 	for {
 		select {
+		case <-ctx.Done():
+			logging.Warning("client/job_scaling: confirmation of deployment %s was "+
+				"interrupted: %v", depID, ctx.Err())
+			return
+
 		case <-timeOut:
 			logging.Error("client/job_scaling: deployment %s reached timeout %v",
 				depID, deploymentTimeOut)
@@ -136,8 +400,161 @@ func (c *nomadClient) scaleConfirmation(evalID string) (success bool) {
 	}
 }
 
+// allocationConfirmation takes the EvaluationID from the job registration and
+// confirms the scaling operation of a job group which does not trigger a
+// deployment. The evaluation is tracked until it completes, after which the
+// allocations of the group are polled until the desired count is running and
+// healthy.
+func (c *nomadClient) allocationConfirmation(ctx context.Context, jobName,
+	groupName, evalID string, desiredCount int) (success bool) {
+
+	if err := c.waitForEvaluation(ctx, evalID, groupName); err != nil {
+		logging.Error("client/job_scaling: evaluation %v for job \"%v\" and "+
+			"group \"%v\" did not complete successfully: %v", evalID, jobName,
+			groupName, err)
+		return
+	}
+
+	timeOut := time.After(deploymentTimeOut)
+	tick := time.NewTicker(500 * time.Millisecond)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Warning("client/job_scaling: confirmation of job \"%v\" and "+
+				"group \"%v\" was interrupted: %v", jobName, groupName, ctx.Err())
+			return
+
+		case <-timeOut:
+			logging.Error("client/job_scaling: job \"%v\" and group \"%v\" did "+
+				"not reach the desired count %v within timeout %v", jobName,
+				groupName, desiredCount, deploymentTimeOut)
+			return
+
+		case <-tick.C:
+			allocs, _, err := c.nomad.Jobs().Allocations(jobName, false,
+				c.queryOptions())
+			if err != nil {
+				logging.Error("client/job_scaling: unable to list allocations for "+
+					"job %v: %v", jobName, err)
+				return
+			}
+
+			healthy, unhealthy := groupAllocationHealth(allocs, groupName, evalID)
+
+			if unhealthy > 0 {
+				logging.Error("client/job_scaling: job \"%v\" and group \"%v\" "+
+					"has %v unhealthy allocations", jobName, groupName, unhealthy)
+				return false
+			}
+
+			if healthy == desiredCount {
+				return true
+			}
+
+			logging.Debug("client/job_scaling: job \"%v\" and group \"%v\" has "+
+				"%v of %v allocations running and healthy", jobName, groupName,
+				healthy, desiredCount)
+		}
+	}
+}
+
+// waitForEvaluation polls the Nomad API until an evaluation completes. An
+// error is returned if the evaluation fails, is cancelled or is unable to
+// place allocations for the supplied group.
+func (c *nomadClient) waitForEvaluation(ctx context.Context, evalID, groupName string) error {
+	ticker := time.NewTicker(time.Millisecond * 500)
+	defer ticker.Stop()
+
+	timeout := time.NewTicker(evaluationTimeOut)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-timeout.C:
+			return fmt.Errorf("timeout reached while waiting for evaluation %v "+
+				"to complete", evalID)
+
+		case <-ticker.C:
+			eval, _, err := c.nomad.Evaluations().Info(evalID, nil)
+			if err != nil {
+				logging.Error("client/job_scaling: an error occurred while trying "+
+					"to retrieve evaluation %v: %v", evalID, err)
+				continue
+			}
+
+			switch eval.Status {
+			case nomadstructs.EvalStatusComplete:
+				if _, ok := eval.FailedTGAllocs[groupName]; ok {
+					return fmt.Errorf("evaluation %v failed to place allocations "+
+						"for group %v", evalID, groupName)
+				}
+				return nil
+			case nomadstructs.EvalStatusFailed, nomadstructs.EvalStatusCancelled:
+				return fmt.Errorf("evaluation %v has status %v: %v", evalID,
+					eval.Status, eval.StatusDescription)
+			default:
+				logging.Debug("client/job_scaling: evaluation %v has status %v; "+
+					"pausing and retrying", evalID, eval.Status)
+			}
+		}
+	}
+}
+
+// groupAllocationHealth counts the allocations of a job group which are
+// desired to be running and reports how many are running and healthy. Only
+// allocations placed by the supplied evaluation are reported as unhealthy so
+// that historic failures do not fail the current scaling operation.
+// Deployment health is taken into account where present.
+func groupAllocationHealth(allocs []*nomad.AllocationListStub,
+	groupName, evalID string) (healthy, unhealthy int) {
+
+	for _, alloc := range allocs {
+		if alloc.TaskGroup != groupName ||
+			alloc.DesiredStatus != nomadstructs.AllocDesiredStatusRun {
+			continue
+		}
+
+		placed := alloc.EvalID == evalID
+
+		switch alloc.ClientStatus {
+		case nomadstructs.AllocClientStatusRunning:
+			if alloc.DeploymentStatus != nil && alloc.DeploymentStatus.Healthy != nil &&
+				!*alloc.DeploymentStatus.Healthy {
+				if placed {
+					unhealthy++
+				}
+				continue
+			}
+			healthy++
+		case nomadstructs.AllocClientStatusFailed, nomadstructs.AllocClientStatusLost:
+			if placed {
+				unhealthy++
+			}
+		}
+	}
+
+	return
+}
+
+// confirmationStrategy determines how a scaling operation against the supplied
+// task group should be confirmed. Only groups with an update stanza that
+// permits parallel updates result in a Nomad deployment.
+func confirmationStrategy(group *nomad.TaskGroup) string {
+	if group.Update != nil && group.Update.MaxParallel != nil &&
+		*group.Update.MaxParallel > 0 {
+		return ConfirmationStrategyDeployment
+	}
+
+	return ConfirmationStrategyAllocation
+}
+
 // getDeploymentID retrieves the deployment ID for a given Nomad evaluation.
-func (c *nomadClient) getDeploymentID(evalID string) (depID string, err error) {
+func (c *nomadClient) getDeploymentID(ctx context.Context, evalID string) (depID string, err error) {
 	var eval *nomad.Evaluation
 
 	// Setup our retry ticker to keep polling the Nomad API until we get
@@ -150,6 +567,9 @@ func (c *nomadClient) getDeploymentID(evalID string) (depID string, err error) {
 
 	for {
 		select {
+		case <-ctx.Done():
+			return depID, ctx.Err()
+
 		case <-timeout.C:
 			return depID, fmt.Errorf("timeout reached while trying to retrieve the "+
 				"deployment ID for evaluation %v", evalID)
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"time"
 
 	nomad "github.com/hashicorp/nomad/api"
@@ -13,20 +14,35 @@ import (
 )
 
 // JobWatcher is the main entry point into Replicators process of reading and
-// updating its JobScalingPolicies tracking.
-func (c *nomadClient) JobWatcher(jobScalingPolicies *structs.JobScalingPolicies) {
+// updating its JobScalingPolicies tracking. The watcher returns once the
+// context is cancelled, which is checked between blocking queries.
+func (c *nomadClient) JobWatcher(ctx context.Context,
+	jobScalingPolicies *structs.JobScalingPolicies) {
+
 	q := &nomad.QueryOptions{WaitIndex: 1, AllowStale: true}
 
 	for {
+		if ctx.Err() != nil {
+			logging.Debug("client/job_scaling_policies: stopping job discovery watcher")
+			return
+		}
+
 		jobs, meta, err := c.nomad.Jobs().List(q)
 		if err != nil {
 			logging.Error("client/job_scaling_policies: failed to retrieve jobs from the Nomad API: %v", err)
 
 			// Sleep as we don't want to retry the API call as fast as Go possibly can.
-			time.Sleep(20 * time.Second)
+			select {
+			case <-ctx.Done():
+			case <-time.After(20 * time.Second):
+			}
 			continue
 		}
 
+		jobScalingPolicies.Lock.Lock()
+		jobScalingPolicies.LastPoll = time.Now()
+		jobScalingPolicies.Lock.Unlock()
+
 		// If the LastIndex is not greater than our stored LastChangeIndex, we don't
 		// need to do anything. On the initial run this will always result in a full
 		// run as the LastChangeIndex is initialized to 0.
@@ -96,22 +112,30 @@ func (c *nomadClient) jobScalingPolicyProcessor(jobID string, scaling *structs.J
 		"replicator_notification_uid",
 	}
 
+	// Groups which follow another group derive their count from the followed
+	// group and therefore do not require utilization thresholds.
+	followerKeys := []string{
+		"replicator_enabled",
+		"replicator_min",
+		"replicator_max",
+		"replicator_notification_uid",
+	}
+
 	// Run the checkOrphanedGroup function.
 	go checkOrphanedGroup(jobID, jobInfo.TaskGroups, scaling)
 
 	for _, group := range jobInfo.TaskGroups {
 
-		if group.Update == nil {
-			logging.Error("client/job_scaling_policies: job %s and group %v is missing update stanza",
-				jobID, *group.Name)
-			continue
+		keys := requiredKeys
+		if _, ok := group.Meta["replicator_follow"]; ok {
+			keys = followerKeys
 		}
 
-		missedKeys := helper.ParseMetaConfig(group.Meta, requiredKeys)
+		missedKeys := helper.ParseMetaConfig(group.Meta, keys)
 
 		// If all 7 keys missed, then the job group does not have scaling enabled,
 		// this is logged for operator clarity.
-		if len(missedKeys) == len(requiredKeys) {
+		if len(missedKeys) == len(keys) {
 			logging.Debug("client/job_scaling_policies: job %s and group %v is not configured for autoscaling",
 				jobID, *group.Name)
 			go removeGroupScalingPolicy(jobID, *group.Name, scaling)
@@ -121,7 +145,7 @@ func (c *nomadClient) jobScalingPolicyProcessor(jobID string, scaling *structs.J
 		// If some keys missed, the operator has made an effort to enable job scaling
 		// but potentially made a typo. This is logged as an error so operators can
 		// see and quickly resolve these issues.
-		if len(missedKeys) > 0 && len(missedKeys) < len(requiredKeys) {
+		if len(missedKeys) > 0 && len(missedKeys) < len(keys) {
 			logging.Error("client/job_scaling_policies: job %s and group %v is missing meta scaling key(s): %v",
 				jobID, *group.Name, missedKeys)
 			continue
@@ -47,6 +47,9 @@ func TestJobScalingPolicies_updateScalingPolicy(t *testing.T) {
 		ScaleOutCPU:    90,
 		RetryThreshold: 10,
 		UID:            "ELS2",
+
+		FollowRatio:       1,
+		VerticalThreshold: 10,
 	}
 	policy2 := &structs.GroupScalingPolicy{
 		GroupName:      "jobs",
@@ -60,6 +63,9 @@ func TestJobScalingPolicies_updateScalingPolicy(t *testing.T) {
 		ScaleOutCPU:    90,
 		RetryThreshold: 10,
 		UID:            "ELS2",
+
+		FollowRatio:       1,
+		VerticalThreshold: 10,
 	}
 	policy3 := &structs.GroupScalingPolicy{
 		GroupName:      "hertzfeld",
@@ -73,6 +79,9 @@ func TestJobScalingPolicies_updateScalingPolicy(t *testing.T) {
 		ScaleOutCPU:    90,
 		RetryThreshold: 10,
 		UID:            "ELS2",
+
+		FollowRatio:       1,
+		VerticalThreshold: 10,
 	}
 	expected.Policies["example"] = append(expected.Policies["example"], policy1)
 	expected.Policies["woz"] = append(expected.Policies["woz"], policy2)
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"testing"
+
+	nomad "github.com/hashicorp/nomad/api"
+	nomadstructs "github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestJobScaling_confirmationStrategy(t *testing.T) {
+	parallel := 1
+	serial := 0
+
+	group := &nomad.TaskGroup{}
+	if s := confirmationStrategy(group); s != ConfirmationStrategyAllocation {
+		t.Fatalf("expected strategy %v but got %v", ConfirmationStrategyAllocation, s)
+	}
+
+	group.Update = &nomad.UpdateStrategy{MaxParallel: &serial}
+	if s := confirmationStrategy(group); s != ConfirmationStrategyAllocation {
+		t.Fatalf("expected strategy %v but got %v", ConfirmationStrategyAllocation, s)
+	}
+
+	group.Update = &nomad.UpdateStrategy{MaxParallel: &parallel}
+	if s := confirmationStrategy(group); s != ConfirmationStrategyDeployment {
+		t.Fatalf("expected strategy %v but got %v", ConfirmationStrategyDeployment, s)
+	}
+}
+
+func TestJobScaling_groupAllocationHealth(t *testing.T) {
+	healthy := true
+	unhealthy := false
+
+	allocs := []*nomad.AllocationListStub{
+		{
+			EvalID:        "old",
+			TaskGroup:     "cache",
+			DesiredStatus: nomadstructs.AllocDesiredStatusRun,
+			ClientStatus:  nomadstructs.AllocClientStatusRunning,
+		},
+		{
+			EvalID:        "new",
+			TaskGroup:     "cache",
+			DesiredStatus: nomadstructs.AllocDesiredStatusRun,
+			ClientStatus:  nomadstructs.AllocClientStatusRunning,
+			DeploymentStatus: &nomad.AllocDeploymentStatus{
+				Healthy: &healthy,
+			},
+		},
+		{
+			EvalID:        "old",
+			TaskGroup:     "cache",
+			DesiredStatus: nomadstructs.AllocDesiredStatusRun,
+			ClientStatus:  nomadstructs.AllocClientStatusFailed,
+		},
+		{
+			EvalID:        "new",
+			TaskGroup:     "cache",
+			DesiredStatus: nomadstructs.AllocDesiredStatusStop,
+			ClientStatus:  nomadstructs.AllocClientStatusRunning,
+		},
+		{
+			EvalID:        "new",
+			TaskGroup:     "web",
+			DesiredStatus: nomadstructs.AllocDesiredStatusRun,
+			ClientStatus:  nomadstructs.AllocClientStatusRunning,
+		},
+	}
+
+	h, u := groupAllocationHealth(allocs, "cache", "new")
+	if h != 2 || u != 0 {
+		t.Fatalf("expected 2 healthy and 0 unhealthy allocations but got %v and %v", h, u)
+	}
+
+	allocs = append(allocs, &nomad.AllocationListStub{
+		EvalID:        "new",
+		TaskGroup:     "cache",
+		DesiredStatus: nomadstructs.AllocDesiredStatusRun,
+		ClientStatus:  nomadstructs.AllocClientStatusRunning,
+		DeploymentStatus: &nomad.AllocDeploymentStatus{
+			Healthy: &unhealthy,
+		},
+	})
+
+	h, u = groupAllocationHealth(allocs, "cache", "new")
+	if h != 2 || u != 1 {
+		t.Fatalf("expected 2 healthy and 1 unhealthy allocations but got %v and %v", h, u)
+	}
+}
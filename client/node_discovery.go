@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,23 +17,36 @@ import (
 )
 
 // NodeWathcher is the method Replicator uses to perform discovery of all
-// worker pool nodes in the Nomad cluster.
-func (c *nomadClient) NodeWatcher(nodeRegistry *structs.NodeRegistry,
-	config *structs.Config) {
+// worker pool nodes in the Nomad cluster. The watcher returns once the context
+// is cancelled, which is checked between blocking queries.
+func (c *nomadClient) NodeWatcher(ctx context.Context,
+	nodeRegistry *structs.NodeRegistry, config *structs.Config) {
 
 	q := &nomad.QueryOptions{WaitIndex: 1, AllowStale: true}
 
 	for {
+		if ctx.Err() != nil {
+			logging.Debug("client/node_discovery: stopping node discovery watcher")
+			return
+		}
+
 		nodes, meta, err := c.nomad.Nodes().List(q)
 		if err != nil {
 			logging.Error("client/node_discovery: failed to retrieve nodes from "+
 				"the Nomad API: %v", err)
 
 			// Sleep as we don't want to retry the API call as fast as Go possibly can.
-			time.Sleep(20 * time.Second)
+			select {
+			case <-ctx.Done():
+			case <-time.After(20 * time.Second):
+			}
 			continue
 		}
 
+		nodeRegistry.Lock.Lock()
+		nodeRegistry.LastPoll = time.Now()
+		nodeRegistry.Lock.Unlock()
+
 		if meta.LastIndex <= nodeRegistry.LastChangeIndex {
 			logging.Debug("client/node_discovery: blocking query timed out, " +
 				"restarting node discovery watcher")
@@ -257,6 +271,9 @@ func Register(node *nomad.Node, workerPool *structs.WorkerPool,
 				"changed, updating.")
 			existingPool.Region = workerPool.Region
 			existingPool.Cooldown = workerPool.Cooldown
+			existingPool.ScaleInCooldown = workerPool.ScaleInCooldown
+			existingPool.ScaleOutCooldown = workerPool.ScaleOutCooldown
+			existingPool.EmergencySlope = workerPool.EmergencySlope
 			existingPool.RetryThreshold = workerPool.RetryThreshold
 			existingPool.FaultTolerance = workerPool.FaultTolerance
 			existingPool.ScalingEnabled = workerPool.ScalingEnabled
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"time"
@@ -62,6 +63,11 @@ func (c *nomadClient) queryOptions() (queryOptions *nomad.QueryOptions) {
 	return &nomad.QueryOptions{AllowStale: true}
 }
 
+// Leader returns the address of the Nomad cluster leader.
+func (c *nomadClient) Leader() (string, error) {
+	return c.nomad.Status().Leader()
+}
+
 // NodeReverseLookup provides a method to get the ID of the worker pool node
 // running a given allocation.
 func (c *nomadClient) NodeReverseLookup(allocID string) (node string, err error) {
@@ -167,8 +173,9 @@ func (c *nomadClient) LeastAllocatedNode(capacity *structs.ClusterCapacity,
 }
 
 // DrainNode toggles the drain mode of a worker node. When enabled, no further allocations
-// will be assigned and existing allocations will be migrated.
-func (c *nomadClient) DrainNode(nodeID string) (err error) {
+// will be assigned and existing allocations will be migrated. Waiting for the
+// migration to complete stops if the context is cancelled.
+func (c *nomadClient) DrainNode(ctx context.Context, nodeID string) (err error) {
 	// Initiate allocation draining for specified node.
 	drainSpec := &nomad.DrainSpec{}
 	_, err = c.nomad.Nodes().UpdateDrain(nodeID, drainSpec, true, &nomad.WriteOptions{})
@@ -194,6 +201,10 @@ func (c *nomadClient) DrainNode(nodeID string) (err error) {
 
 	for {
 		select {
+		case <-ctx.Done():
+			logging.Warning("client/nomad: interrupted while waiting for existing "+
+				"allocations to be migrated from node %v: %v", nodeID, ctx.Err())
+			return ctx.Err()
 		case <-timeout.C:
 			logging.Error("client/nomad: timeout %v reached while waiting for existing allocations to be migrated from node %v",
 				timeout, nodeID)
@@ -226,6 +237,18 @@ func (c *nomadClient) DrainNode(nodeID string) (err error) {
 	}
 }
 
+// CancelDrain disables the drain mode of a worker node and marks it eligible
+// to receive allocations again.
+func (c *nomadClient) CancelDrain(nodeID string) error {
+	_, err := c.nomad.Nodes().UpdateDrain(nodeID, nil, true, &nomad.WriteOptions{})
+	if err != nil {
+		return err
+	}
+
+	logging.Info("client/nomad: node %v has been removed from drain mode", nodeID)
+	return nil
+}
+
 // GetTaskGroupResources finds the defined resource requirements for a
 // given Job.
 func (c *nomadClient) GetTaskGroupResources(jobName string, groupPolicy *structs.GroupScalingPolicy) error {
@@ -239,6 +262,10 @@ func (c *nomadClient) GetTaskGroupResources(jobName string, groupPolicy *structs
 	groupPolicy.Tasks.Resources.MemoryMB = 0
 
 	for _, group := range jobs.TaskGroups {
+		if *group.Name == groupPolicy.GroupName {
+			groupPolicy.DesiredCount = *group.Count
+		}
+
 		for _, task := range group.Tasks {
 			groupPolicy.Tasks.Resources.CPUMHz += *task.Resources.CPU
 			groupPolicy.Tasks.Resources.MemoryMB += *task.Resources.MemoryMB
@@ -250,7 +277,8 @@ func (c *nomadClient) GetTaskGroupResources(jobName string, groupPolicy *structs
 // EvaluateJobScaling identifies Nomad allocations representative of a Job group
 // and compares the consumed resource percentages against the scaling policy to
 // determine whether a scaling event is required.
-func (c *nomadClient) EvaluateJobScaling(jobName string, jobScalingPolicies []*structs.GroupScalingPolicy) (err error) {
+func (c *nomadClient) EvaluateJobScaling(jobName string, jobScalingPolicies []*structs.GroupScalingPolicy,
+	history *structs.UtilizationHistory) (err error) {
 	for _, gsp := range jobScalingPolicies {
 		if err = c.GetTaskGroupResources(jobName, gsp); err != nil {
 			return
@@ -261,12 +289,18 @@ func (c *nomadClient) EvaluateJobScaling(jobName string, jobScalingPolicies []*s
 			return err
 		}
 
-		c.GetJobAllocations(allocs, gsp)
+		c.GetJobAllocations(allocs, gsp, history)
 		c.MostUtilizedGroupResource(gsp)
 
 		// Reset the direction
 		gsp.ScaleDirection = ScalingDirectionNone
 
+		// Groups which follow another group have their count derived from the
+		// followed group rather than their own utilization.
+		if gsp.Follow != "" {
+			continue
+		}
+
 		switch gsp.ScalingMetric {
 		case ScalingMetricProcessor:
 			if gsp.Tasks.Resources.CPUPercent > gsp.ScaleOutCPU {
@@ -286,18 +320,42 @@ func (c *nomadClient) EvaluateJobScaling(jobName string, jobScalingPolicies []*s
 	return
 }
 
-// GetJobAllocations identifies all allocations for an active job.
-func (c *nomadClient) GetJobAllocations(allocs []*nomad.AllocationListStub, gsp *structs.GroupScalingPolicy) {
+// GetJobGroupCount returns the current count of a job group.
+func (c *nomadClient) GetJobGroupCount(jobName, groupName string) (int, error) {
+	job, _, err := c.nomad.Jobs().Info(jobName, c.queryOptions())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, group := range job.TaskGroups {
+		if *group.Name == groupName {
+			return *group.Count, nil
+		}
+	}
+
+	return 0, fmt.Errorf("group %v was not found in job %v", groupName, jobName)
+}
+
+// GetJobAllocations identifies all allocations for an active job group.
+func (c *nomadClient) GetJobAllocations(allocs []*nomad.AllocationListStub, gsp *structs.GroupScalingPolicy,
+	history *structs.UtilizationHistory) {
 	var cpuPercentAll float64
 	var memPercentAll float64
 	nAllocs := 0
+	gsp.RunningCount = 0
 
 	for _, allocationStub := range allocs {
+		// Only allocations belonging to the group being evaluated are considered.
+		if allocationStub.TaskGroup != gsp.GroupName {
+			continue
+		}
+
 		if (allocationStub.ClientStatus == nomadStructs.AllocClientStatusRunning) &&
 			(allocationStub.DesiredStatus == nomadStructs.AllocDesiredStatusRun) {
+			gsp.RunningCount++
 
 			if alloc, _, err := c.nomad.Allocations().Info(allocationStub.ID, c.queryOptions()); err == nil && alloc != nil {
-				cpuPercent, memPercent := c.GetAllocationStats(alloc, gsp)
+				cpuPercent, memPercent := c.GetAllocationStats(alloc, gsp, history)
 				cpuPercentAll += cpuPercent
 				memPercentAll += memPercent
 				nAllocs++
@@ -373,14 +431,20 @@ func (c *nomadClient) VerifyNodeHealth(nodeIP string) (healthy bool) {
 }
 
 // GetAllocationStats discovers the resources consumed by a particular Nomad
-// allocation.
-func (c *nomadClient) GetAllocationStats(allocation *nomad.Allocation, scalingPolicy *structs.GroupScalingPolicy) (float64, float64) {
+// allocation. The resources consumed by each task are recorded in the
+// utilization history to support right-sizing recommendations.
+func (c *nomadClient) GetAllocationStats(allocation *nomad.Allocation, scalingPolicy *structs.GroupScalingPolicy,
+	history *structs.UtilizationHistory) (float64, float64) {
 	stats, err := c.nomad.Allocations().Stats(allocation, c.queryOptions())
 	if err != nil {
 		logging.Error("client/nomad: failed to retrieve allocation statistics from client %v: %v\n", allocation.NodeID, err)
 		return 0, 0
 	}
 
+	if history != nil {
+		recordTaskUtilization(allocation, stats, history)
+	}
+
 	cs := stats.ResourceUsage.CpuStats
 	ms := stats.ResourceUsage.MemoryStats
 
@@ -389,6 +453,34 @@ func (c *nomadClient) GetAllocationStats(allocation *nomad.Allocation, scalingPo
 			scalingPolicy.Tasks.Resources.MemoryMB)
 }
 
+// recordTaskUtilization records the resources consumed by each task of an
+// allocation, along with the resources allocated to it, in the utilization
+// history.
+func recordTaskUtilization(allocation *nomad.Allocation,
+	stats *nomad.AllocResourceUsage, history *structs.UtilizationHistory) {
+
+	timestamp := time.Now()
+
+	for taskName, usage := range stats.Tasks {
+		resources, ok := allocation.TaskResources[taskName]
+		if !ok || resources.CPU == nil || resources.MemoryMB == nil {
+			continue
+		}
+
+		if usage == nil || usage.ResourceUsage == nil ||
+			usage.ResourceUsage.CpuStats == nil || usage.ResourceUsage.MemoryStats == nil {
+			continue
+		}
+
+		history.Record(allocation.JobID, allocation.TaskGroup, taskName,
+			*resources.CPU, *resources.MemoryMB, structs.UtilizationSample{
+				Timestamp: timestamp,
+				CPUMHz:    usage.ResourceUsage.CpuStats.TotalTicks,
+				MemoryMB:  float64(usage.ResourceUsage.MemoryStats.RSS) / bytesPerMegabyte,
+			})
+	}
+}
+
 // MaxAllowedClusterUtilization calculates the maximum allowed cluster utilization after
 // taking into consideration node fault-tolerance and scaling overhead.
 func MaxAllowedClusterUtilization(capacity *structs.ClusterCapacity, nodeFaultTolerance int, scaleIn bool) (maxAllowedUtilization int) {
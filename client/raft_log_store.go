@@ -0,0 +1,193 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/hashicorp/raft"
+	bolt "go.etcd.io/bbolt"
+)
+
+// logsBucket is the BoltDB bucket in which Raft log entries are stored, keyed
+// by log index. stableBucket stores the Raft stable configuration such as the
+// current term and vote.
+var (
+	logsBucket   = []byte("logs")
+	stableBucket = []byte("stable")
+)
+
+// errKeyNotFound is returned by the stable store when a key does not exist,
+// matching the error text expected by Raft.
+var errKeyNotFound = errors.New("not found")
+
+// RaftLogStore provides durable Raft log and stable storage in a local
+// BoltDB database.
+type RaftLogStore struct {
+	db *bolt.DB
+}
+
+// NewRaftLogStore is used to construct a new Raft log store backed by the
+// BoltDB database at the specified path, creating it if required.
+func NewRaftLogStore(path string) (*RaftLogStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open raft log database %v: %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{logsBucket, stableBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to initialize raft log database %v: %v",
+			path, err)
+	}
+
+	return &RaftLogStore{db: db}, nil
+}
+
+// Close releases the lock held on the Raft log database.
+func (r *RaftLogStore) Close() error {
+	return r.db.Close()
+}
+
+// FirstIndex returns the first index written, or zero for no entries.
+func (r *RaftLogStore) FirstIndex() (index uint64, err error) {
+	err = r.db.View(func(tx *bolt.Tx) error {
+		if k, _ := tx.Bucket(logsBucket).Cursor().First(); k != nil {
+			index = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	return
+}
+
+// LastIndex returns the last index written, or zero for no entries.
+func (r *RaftLogStore) LastIndex() (index uint64, err error) {
+	err = r.db.View(func(tx *bolt.Tx) error {
+		if k, _ := tx.Bucket(logsBucket).Cursor().Last(); k != nil {
+			index = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	return
+}
+
+// GetLog retrieves the log entry at the provided index.
+func (r *RaftLogStore) GetLog(index uint64, log *raft.Log) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(logsBucket).Get(uint64Key(index))
+		if v == nil {
+			return raft.ErrLogNotFound
+		}
+		return decodeMsgpack(v, log)
+	})
+}
+
+// StoreLog stores a single log entry.
+func (r *RaftLogStore) StoreLog(log *raft.Log) error {
+	return r.StoreLogs([]*raft.Log{log})
+}
+
+// StoreLogs stores multiple log entries in a single transaction.
+func (r *RaftLogStore) StoreLogs(logs []*raft.Log) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(logsBucket)
+
+		for _, log := range logs {
+			value, err := encodeMsgpack(log)
+			if err != nil {
+				return err
+			}
+
+			if err = bucket.Put(uint64Key(log.Index), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRange deletes the log entries between the min and max indexes,
+// inclusive.
+func (r *RaftLogStore) DeleteRange(min, max uint64) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(logsBucket).Cursor()
+
+		for k, _ := c.Seek(uint64Key(min)); k != nil; k, _ = c.Next() {
+			if binary.BigEndian.Uint64(k) > max {
+				break
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Set stores a value in the stable store.
+func (r *RaftLogStore) Set(key []byte, val []byte) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stableBucket).Put(key, val)
+	})
+}
+
+// Get retrieves a value from the stable store.
+func (r *RaftLogStore) Get(key []byte) (val []byte, err error) {
+	err = r.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(stableBucket).Get(key)
+		if v == nil {
+			return errKeyNotFound
+		}
+		val = append([]byte(nil), v...)
+		return nil
+	})
+	return
+}
+
+// SetUint64 stores an integer value in the stable store.
+func (r *RaftLogStore) SetUint64(key []byte, val uint64) error {
+	return r.Set(key, uint64Key(val))
+}
+
+// GetUint64 retrieves an integer value from the stable store, returning zero
+// if the key does not exist.
+func (r *RaftLogStore) GetUint64(key []byte) (uint64, error) {
+	val, err := r.Get(key)
+	if err == errKeyNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(val), nil
+}
+
+// uint64Key encodes an integer as a big endian byte slice so that keys sort
+// in numeric order.
+func uint64Key(i uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, i)
+	return buf
+}
+
+// decodeMsgpack decodes a msgpack encoded value.
+func decodeMsgpack(buf []byte, out interface{}) error {
+	return codec.NewDecoder(bytes.NewReader(buf), &codec.MsgpackHandle{}).Decode(out)
+}
+
+// encodeMsgpack encodes a value using msgpack.
+func encodeMsgpack(in interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := codec.NewEncoder(&buf, &codec.MsgpackHandle{}).Encode(in)
+	return buf.Bytes(), err
+}
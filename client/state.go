@@ -5,13 +5,58 @@ import (
 	"fmt"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
+
 	"github.com/elsevier-core-engineering/replicator/logging"
 	"github.com/elsevier-core-engineering/replicator/replicator/structs"
 	consul "github.com/hashicorp/consul/api"
 )
 
-// ReadState does stuff and things.
-func (c *consulClient) ReadState(state *structs.ScalingState, force bool) {
+// stateWriteAttempts is the maximum number of attempts made to write a state
+// object when conflicting writes are detected.
+const stateWriteAttempts = 5
+
+// The consulStateStore object persists scaling state in the Consul Key/Value
+// Store.
+type consulStateStore struct {
+	consul *consul.Client
+}
+
+// NewStateStore is used to construct the state store for the backend
+// specified in the state configuration.
+func NewStateStore(config *structs.Config) (structs.StateStore, error) {
+	backend := structs.StateBackendConsul
+	if config.State != nil && config.State.Backend != "" {
+		backend = config.State.Backend
+	}
+
+	switch backend {
+	case structs.StateBackendConsul:
+		return NewConsulStateStore(config.Consul, config.ConsulToken)
+	case structs.StateBackendBolt:
+		return NewBoltStateStore(config.State.Path)
+	case structs.StateBackendRaft:
+		return nil, fmt.Errorf("not supported with the raft state backend, " +
+			"which is only available through the running Replicator servers")
+	default:
+		return nil, fmt.Errorf("unsupported state backend %q", backend)
+	}
+}
+
+// NewConsulStateStore is used to construct a new state store backed by the
+// Consul Key/Value Store at the specified address.
+func NewConsulStateStore(addr, token string) (structs.StateStore, error) {
+	c, err := newConsulAPIClient(addr, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &consulStateStore{consul: c}, nil
+}
+
+// ReadState attempts to read state tracking information from the Consul
+// Key/Value Store from the path provided.
+func (c *consulStateStore) ReadState(state *structs.ScalingState, force bool) {
 	logging.Debug("client/state: attempting to read state tracking "+
 		"information from Consul at location %v", state.StatePath)
 
@@ -38,15 +83,20 @@ func (c *consulClient) ReadState(state *structs.ScalingState, force bool) {
 			logging.Debug("client/state: initialization has been enabled, "+
 				"writing initial state object at location %v", state.StatePath)
 
-			c.PersistState(state)
+			state.ModifyIndex = 0
+			state.Snapshot()
+
+			if err := c.PersistState(state); err != nil {
+				logging.Error("%v", err)
+			}
 		}
 
 		// Return unmodified struct back to the caller.
 		return
 	}
 
-	// Deserialize state tracking data.
-	err = json.Unmarshal(pair.Value, state)
+	// Deserialize state tracking data, upgrading it to the current schema.
+	err = decodeState(pair.Value, state)
 	if err != nil {
 		logging.Error("client/state: an error occurred while attempting to "+
 			"deserialize scaling state retrieved from persistent storage: %v", err)
@@ -56,50 +106,345 @@ func (c *consulClient) ReadState(state *structs.ScalingState, force bool) {
 		return
 	}
 
-	logging.Debug("client/consul: successfully loaded state tracking "+
+	// Track the index of the state object for check-and-set writes.
+	state.ModifyIndex = pair.ModifyIndex
+	state.Snapshot()
+
+	logging.Debug("client/state: successfully loaded state tracking "+
 		"information from Consul, data was last updated: %v",
 		state.LastUpdated)
 
 	return
 }
 
-// WriteState is responsible for persistently storing state tracking
-// information in the Consul Key/Value Store.
-func (c *consulClient) PersistState(state *structs.ScalingState) (err error) {
+// PersistState is responsible for persistently storing state tracking
+// information in the Consul Key/Value Store. Writes are performed using
+// check-and-set against the index at which the state was last read or
+// written. If the state has been modified elsewhere, the latest copy is read
+// and merged before retrying.
+func (c *consulStateStore) PersistState(state *structs.ScalingState) (err error) {
 
 	logging.Debug("client/state: attempting to persistently store scaling "+
 		"state in Consul at location %v", state.StatePath)
 
-	// Set the last_updated timestamp before serialization
-	state.LastUpdated = time.Now()
+	// Instantiate new Consul Key/Value client.
+	kv := c.consul.KV()
+
+	for attempt := 1; attempt <= stateWriteAttempts; attempt++ {
+		// Set the last_updated timestamp and schema version before serialization
+		state.LastUpdated = time.Now()
+		state.SchemaVersion = structs.StateSchemaVersion
+
+		// Marshal the state struct into a JSON string for persistent storage.
+		scalingState, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("client/state: an error occurred when attempting to "+
+				"serialize scaling state for persistent storage: %v", err)
+		}
+
+		// Build the key/value pair struct for persistent storage.
+		d := &consul.KVPair{
+			Key:         state.StatePath,
+			Value:       scalingState,
+			ModifyIndex: state.ModifyIndex,
+		}
+
+		// Attempt to write scaling state to Consul Key/Value Store, tracking
+		// the index of the write for subsequent writes.
+		ok, index, err := casWrite(kv, d)
+		if err != nil {
+			metrics.IncrCounter([]string{"state", "write", "failure"}, 1)
+			return fmt.Errorf("client/state: an error occurred when attempting to "+
+				"write scaling state data to Consul: %v", err)
+		}
+
+		if ok {
+			state.ModifyIndex = index
+			state.Snapshot()
+
+			logging.Debug("client/state: successfully stored scaling state in "+
+				"Consul at location %v", state.StatePath)
+			return nil
+		}
+
+		metrics.IncrCounter([]string{"state", "write", "conflict"}, 1)
+		logging.Warning("client/state: scaling state at location %v was modified "+
+			"concurrently (attempt %v of %v), merging with the latest copy and "+
+			"retrying", state.StatePath, attempt, stateWriteAttempts)
+
+		// Read the latest object, which carries the index it was written at.
+		pair, _, err := kv.Get(state.StatePath, nil)
+		if err != nil {
+			return fmt.Errorf("client/state: an error occurred when attempting to "+
+				"read scaling state data from Consul: %v", err)
+		}
+
+		remote := &structs.ScalingState{}
+		if pair != nil {
+			if err = decodeState(pair.Value, remote); err != nil {
+				return fmt.Errorf("client/state: an error occurred while attempting "+
+					"to deserialize scaling state retrieved from Consul: %v", err)
+			}
+			remote.ModifyIndex = pair.ModifyIndex
+		}
+
+		state.Merge(remote)
+		state.ModifyIndex = remote.ModifyIndex
+	}
+
+	metrics.IncrCounter([]string{"state", "write", "failure"}, 1)
+	return fmt.Errorf("client/state: unable to write scaling state to Consul at "+
+		"location %v after %v conflicting attempts", state.StatePath,
+		stateWriteAttempts)
+}
 
-	// Marshal the state struct into a JSON string for persistent storage.
-	scalingState, err := json.Marshal(state)
+// ListState returns all state objects stored in the Consul Key/Value Store
+// under the provided path prefix.
+func (c *consulStateStore) ListState(prefix string) (states []*structs.ScalingState, err error) {
+	pairs, _, err := c.consul.KV().List(prefix, nil)
 	if err != nil {
-		err = fmt.Errorf("client/state: an error occurred when attempting to "+
-			"serialize scaling state for persistent storage: %v", err)
-		return
+		return nil, fmt.Errorf("client/state: an error occurred when attempting "+
+			"to list scaling state in Consul at location %v: %v", prefix, err)
 	}
 
-	// Build the key/value pair struct for persistent storage.
-	d := &consul.KVPair{
-		Key:   state.StatePath,
-		Value: scalingState,
+	for _, pair := range pairs {
+		state := &structs.ScalingState{}
+		if err = decodeState(pair.Value, state); err != nil {
+			return nil, fmt.Errorf("client/state: an error occurred while "+
+				"attempting to deserialize scaling state at location %v: %v",
+				pair.Key, err)
+		}
+
+		state.StatePath = pair.Key
+		state.ModifyIndex = pair.ModifyIndex
+		state.Snapshot()
+
+		states = append(states, state)
 	}
 
-	// Instantiate new Consul Key/Value client.
+	return
+}
+
+// DeleteState removes the state object and scaling event history of a
+// resource from the Consul Key/Value Store using check-and-set against the
+// index at which the state was last read or written.
+func (c *consulStateStore) DeleteState(state *structs.ScalingState) error {
 	kv := c.consul.KV()
 
-	// Attempt to write scaling state to Consul Key/Value Store.
-	_, err = kv.Put(d, nil)
+	ok, _, err := kv.DeleteCAS(&consul.KVPair{
+		Key:         state.StatePath,
+		ModifyIndex: state.ModifyIndex,
+	}, nil)
 	if err != nil {
-		err = fmt.Errorf("client/state: an error occurred when attempting to "+
-			"write scaling state data to Consul: %v", err)
-		return
+		return fmt.Errorf("client/state: an error occurred when attempting to "+
+			"delete scaling state from Consul at location %v: %v",
+			state.StatePath, err)
 	}
 
-	logging.Debug("client/state: successfully stored scaling state in Consul "+
-		"at location %v", state.StatePath)
+	if !ok {
+		return fmt.Errorf("client/state: scaling state at location %v was "+
+			"modified concurrently and has not been deleted", state.StatePath)
+	}
 
-	return
+	if path, _ := structs.HistoryPath(state.StatePath); path != "" {
+		if _, err = kv.Delete(path, nil); err != nil {
+			return fmt.Errorf("client/state: an error occurred when attempting to "+
+				"delete scaling event history from Consul at location %v: %v",
+				path, err)
+		}
+	}
+
+	logging.Debug("client/state: successfully deleted scaling state from "+
+		"Consul at location %v", state.StatePath)
+
+	return nil
+}
+
+// decodeState deserializes a raw state document into the state object after
+// upgrading the document to the current schema version.
+func decodeState(data []byte, state *structs.ScalingState) error {
+	upgraded, version, err := structs.MigrateState(data)
+	if err != nil {
+		return err
+	}
+
+	if version != structs.StateSchemaVersion {
+		logging.Debug("client/state: upgraded state at location %v from schema "+
+			"version %v to %v", state.StatePath, version,
+			structs.StateSchemaVersion)
+	}
+
+	return json.Unmarshal(upgraded, state)
+}
+
+// AppendEvent adds an event to the scaling event history stored in the
+// Consul Key/Value Store at the path provided. The history is written using
+// check-and-set so that events recorded concurrently are not lost.
+func (c *consulStateStore) AppendEvent(path string, event *structs.ScalingEvent,
+	retention *structs.History) error {
+
+	kv := c.consul.KV()
+
+	for attempt := 1; attempt <= stateWriteAttempts; attempt++ {
+		var events []*structs.ScalingEvent
+		var index uint64
+
+		pair, _, err := kv.Get(path, nil)
+		if err != nil {
+			return fmt.Errorf("client/state: an error occurred when attempting to "+
+				"read scaling event history from Consul: %v", err)
+		}
+
+		if pair != nil {
+			if err = json.Unmarshal(pair.Value, &events); err != nil {
+				return fmt.Errorf("client/state: an error occurred while attempting "+
+					"to deserialize scaling event history at location %v: %v", path, err)
+			}
+			index = pair.ModifyIndex
+		}
+
+		events = retention.Prune(append(events, event), time.Now())
+
+		value, err := json.Marshal(events)
+		if err != nil {
+			return fmt.Errorf("client/state: an error occurred when attempting to "+
+				"serialize scaling event history: %v", err)
+		}
+
+		ok, _, err := kv.CAS(&consul.KVPair{
+			Key:         path,
+			Value:       value,
+			ModifyIndex: index,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("client/state: an error occurred when attempting to "+
+				"write scaling event history to Consul: %v", err)
+		}
+
+		if ok {
+			return nil
+		}
+
+		logging.Debug("client/state: scaling event history at location %v was "+
+			"modified concurrently (attempt %v of %v), retrying", path, attempt,
+			stateWriteAttempts)
+	}
+
+	return fmt.Errorf("client/state: unable to write scaling event history to "+
+		"Consul at location %v after %v conflicting attempts", path,
+		stateWriteAttempts)
+}
+
+// ListEvents returns all scaling events stored in the Consul Key/Value Store
+// under the provided path prefix.
+func (c *consulStateStore) ListEvents(prefix string) (events []*structs.ScalingEvent, err error) {
+	pairs, _, err := c.consul.KV().List(prefix, nil)
+	if err != nil {
+		return nil, fmt.Errorf("client/state: an error occurred when attempting "+
+			"to list scaling event history in Consul at location %v: %v", prefix, err)
+	}
+
+	for _, pair := range pairs {
+		var history []*structs.ScalingEvent
+		if err = json.Unmarshal(pair.Value, &history); err != nil {
+			return nil, fmt.Errorf("client/state: an error occurred while "+
+				"attempting to deserialize scaling event history at location %v: %v",
+				pair.Key, err)
+		}
+
+		events = append(events, history...)
+	}
+
+	structs.SortEvents(events)
+	return events, nil
+}
+
+// ReadACL reads the ACL tokens and policies from the Consul Key/Value Store
+// at the path referenced by the ACL state.
+func (c *consulStateStore) ReadACL(acl *structs.ACLState) error {
+	pair, _, err := c.consul.KV().Get(acl.Path, nil)
+	if err != nil {
+		return fmt.Errorf("client/state: an error occurred when attempting to "+
+			"read ACL state from Consul at location %v: %v", acl.Path, err)
+	}
+
+	if pair == nil {
+		return nil
+	}
+
+	if err = json.Unmarshal(pair.Value, acl); err != nil {
+		return fmt.Errorf("client/state: an error occurred while attempting to "+
+			"deserialize ACL state at location %v: %v", acl.Path, err)
+	}
+	acl.ModifyIndex = pair.ModifyIndex
+
+	return nil
+}
+
+// PersistACL stores the ACL tokens and policies in the Consul Key/Value Store
+// using check-and-set against the index at which the ACL state was last read
+// or written.
+func (c *consulStateStore) PersistACL(acl *structs.ACLState) error {
+	kv := c.consul.KV()
+
+	value, err := json.Marshal(acl)
+	if err != nil {
+		return fmt.Errorf("client/state: an error occurred when attempting to "+
+			"serialize ACL state: %v", err)
+	}
+
+	ok, index, err := casWrite(kv, &consul.KVPair{
+		Key:         acl.Path,
+		Value:       value,
+		ModifyIndex: acl.ModifyIndex,
+	})
+	if err != nil {
+		return fmt.Errorf("client/state: an error occurred when attempting to "+
+			"write ACL state to Consul: %v", err)
+	}
+
+	if !ok {
+		return fmt.Errorf("client/state: ACL state at location %v was modified "+
+			"concurrently and has not been written", acl.Path)
+	}
+
+	acl.ModifyIndex = index
+
+	return nil
+}
+
+// casWrite writes the key/value pair using check-and-set against its modify
+// index and returns the index of the write. The index is read back in the
+// same transaction as the write so that the index of a later write by another
+// client is never adopted. False is returned if the write conflicted.
+func casWrite(kv *consul.KV, pair *consul.KVPair) (bool, uint64, error) {
+	ops := consul.KVTxnOps{
+		&consul.KVTxnOp{
+			Verb:  consul.KVCAS,
+			Key:   pair.Key,
+			Value: pair.Value,
+			Index: pair.ModifyIndex,
+		},
+		&consul.KVTxnOp{
+			Verb: consul.KVGet,
+			Key:  pair.Key,
+		},
+	}
+
+	ok, resp, _, err := kv.Txn(ops, nil)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// The transaction is rolled back if the check-and-set fails.
+	if !ok {
+		return false, 0, nil
+	}
+
+	if resp == nil || len(resp.Results) != len(ops) || resp.Results[1] == nil {
+		return false, 0, fmt.Errorf("unexpected transaction response writing %v",
+			pair.Key)
+	}
+
+	return true, resp.Results[1].ModifyIndex, nil
 }
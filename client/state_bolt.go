@@ -0,0 +1,364 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// stateBucket is the BoltDB bucket in which scaling state is stored, keyed
+// by the state path. indexBucket stores the modify index of each state object
+// to support check-and-set writes. historyBucket stores the scaling event
+// history of each resource, keyed by the history path.
+var (
+	stateBucket   = []byte("state")
+	indexBucket   = []byte("index")
+	historyBucket = []byte("history")
+)
+
+// The boltStateStore object persists scaling state in a local embedded
+// BoltDB database, allowing single instance deployments to run without
+// Consul.
+type boltStateStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStateStore is used to construct a new state store backed by the
+// BoltDB database at the specified path, creating it if required.
+func NewBoltStateStore(path string) (structs.StateStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open state database %v: %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{stateBucket, indexBucket, historyBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to initialize state database %v: %v",
+			path, err)
+	}
+
+	return &boltStateStore{db: db}, nil
+}
+
+// Close releases the lock held on the state database.
+func (b *boltStateStore) Close() error {
+	return b.db.Close()
+}
+
+// ReadState attempts to read state tracking information from the state
+// database at the path provided.
+func (b *boltStateStore) ReadState(state *structs.ScalingState, force bool) {
+	logging.Debug("client/state_bolt: attempting to read state tracking "+
+		"information from location %v", state.StatePath)
+
+	var value []byte
+	var index uint64
+	b.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(stateBucket).Get([]byte(state.StatePath)); v != nil {
+			value = make([]byte, len(v))
+			copy(value, v)
+			index = modifyIndex(tx, state.StatePath)
+		}
+		return nil
+	})
+
+	if value == nil {
+		logging.Debug("client/state_bolt: no state tracking information is "+
+			"present at location %v", state.StatePath)
+
+		if force {
+			logging.Debug("client/state_bolt: initialization has been enabled, "+
+				"writing initial state object at location %v", state.StatePath)
+
+			state.ModifyIndex = 0
+			state.Snapshot()
+
+			if err := b.PersistState(state); err != nil {
+				logging.Error("%v", err)
+			}
+		}
+		return
+	}
+
+	if err := decodeState(value, state); err != nil {
+		logging.Error("client/state_bolt: an error occurred while attempting to "+
+			"deserialize scaling state retrieved from persistent storage: %v", err)
+		return
+	}
+
+	// Track the index of the state object for check-and-set writes.
+	state.ModifyIndex = index
+	state.Snapshot()
+
+	logging.Debug("client/state_bolt: successfully loaded state tracking "+
+		"information, data was last updated: %v", state.LastUpdated)
+}
+
+// PersistState is responsible for persistently storing state tracking
+// information in the state database. Writes are performed using
+// check-and-set against the index at which the state was last read or
+// written. If the state has been modified elsewhere, the latest copy is
+// merged before writing.
+func (b *boltStateStore) PersistState(state *structs.ScalingState) error {
+	logging.Debug("client/state_bolt: attempting to persistently store "+
+		"scaling state at location %v", state.StatePath)
+
+	key := []byte(state.StatePath)
+
+	// BoltDB serializes write transactions, so a conflict can be resolved by
+	// merging within the same transaction without the need to retry.
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		index := modifyIndex(tx, state.StatePath)
+
+		if index != state.ModifyIndex {
+			metrics.IncrCounter([]string{"state", "write", "conflict"}, 1)
+			logging.Warning("client/state_bolt: scaling state at location %v was "+
+				"modified concurrently, merging with the latest copy", state.StatePath)
+
+			remote := &structs.ScalingState{}
+			if v := tx.Bucket(stateBucket).Get(key); v != nil {
+				if err := decodeState(v, remote); err != nil {
+					return fmt.Errorf("unable to deserialize scaling state: %v", err)
+				}
+			}
+			state.Merge(remote)
+		}
+
+		// Set the last_updated timestamp and schema version before serialization
+		state.LastUpdated = time.Now()
+		state.SchemaVersion = structs.StateSchemaVersion
+
+		scalingState, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("unable to serialize scaling state: %v", err)
+		}
+
+		if err = tx.Bucket(stateBucket).Put(key, scalingState); err != nil {
+			return err
+		}
+
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, index+1)
+		if err = tx.Bucket(indexBucket).Put(key, buf); err != nil {
+			return err
+		}
+
+		state.ModifyIndex = index + 1
+		return nil
+	})
+	if err != nil {
+		metrics.IncrCounter([]string{"state", "write", "failure"}, 1)
+		return fmt.Errorf("client/state_bolt: an error occurred when attempting "+
+			"to write scaling state data: %v", err)
+	}
+
+	state.Snapshot()
+
+	logging.Debug("client/state_bolt: successfully stored scaling state at "+
+		"location %v", state.StatePath)
+
+	return nil
+}
+
+// ListState returns all state objects stored in the state database under the
+// provided path prefix.
+func (b *boltStateStore) ListState(prefix string) (states []*structs.ScalingState, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(stateBucket).Cursor()
+
+		for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
+			state := &structs.ScalingState{}
+			if err := decodeState(v, state); err != nil {
+				return fmt.Errorf("client/state_bolt: an error occurred while "+
+					"attempting to deserialize scaling state at location %s: %v", k, err)
+			}
+
+			state.StatePath = string(k)
+			state.ModifyIndex = modifyIndex(tx, state.StatePath)
+			state.Snapshot()
+
+			states = append(states, state)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// DeleteState removes the state object and scaling event history of a
+// resource from the state database, provided the state has not been modified
+// since it was last read or written.
+func (b *boltStateStore) DeleteState(state *structs.ScalingState) error {
+	key := []byte(state.StatePath)
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		if index := modifyIndex(tx, state.StatePath); index != state.ModifyIndex {
+			return fmt.Errorf("state was modified concurrently and has not been " +
+				"deleted")
+		}
+
+		if err := tx.Bucket(stateBucket).Delete(key); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(indexBucket).Delete(key); err != nil {
+			return err
+		}
+
+		if path, _ := structs.HistoryPath(state.StatePath); path != "" {
+			return tx.Bucket(historyBucket).Delete([]byte(path))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("client/state_bolt: an error occurred when attempting "+
+			"to delete scaling state at location %v: %v", state.StatePath, err)
+	}
+
+	logging.Debug("client/state_bolt: successfully deleted scaling state at "+
+		"location %v", state.StatePath)
+
+	return nil
+}
+
+// AppendEvent adds an event to the scaling event history stored in the state
+// database at the path provided.
+func (b *boltStateStore) AppendEvent(path string, event *structs.ScalingEvent,
+	retention *structs.History) error {
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket)
+
+		var events []*structs.ScalingEvent
+		if v := bucket.Get([]byte(path)); v != nil {
+			if err := json.Unmarshal(v, &events); err != nil {
+				return fmt.Errorf("unable to deserialize scaling event history: %v", err)
+			}
+		}
+
+		events = retention.Prune(append(events, event), time.Now())
+
+		value, err := json.Marshal(events)
+		if err != nil {
+			return fmt.Errorf("unable to serialize scaling event history: %v", err)
+		}
+
+		return bucket.Put([]byte(path), value)
+	})
+	if err != nil {
+		return fmt.Errorf("client/state_bolt: an error occurred when attempting "+
+			"to write scaling event history at location %v: %v", path, err)
+	}
+
+	return nil
+}
+
+// ListEvents returns all scaling events stored in the state database under
+// the provided path prefix.
+func (b *boltStateStore) ListEvents(prefix string) (events []*structs.ScalingEvent, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+
+		for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
+			var history []*structs.ScalingEvent
+			if err := json.Unmarshal(v, &history); err != nil {
+				return fmt.Errorf("client/state_bolt: an error occurred while "+
+					"attempting to deserialize scaling event history at location %s: "+
+					"%v", k, err)
+			}
+
+			events = append(events, history...)
+		}
+
+		return nil
+	})
+
+	structs.SortEvents(events)
+	return
+}
+
+// modifyIndex returns the modify index of the state object at the specified
+// path, or zero if the state object does not exist.
+func modifyIndex(tx *bolt.Tx, path string) uint64 {
+	v := tx.Bucket(indexBucket).Get([]byte(path))
+	if len(v) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+// ReadACL reads the ACL tokens and policies from the state database at the
+// path referenced by the ACL state.
+func (b *boltStateStore) ReadACL(acl *structs.ACLState) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(stateBucket).Get([]byte(acl.Path))
+		if v == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(v, acl); err != nil {
+			return fmt.Errorf("client/state_bolt: an error occurred while "+
+				"attempting to deserialize ACL state at location %v: %v", acl.Path, err)
+		}
+		acl.ModifyIndex = modifyIndex(tx, acl.Path)
+
+		return nil
+	})
+}
+
+// PersistACL stores the ACL tokens and policies in the state database,
+// provided the ACL state has not been modified since it was last read or
+// written.
+func (b *boltStateStore) PersistACL(acl *structs.ACLState) error {
+	key := []byte(acl.Path)
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		index := modifyIndex(tx, acl.Path)
+		if index != acl.ModifyIndex {
+			return fmt.Errorf("ACL state was modified concurrently and has not " +
+				"been written")
+		}
+
+		value, err := json.Marshal(acl)
+		if err != nil {
+			return fmt.Errorf("unable to serialize ACL state: %v", err)
+		}
+
+		if err = tx.Bucket(stateBucket).Put(key, value); err != nil {
+			return err
+		}
+
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, index+1)
+		if err = tx.Bucket(indexBucket).Put(key, buf); err != nil {
+			return err
+		}
+
+		acl.ModifyIndex = index + 1
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("client/state_bolt: an error occurred when attempting "+
+			"to write ACL state at location %v: %v", acl.Path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestClient_BoltStateTracking(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(*boltStateStore).Close()
+
+	expected := &structs.ScalingState{
+		FailureCount: 2,
+		ResourceName: "example-pool",
+		StatePath:    "replicator/config/state/nodes/example-pool",
+	}
+
+	// Reading a missing state object with force enabled initializes it.
+	initial := &structs.ScalingState{StatePath: "replicator/config/state/jobs/example/cache"}
+	store.ReadState(initial, true)
+	if initial.LastUpdated.IsZero() {
+		t.Fatalf("expected initial state object to be persisted")
+	}
+
+	if err := store.PersistState(expected); err != nil {
+		t.Fatalf("error writing state: %v", err)
+	}
+
+	returnState := &structs.ScalingState{StatePath: expected.StatePath}
+	store.ReadState(returnState, false)
+
+	expected.LastUpdated = returnState.LastUpdated
+	expected.Snapshot()
+	returnState.Snapshot()
+	if !reflect.DeepEqual(returnState, expected) {
+		t.Fatalf("expected \n%#v\n\n, got \n\n%#v\n\n", expected, returnState)
+	}
+
+	states, err := store.ListState("replicator/config/state/nodes/")
+	if err != nil {
+		t.Fatalf("error listing state: %v", err)
+	}
+	if len(states) != 1 || states[0].ResourceName != "example-pool" {
+		t.Fatalf("expected a single worker pool state object but got %#v", states)
+	}
+}
+
+func TestClient_BoltStateConflict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(*boltStateStore).Close()
+
+	path := "replicator/config/state/jobs/example/cache"
+	if err := store.PersistState(&structs.ScalingState{
+		FailsafeMode: true,
+		FailureCount: 3,
+		StatePath:    path,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both the leader and an operator read the same copy of the state.
+	leader := &structs.ScalingState{StatePath: path}
+	store.ReadState(leader, false)
+
+	operator := &structs.ScalingState{StatePath: path}
+	store.ReadState(operator, false)
+
+	// The operator disables failsafe mode.
+	operator.FailsafeAdmin = true
+	operator.FailsafeMode = false
+	operator.FailureCount = 0
+	if err := store.PersistState(operator); err != nil {
+		t.Fatal(err)
+	}
+
+	// The leader writes back its stale copy after recording an event.
+	leader.ScaleOutRequests++
+	if err := store.PersistState(leader); err != nil {
+		t.Fatal(err)
+	}
+
+	result := &structs.ScalingState{StatePath: path}
+	store.ReadState(result, false)
+
+	if result.FailsafeMode || result.FailureCount != 0 || !result.FailsafeAdmin {
+		t.Fatalf("expected operator failsafe changes to be retained: %+v", result)
+	}
+	if result.ScaleOutRequests != 1 {
+		t.Fatalf("expected leader changes to be retained: %+v", result)
+	}
+	if result.ModifyIndex != 3 {
+		t.Fatalf("expected modify index 3 but got %v", result.ModifyIndex)
+	}
+}
+
+func TestClient_BoltEventHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(*boltStateStore).Close()
+
+	path := "replicator/config/history/jobs/example/cache"
+	retention := &structs.History{MaxEvents: 2}
+
+	for count := 1; count <= 3; count++ {
+		event := &structs.ScalingEvent{
+			CountAfter: count,
+			Outcome:    structs.EventOutcomeSuccess,
+			Timestamp:  time.Now().Add(time.Duration(count) * time.Second),
+		}
+
+		if err := store.AppendEvent(path, event, retention); err != nil {
+			t.Fatalf("error appending event: %v", err)
+		}
+	}
+
+	// An event belonging to another group of the job.
+	store.AppendEvent("replicator/config/history/jobs/example/web",
+		&structs.ScalingEvent{CountAfter: 10}, retention)
+
+	events, err := store.ListEvents(path)
+	if err != nil {
+		t.Fatalf("error listing events: %v", err)
+	}
+	if len(events) != 2 || events[0].CountAfter != 2 || events[1].CountAfter != 3 {
+		t.Fatalf("expected the two most recent events but got %#v", events)
+	}
+
+	events, _ = store.ListEvents("replicator/config/history/jobs/example/")
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events for the job but got %v", len(events))
+	}
+}
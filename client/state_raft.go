@@ -0,0 +1,531 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/raft"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// raftApplyTimeout is the maximum time to wait for a command to be applied
+// through the Raft log.
+const raftApplyTimeout = 10 * time.Second
+
+// Define the types of command applied to the state FSM through the Raft log.
+const (
+	raftAppendEvent  = "append_event"
+	raftDeleteState  = "delete_state"
+	raftPersistState = "persist_state"
+)
+
+// raftCommand is a state store operation replicated through the Raft log.
+type raftCommand struct {
+	// ModifyIndex is the index the state object is expected to be at for
+	// check-and-set operations.
+	ModifyIndex uint64
+
+	// Path is the location of the state object or event history.
+	Path string
+
+	// Retention is the event history retention applied when appending events.
+	Retention *structs.History
+
+	// Time is the time the command was submitted, used so that history
+	// retention is applied identically on every server.
+	Time time.Time
+
+	// Type is the type of operation.
+	Type string
+
+	// Value is the serialized state object or event.
+	Value []byte
+}
+
+// raftResponse is the result of applying a command to the state FSM. If a
+// check-and-set operation fails, the current value and index are returned.
+type raftResponse struct {
+	ModifyIndex uint64
+	OK          bool
+	Value       []byte
+}
+
+// raftEntry is a state object held by the state FSM.
+type raftEntry struct {
+	ModifyIndex uint64
+	Value       []byte
+}
+
+// raftSnapshot is the serialized form of the state FSM.
+type raftSnapshot struct {
+	History map[string][]byte
+	State   map[string]*raftEntry
+}
+
+// RaftFSM is the finite state machine which holds the scaling state and event
+// history replicated between Replicator servers through the Raft log.
+type RaftFSM struct {
+	history map[string][]byte
+	lock    sync.RWMutex
+	state   map[string]*raftEntry
+}
+
+// NewRaftFSM is used to construct an empty state FSM.
+func NewRaftFSM() *RaftFSM {
+	return &RaftFSM{
+		history: make(map[string][]byte),
+		state:   make(map[string]*raftEntry),
+	}
+}
+
+// Apply applies a committed Raft log entry to the state FSM.
+func (f *RaftFSM) Apply(log *raft.Log) interface{} {
+	var cmd raftCommand
+	if err := json.Unmarshal(log.Data, &cmd); err != nil {
+		return fmt.Errorf("unable to deserialize raft command: %v", err)
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	switch cmd.Type {
+	case raftPersistState:
+		if resp := f.checkIndex(cmd); !resp.OK {
+			return resp
+		}
+
+		f.state[cmd.Path] = &raftEntry{ModifyIndex: log.Index, Value: cmd.Value}
+		return &raftResponse{ModifyIndex: log.Index, OK: true}
+
+	case raftDeleteState:
+		if resp := f.checkIndex(cmd); !resp.OK {
+			return resp
+		}
+
+		delete(f.state, cmd.Path)
+		if path, _ := structs.HistoryPath(cmd.Path); path != "" {
+			delete(f.history, path)
+		}
+		return &raftResponse{OK: true}
+
+	case raftAppendEvent:
+		var events []*structs.ScalingEvent
+		if v, ok := f.history[cmd.Path]; ok {
+			if err := json.Unmarshal(v, &events); err != nil {
+				return fmt.Errorf("unable to deserialize scaling event history: %v", err)
+			}
+		}
+
+		event := &structs.ScalingEvent{}
+		if err := json.Unmarshal(cmd.Value, event); err != nil {
+			return fmt.Errorf("unable to deserialize scaling event: %v", err)
+		}
+
+		value, err := json.Marshal(cmd.Retention.Prune(append(events, event), cmd.Time))
+		if err != nil {
+			return fmt.Errorf("unable to serialize scaling event history: %v", err)
+		}
+
+		f.history[cmd.Path] = value
+		return &raftResponse{OK: true}
+
+	default:
+		return fmt.Errorf("unknown raft command type %q", cmd.Type)
+	}
+}
+
+// checkIndex compares the expected index of a check-and-set command against
+// the current index of the state object. The lock must be held by the caller.
+func (f *RaftFSM) checkIndex(cmd raftCommand) *raftResponse {
+	var index uint64
+	var value []byte
+
+	if entry, ok := f.state[cmd.Path]; ok {
+		index, value = entry.ModifyIndex, entry.Value
+	}
+
+	return &raftResponse{
+		ModifyIndex: index,
+		OK:          index == cmd.ModifyIndex,
+		Value:       value,
+	}
+}
+
+// Snapshot returns a point-in-time copy of the state FSM.
+func (f *RaftFSM) Snapshot() (raft.FSMSnapshot, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	snap := &raftSnapshot{
+		History: make(map[string][]byte, len(f.history)),
+		State:   make(map[string]*raftEntry, len(f.state)),
+	}
+
+	for k, v := range f.history {
+		snap.History[k] = v
+	}
+	for k, v := range f.state {
+		snap.State[k] = v
+	}
+
+	return snap, nil
+}
+
+// Restore replaces the contents of the state FSM from a snapshot.
+func (f *RaftFSM) Restore(r io.ReadCloser) error {
+	defer r.Close()
+
+	snap := &raftSnapshot{}
+	if err := json.NewDecoder(r).Decode(snap); err != nil {
+		return fmt.Errorf("unable to deserialize raft snapshot: %v", err)
+	}
+
+	if snap.History == nil {
+		snap.History = make(map[string][]byte)
+	}
+	if snap.State == nil {
+		snap.State = make(map[string]*raftEntry)
+	}
+
+	f.lock.Lock()
+	f.history, f.state = snap.History, snap.State
+	f.lock.Unlock()
+
+	return nil
+}
+
+// Persist writes the snapshot to the sink.
+func (s *raftSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release is invoked when the snapshot is no longer required.
+func (s *raftSnapshot) Release() {}
+
+// get returns a copy of the state object stored at the path provided.
+func (f *RaftFSM) get(path string) (entry raftEntry, ok bool) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if e, found := f.state[path]; found {
+		return *e, true
+	}
+	return
+}
+
+// list returns the paths and values stored under the prefix provided, sorted
+// by path.
+func list(values map[string][]byte, prefix string) (paths []string) {
+	for path := range values {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return
+}
+
+// The raftStateStore object persists scaling state in the state FSM which is
+// replicated between Replicator servers through the Raft log. Reads are
+// served from the local copy of the FSM while writes must be performed on
+// the leader.
+type raftStateStore struct {
+	fsm  *RaftFSM
+	raft *raft.Raft
+}
+
+// NewRaftStateStore is used to construct a new state store which persists
+// scaling state through the Raft log of the provided Raft instance.
+func NewRaftStateStore(r *raft.Raft, fsm *RaftFSM) structs.StateStore {
+	return &raftStateStore{fsm: fsm, raft: r}
+}
+
+// apply submits a command to the Raft log and returns the response of the
+// state FSM.
+func (r *raftStateStore) apply(cmd *raftCommand) (*raftResponse, error) {
+	buf, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize raft command: %v", err)
+	}
+
+	future := r.raft.Apply(buf, raftApplyTimeout)
+	if err = future.Error(); err != nil {
+		return nil, err
+	}
+
+	switch resp := future.Response().(type) {
+	case error:
+		return nil, resp
+	case *raftResponse:
+		return resp, nil
+	default:
+		return nil, fmt.Errorf("unexpected raft response %T", resp)
+	}
+}
+
+// ReadState attempts to read state tracking information from the local copy
+// of the state FSM at the path provided.
+func (r *raftStateStore) ReadState(state *structs.ScalingState, force bool) {
+	logging.Debug("client/state_raft: attempting to read state tracking "+
+		"information from location %v", state.StatePath)
+
+	entry, ok := r.fsm.get(state.StatePath)
+	if !ok {
+		logging.Debug("client/state_raft: no state tracking information is "+
+			"present at location %v", state.StatePath)
+
+		if force {
+			logging.Debug("client/state_raft: initialization has been enabled, "+
+				"writing initial state object at location %v", state.StatePath)
+
+			state.ModifyIndex = 0
+			state.Snapshot()
+
+			if err := r.PersistState(state); err != nil {
+				logging.Error("%v", err)
+			}
+		}
+		return
+	}
+
+	if err := decodeState(entry.Value, state); err != nil {
+		logging.Error("client/state_raft: an error occurred while attempting to "+
+			"deserialize scaling state retrieved from persistent storage: %v", err)
+		return
+	}
+
+	// Track the index of the state object for check-and-set writes.
+	state.ModifyIndex = entry.ModifyIndex
+	state.Snapshot()
+
+	logging.Debug("client/state_raft: successfully loaded state tracking "+
+		"information, data was last updated: %v", state.LastUpdated)
+}
+
+// PersistState is responsible for persistently storing state tracking
+// information through the Raft log. Writes are performed using check-and-set
+// against the index at which the state was last read or written. If the
+// state has been modified elsewhere, the latest copy is merged before
+// retrying.
+func (r *raftStateStore) PersistState(state *structs.ScalingState) error {
+	logging.Debug("client/state_raft: attempting to persistently store "+
+		"scaling state at location %v", state.StatePath)
+
+	for attempt := 1; attempt <= stateWriteAttempts; attempt++ {
+		// Set the last_updated timestamp and schema version before serialization
+		state.LastUpdated = time.Now()
+		state.SchemaVersion = structs.StateSchemaVersion
+
+		scalingState, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("client/state_raft: an error occurred when attempting "+
+				"to serialize scaling state for persistent storage: %v", err)
+		}
+
+		resp, err := r.apply(&raftCommand{
+			ModifyIndex: state.ModifyIndex,
+			Path:        state.StatePath,
+			Type:        raftPersistState,
+			Value:       scalingState,
+		})
+		if err != nil {
+			metrics.IncrCounter([]string{"state", "write", "failure"}, 1)
+			return fmt.Errorf("client/state_raft: an error occurred when attempting "+
+				"to write scaling state data: %v", err)
+		}
+
+		if resp.OK {
+			state.ModifyIndex = resp.ModifyIndex
+			state.Snapshot()
+
+			logging.Debug("client/state_raft: successfully stored scaling state at "+
+				"location %v", state.StatePath)
+			return nil
+		}
+
+		metrics.IncrCounter([]string{"state", "write", "conflict"}, 1)
+		logging.Warning("client/state_raft: scaling state at location %v was "+
+			"modified concurrently (attempt %v of %v), merging with the latest copy "+
+			"and retrying", state.StatePath, attempt, stateWriteAttempts)
+
+		remote := &structs.ScalingState{}
+		if resp.Value != nil {
+			if err = decodeState(resp.Value, remote); err != nil {
+				return fmt.Errorf("client/state_raft: an error occurred while "+
+					"attempting to deserialize scaling state: %v", err)
+			}
+		}
+
+		state.Merge(remote)
+		state.ModifyIndex = resp.ModifyIndex
+	}
+
+	metrics.IncrCounter([]string{"state", "write", "failure"}, 1)
+	return fmt.Errorf("client/state_raft: unable to write scaling state at "+
+		"location %v after %v conflicting attempts", state.StatePath,
+		stateWriteAttempts)
+}
+
+// ListState returns all state objects held in the local copy of the state
+// FSM under the provided path prefix.
+func (r *raftStateStore) ListState(prefix string) (states []*structs.ScalingState, err error) {
+	r.fsm.lock.RLock()
+	values := make(map[string][]byte)
+	indexes := make(map[string]uint64)
+	for path, entry := range r.fsm.state {
+		values[path] = entry.Value
+		indexes[path] = entry.ModifyIndex
+	}
+	r.fsm.lock.RUnlock()
+
+	for _, path := range list(values, prefix) {
+		state := &structs.ScalingState{}
+		if err = decodeState(values[path], state); err != nil {
+			return nil, fmt.Errorf("client/state_raft: an error occurred while "+
+				"attempting to deserialize scaling state at location %v: %v", path, err)
+		}
+
+		state.StatePath = path
+		state.ModifyIndex = indexes[path]
+		state.Snapshot()
+
+		states = append(states, state)
+	}
+
+	return
+}
+
+// DeleteState removes the state object and scaling event history of a
+// resource through the Raft log, provided the state has not been modified
+// since it was last read or written.
+func (r *raftStateStore) DeleteState(state *structs.ScalingState) error {
+	resp, err := r.apply(&raftCommand{
+		ModifyIndex: state.ModifyIndex,
+		Path:        state.StatePath,
+		Type:        raftDeleteState,
+	})
+	if err != nil {
+		return fmt.Errorf("client/state_raft: an error occurred when attempting "+
+			"to delete scaling state at location %v: %v", state.StatePath, err)
+	}
+
+	if !resp.OK {
+		return fmt.Errorf("client/state_raft: scaling state at location %v was "+
+			"modified concurrently and has not been deleted", state.StatePath)
+	}
+
+	logging.Debug("client/state_raft: successfully deleted scaling state at "+
+		"location %v", state.StatePath)
+
+	return nil
+}
+
+// AppendEvent adds an event to the scaling event history stored at the path
+// provided through the Raft log.
+func (r *raftStateStore) AppendEvent(path string, event *structs.ScalingEvent,
+	retention *structs.History) error {
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("client/state_raft: an error occurred when attempting "+
+			"to serialize scaling event: %v", err)
+	}
+
+	_, err = r.apply(&raftCommand{
+		Path:      path,
+		Retention: retention,
+		Time:      time.Now(),
+		Type:      raftAppendEvent,
+		Value:     value,
+	})
+	if err != nil {
+		return fmt.Errorf("client/state_raft: an error occurred when attempting "+
+			"to write scaling event history at location %v: %v", path, err)
+	}
+
+	return nil
+}
+
+// ListEvents returns all scaling events held in the local copy of the state
+// FSM under the provided path prefix.
+func (r *raftStateStore) ListEvents(prefix string) (events []*structs.ScalingEvent, err error) {
+	r.fsm.lock.RLock()
+	values := make(map[string][]byte, len(r.fsm.history))
+	for path, value := range r.fsm.history {
+		values[path] = value
+	}
+	r.fsm.lock.RUnlock()
+
+	for _, path := range list(values, prefix) {
+		var history []*structs.ScalingEvent
+		if err = json.Unmarshal(values[path], &history); err != nil {
+			return nil, fmt.Errorf("client/state_raft: an error occurred while "+
+				"attempting to deserialize scaling event history at location %v: %v",
+				path, err)
+		}
+
+		events = append(events, history...)
+	}
+
+	structs.SortEvents(events)
+	return events, nil
+}
+
+// ReadACL reads the ACL tokens and policies from the local copy of the state
+// FSM at the path referenced by the ACL state.
+func (r *raftStateStore) ReadACL(acl *structs.ACLState) error {
+	entry, ok := r.fsm.get(acl.Path)
+	if !ok {
+		return nil
+	}
+
+	if err := json.Unmarshal(entry.Value, acl); err != nil {
+		return fmt.Errorf("client/state_raft: an error occurred while "+
+			"attempting to deserialize ACL state at location %v: %v", acl.Path, err)
+	}
+	acl.ModifyIndex = entry.ModifyIndex
+
+	return nil
+}
+
+// PersistACL stores the ACL tokens and policies through the Raft log,
+// provided the ACL state has not been modified since it was last read or
+// written.
+func (r *raftStateStore) PersistACL(acl *structs.ACLState) error {
+	value, err := json.Marshal(acl)
+	if err != nil {
+		return fmt.Errorf("client/state_raft: an error occurred when attempting "+
+			"to serialize ACL state: %v", err)
+	}
+
+	resp, err := r.apply(&raftCommand{
+		ModifyIndex: acl.ModifyIndex,
+		Path:        acl.Path,
+		Type:        raftPersistState,
+		Value:       value,
+	})
+	if err != nil {
+		return fmt.Errorf("client/state_raft: an error occurred when attempting "+
+			"to write ACL state at location %v: %v", acl.Path, err)
+	}
+
+	if !resp.OK {
+		return fmt.Errorf("client/state_raft: ACL state at location %v was "+
+			"modified concurrently and has not been written", acl.Path)
+	}
+	acl.ModifyIndex = resp.ModifyIndex
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// applyCommand applies a command to the FSM at the provided log index.
+func applyCommand(t *testing.T, fsm *RaftFSM, index uint64, cmd *raftCommand) *raftResponse {
+	buf, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, ok := fsm.Apply(&raft.Log{Data: buf, Index: index}).(*raftResponse)
+	if !ok {
+		t.Fatalf("expected a raft response applying %#v", cmd)
+	}
+	return resp
+}
+
+func TestClient_RaftFSM(t *testing.T) {
+	fsm := NewRaftFSM()
+	path := "replicator/config/state/nodes/example-pool"
+
+	// Writing a new state object requires an expected index of zero.
+	resp := applyCommand(t, fsm, 5, &raftCommand{
+		Path: path, Type: raftPersistState, Value: []byte(`{"failure_count":1}`),
+	})
+	if !resp.OK || resp.ModifyIndex != 5 {
+		t.Fatalf("expected write to succeed at index 5 but got %#v", resp)
+	}
+
+	// A write against a stale index is rejected and returns the current copy.
+	resp = applyCommand(t, fsm, 6, &raftCommand{
+		Path: path, Type: raftPersistState, Value: []byte(`{"failure_count":2}`),
+	})
+	if resp.OK || resp.ModifyIndex != 5 || string(resp.Value) != `{"failure_count":1}` {
+		t.Fatalf("expected conflicting write to be rejected but got %#v", resp)
+	}
+
+	event, _ := json.Marshal(&structs.ScalingEvent{Timestamp: time.Now()})
+	historyPath, _ := structs.HistoryPath(path)
+	for i := uint64(7); i < 10; i++ {
+		applyCommand(t, fsm, i, &raftCommand{
+			Path:      historyPath,
+			Retention: &structs.History{MaxEvents: 2},
+			Time:      time.Now(),
+			Type:      raftAppendEvent,
+			Value:     event,
+		})
+	}
+
+	store := &raftStateStore{fsm: fsm}
+	events, err := store.ListEvents(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected history to be pruned to 2 events but got %v", len(events))
+	}
+
+	// Snapshot and restore the FSM into a new instance.
+	snap, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &testSnapshotSink{}
+	if err = snap.Persist(sink); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewRaftFSM()
+	if err = restored.Restore(ioutil.NopCloser(&sink.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := restored.get(path); !ok || entry.ModifyIndex != 5 {
+		t.Fatalf("expected restored state at index 5 but got %#v", entry)
+	}
+
+	// Deleting the state also removes the event history.
+	resp = applyCommand(t, restored, 10, &raftCommand{
+		ModifyIndex: 5, Path: path, Type: raftDeleteState,
+	})
+	if !resp.OK {
+		t.Fatalf("expected delete to succeed but got %#v", resp)
+	}
+	if _, ok := restored.get(path); ok {
+		t.Fatalf("expected state to be deleted")
+	}
+	if len(restored.history) != 0 {
+		t.Fatalf("expected event history to be deleted")
+	}
+}
+
+func TestClient_RaftLogStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewRaftLogStore(filepath.Join(dir, "raft.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if _, err = store.Get([]byte("missing")); err == nil || err.Error() != "not found" {
+		t.Fatalf("expected not found error but got %v", err)
+	}
+
+	if err = store.SetUint64([]byte("term"), 3); err != nil {
+		t.Fatal(err)
+	}
+	if term, _ := store.GetUint64([]byte("term")); term != 3 {
+		t.Fatalf("expected term 3 but got %v", term)
+	}
+
+	var logs []*raft.Log
+	for i := uint64(1); i <= 5; i++ {
+		logs = append(logs, &raft.Log{Data: []byte("data"), Index: i, Term: 1})
+	}
+	if err = store.StoreLogs(logs); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.DeleteRange(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := store.FirstIndex()
+	last, _ := store.LastIndex()
+	if first != 3 || last != 5 {
+		t.Fatalf("expected log indexes 3 to 5 but got %v to %v", first, last)
+	}
+
+	log := &raft.Log{}
+	if err = store.GetLog(4, log); err != nil || string(log.Data) != "data" {
+		t.Fatalf("expected log 4 to be returned but got %#v: %v", log, err)
+	}
+	if err = store.GetLog(1, log); err != raft.ErrLogNotFound {
+		t.Fatalf("expected deleted log to be missing but got %v", err)
+	}
+}
+
+// testSnapshotSink is an in-memory raft.SnapshotSink.
+type testSnapshotSink struct {
+	bytes.Buffer
+}
+
+func (s *testSnapshotSink) ID() string    { return "test" }
+func (s *testSnapshotSink) Cancel() error { return nil }
+func (s *testSnapshotSink) Close() error  { return nil }
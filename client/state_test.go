@@ -14,8 +14,8 @@ func TestClient_StateTracking(t *testing.T) {
 	c, s := testutil.MakeClientWithConfig(t)
 	defer s.Stop()
 
-	consul, _ := NewConsulClient(s.HTTPAddr, "")
-	c.ConsulClient = consul
+	store, _ := NewConsulStateStore(s.HTTPAddr, "")
+	c.StateStore = store
 
 	returnState := &structs.ScalingState{}
 	returnState.StatePath = c.ConsulKeyRoot + "/state/nodes/example-pool"
@@ -26,15 +26,27 @@ func TestClient_StateTracking(t *testing.T) {
 		StatePath:    c.ConsulKeyRoot + "/state/nodes/example-pool",
 	}
 
-	err := c.ConsulClient.PersistState(expected)
+	err := c.StateStore.PersistState(expected)
 	if err != nil {
 		t.Fatalf("error writing state to Consul: %v", err)
 	}
 
-	c.ConsulClient.ReadState(returnState, true)
+	c.StateStore.ReadState(returnState, true)
 	expected.LastUpdated = returnState.LastUpdated
+	expected.Snapshot()
+	returnState.Snapshot()
 	if !reflect.DeepEqual(returnState, expected) {
 		t.Fatalf("expected \n%#v\n\n, got \n\n%#v\n\n", expected, returnState)
 	}
 
 }
+
+func TestClient_NewStateStoreRaft(t *testing.T) {
+	config := &structs.Config{
+		State: &structs.State{Backend: structs.StateBackendRaft},
+	}
+
+	if _, err := NewStateStore(config); err == nil {
+		t.Fatal("expected an error opening the raft state backend")
+	}
+}
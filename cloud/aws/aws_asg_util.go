@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -37,7 +38,7 @@ func describeScalingGroup(asgName string,
 
 // getMostRecentInstance monitors a worker pool autoscaling group after a
 // scale out operation to identify the newly launched instance.
-func getMostRecentInstance(asg, region string) (node string, err error) {
+func getMostRecentInstance(ctx context.Context, asg, region string) (node string, err error) {
 	// Setup struct to track most recent instance information
 	instanceTracking := &structs.MostRecentNode{}
 
@@ -81,6 +82,9 @@ func getMostRecentInstance(asg, region string) (node string, err error) {
 
 	for {
 		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+
 		case <-timeout.C:
 			err = fmt.Errorf("cloud/aws: timeout reached while attempting to "+
 				"determine the most recently launched instance in autoscaling "+
@@ -137,7 +141,7 @@ func getMostRecentInstance(asg, region string) (node string, err error) {
 
 // detachInstance is used to detach a specified node from a worker pool
 // autoscaling group and automatically decrements the desired count.
-func detachInstance(asg, instanceID string,
+func detachInstance(ctx context.Context, asg, instanceID string,
 	svc *autoscaling.AutoScaling) (err error) {
 
 	// Setup the request parameters for the DetachInstances API call.
@@ -164,7 +168,7 @@ func detachInstance(asg, instanceID string,
 	// completed successfully, call the checkClusterScalingResult() method which
 	// will poll the ASG until it can verify the status.
 	if *resp.Activities[0].StatusCode != autoscaling.ScalingActivityStatusCodeSuccessful {
-		err = checkClusterScalingResult(resp.Activities[0].ActivityId, svc)
+		err = checkClusterScalingResult(ctx, resp.Activities[0].ActivityId, svc)
 	}
 	if err == nil {
 		logging.Info("cloud/aws: successfully detached instance %v from "+
@@ -176,7 +180,7 @@ func detachInstance(asg, instanceID string,
 
 // checkClusterScalingResult is used to poll a worker pool autoscaling group
 // to monitor a specified scaling activity for successful completion.
-func checkClusterScalingResult(activityID *string,
+func checkClusterScalingResult(ctx context.Context, activityID *string,
 	svc *autoscaling.AutoScaling) error {
 
 	// Setup our timeout and ticker value.
@@ -188,6 +192,9 @@ func checkClusterScalingResult(activityID *string,
 
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
 		case <-timeOut.C:
 			return fmt.Errorf("timeout reached while attempting to verify scaling "+
 				"activity %v completed successfully", activityID)
@@ -221,7 +228,7 @@ func checkClusterScalingResult(activityID *string,
 
 // verifyAsgUpdate validates that a scale out operation against a worker
 // pool autoscaling group has completed successfully.
-func verifyAsgUpdate(workerPool string, capacity int64,
+func verifyAsgUpdate(ctx context.Context, workerPool string, capacity int64,
 	svc *autoscaling.AutoScaling) error {
 
 	// Setup a ticker to poll the autoscaling group and report when an instance
@@ -238,6 +245,8 @@ func verifyAsgUpdate(workerPool string, capacity int64,
 
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
 
 		case <-timeout.C:
 			return fmt.Errorf("timeout reached while attempting to verify the "+
@@ -1,8 +1,8 @@
 package aws
 
 import (
+	"context"
 	"fmt"
-	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -43,22 +43,26 @@ func newAwsAsgService(region string) (Session *autoscaling.AutoScaling) {
 }
 
 // Scale is the entry point method for performing scaling operations with
-// the provider.
-func (sp *AwsScalingProvider) Scale(workerPool *structs.WorkerPool,
+// the provider. Cancelling the context stops any verification in progress
+// and returns the context error.
+func (sp *AwsScalingProvider) Scale(ctx context.Context, workerPool *structs.WorkerPool,
 	config *structs.Config, nodeRegistry *structs.NodeRegistry) (err error) {
 
 	switch workerPool.State.ScalingDirection {
 
 	case structs.ScalingDirectionOut:
 		// Initiate autoscaling group scaling operation.
-		err = sp.scaleOut(workerPool)
+		err = sp.scaleOut(ctx, workerPool)
 		if err != nil {
 			return err
 		}
 
 		// Initiate verification of the scaling operation to include retry
 		// attempts if any failures are detected.
-		if ok := sp.verifyScaledNode(workerPool, config, nodeRegistry); !ok {
+		if ok := sp.verifyScaledNode(ctx, workerPool, config, nodeRegistry); !ok {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			return fmt.Errorf("an error occurred while attempting to verify the "+
 				"scaling operation, the provider automatically retried the "+
 				"scaling operation up to the maximum retry threshold count %v",
@@ -67,7 +71,7 @@ func (sp *AwsScalingProvider) Scale(workerPool *structs.WorkerPool,
 
 	case structs.ScalingDirectionIn:
 		// Initiate autoscaling group scaling operation.
-		err = sp.scaleIn(workerPool, config)
+		err = sp.scaleIn(ctx, workerPool, config)
 		if err != nil {
 			return err
 		}
@@ -78,7 +82,7 @@ func (sp *AwsScalingProvider) Scale(workerPool *structs.WorkerPool,
 
 // scaleOut is the internal method used to initiate a scale out operation
 // against a worker pool autoscaling group.
-func (sp *AwsScalingProvider) scaleOut(workerPool *structs.WorkerPool) error {
+func (sp *AwsScalingProvider) scaleOut(ctx context.Context, workerPool *structs.WorkerPool) error {
 	// Get the current autoscaling group configuration.
 	asg, err := describeScalingGroup(workerPool.Name, sp.AsgService)
 	if err != nil {
@@ -108,7 +112,7 @@ func (sp *AwsScalingProvider) scaleOut(workerPool *structs.WorkerPool) error {
 		return err
 	}
 
-	err = verifyAsgUpdate(workerPool.Name, newCapacity, sp.AsgService)
+	err = verifyAsgUpdate(ctx, workerPool.Name, newCapacity, sp.AsgService)
 	if err != nil {
 		return err
 	}
@@ -118,7 +122,9 @@ func (sp *AwsScalingProvider) scaleOut(workerPool *structs.WorkerPool) error {
 
 // scaleIn is the internal method used to initiate a scale in operation
 // against a worker pool autoscaling group.
-func (sp *AwsScalingProvider) scaleIn(workerPool *structs.WorkerPool, config *structs.Config) error {
+func (sp *AwsScalingProvider) scaleIn(ctx context.Context, workerPool *structs.WorkerPool,
+	config *structs.Config) error {
+
 	// If no nodes have been registered as eligible for targeted scaling
 	// operations, throw an error and exit.
 	if len(workerPool.State.EligibleNodes) == 0 {
@@ -126,8 +132,8 @@ func (sp *AwsScalingProvider) scaleIn(workerPool *structs.WorkerPool, config *st
 			"scaling action, unable to detach and terminate")
 	}
 
-	// Setup client for Consul.
-	consulClient := config.ConsulClient
+	// Setup reference to the state store.
+	stateStore := config.StateStore
 
 	// Pop a target node from the list of eligible nodes.
 	targetNode := workerPool.State.EligibleNodes[0]
@@ -136,6 +142,10 @@ func (sp *AwsScalingProvider) scaleIn(workerPool *structs.WorkerPool, config *st
 
 	// Translate the node IP address to the EC2 instance ID.
 	instanceID := translateIptoID(targetNode, workerPool.Region)
+	if instanceID == "" {
+		return fmt.Errorf("unable to identify the instance of node %v in worker "+
+			"pool %v", targetNode, workerPool.Name)
+	}
 
 	// Setup parameters for the AWS API call to detach the target node
 	// from the worker pool autoscaling group.
@@ -158,40 +168,54 @@ func (sp *AwsScalingProvider) scaleIn(workerPool *structs.WorkerPool, config *st
 	} else {
 		// Monitor the scaling activity result.
 		if *resp.Activities[0].StatusCode != autoscaling.ScalingActivityStatusCodeSuccessful {
-			err = checkClusterScalingResult(resp.Activities[0].ActivityId, sp.AsgService)
+			err = checkClusterScalingResult(ctx, resp.Activities[0].ActivityId,
+				sp.AsgService)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
+	// Record the termination step of the scaling workflow so that an
+	// interrupted operation is completed by the next leader.
+	workerPool.State.AdvanceOperation(structs.OperationStepTerminate)
+	if err = stateStore.PersistState(workerPool.State); err != nil {
+		logging.Error("cloud/aws: %v", err)
+	}
+
 	// Once the node has been detached from the worker pool autoscaling group,
 	// terminate the instance.
-	err = terminateInstance(instanceID, workerPool.Region)
+	err = terminateInstance(ctx, instanceID, workerPool.Region)
 	if err != nil {
 		return fmt.Errorf("an error occurred while attempting to terminate "+
 			"instance %v from worker pool %v", instanceID, workerPool.Name)
 	}
 
 	// Record a successful scaling event and reset the failure count.
-	workerPool.State.LastScalingEvent = time.Now()
+	workerPool.State.RecordScalingEvent(structs.ScalingDirectionIn)
 	workerPool.State.FailureCount = 0
 
-	// Attempt to update state tracking information in Consul.
-	if err = consulClient.PersistState(workerPool.State); err != nil {
+	// Attempt to update state tracking information in persistent storage.
+	if err = stateStore.PersistState(workerPool.State); err != nil {
 		logging.Error("cloud/aws: %v", err)
 	}
 
 	return nil
 }
 
-func (sp *AwsScalingProvider) verifyScaledNode(workerPool *structs.WorkerPool,
+func (sp *AwsScalingProvider) verifyScaledNode(ctx context.Context, workerPool *structs.WorkerPool,
 	config *structs.Config, nodeRegistry *structs.NodeRegistry) (ok bool) {
 
-	// Setup reference to Consul client.
-	consulClient := config.ConsulClient
+	// Setup reference to the state store.
+	stateStore := config.StateStore
 
 	for workerPool.State.FailureCount <= workerPool.RetryThreshold {
+		// Stop verifying without registering a failure if the operation has
+		// been cancelled.
+		if ctx.Err() != nil {
+			return false
+		}
+
 		if workerPool.State.FailureCount > 0 {
 			logging.Info("cloud/aws: attempting to launch a new node in worker "+
 				"pool %v, previous node failures: %v", workerPool.Name,
@@ -199,14 +223,19 @@ func (sp *AwsScalingProvider) verifyScaledNode(workerPool *structs.WorkerPool,
 		}
 
 		// Identify the most recently launched instance in the worker pool.
-		instanceIP, err := getMostRecentInstance(workerPool.Name, workerPool.Region)
+		instanceIP, err := getMostRecentInstance(ctx, workerPool.Name,
+			workerPool.Region)
 		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+
 			logging.Error("cloud/aws: failed to identify the most recently "+
 				"launched instance in worker pool %v: %v", workerPool.Name, err)
 
 			// Increment the failure count and persist the state object.
 			workerPool.State.FailureCount++
-			if err = consulClient.PersistState(workerPool.State); err != nil {
+			if err = stateStore.PersistState(workerPool.State); err != nil {
 				logging.Error("cloud/aws: %v", err)
 			}
 			continue
@@ -214,28 +243,32 @@ func (sp *AwsScalingProvider) verifyScaledNode(workerPool *structs.WorkerPool,
 
 		// Verify the most recently launched instance has completed bootstrapping
 		// and successfully joined the worker pool.
-		if ok := helper.FindNodeByAddress(nodeRegistry, workerPool.Name,
+		if ok := helper.FindNodeByAddress(ctx, nodeRegistry, workerPool.Name,
 			instanceIP); ok {
 			// Reset node failure count once we have verified the new node is healthy.
 			workerPool.State.FailureCount = 0
 
 			// Update the last scaling event timestamp.
-			workerPool.State.LastScalingEvent = time.Now()
+			workerPool.State.RecordScalingEvent(structs.ScalingDirectionOut)
 
-			// Persist the state tracking object to Consul.
-			if err = consulClient.PersistState(workerPool.State); err != nil {
+			// Persist the state tracking object to the state store.
+			if err = stateStore.PersistState(workerPool.State); err != nil {
 				logging.Error("cloud/aws: %v", err)
 			}
 
 			return true
 		}
 
+		if ctx.Err() != nil {
+			return false
+		}
+
 		// The identified node did not successfully join the worker pool in a
 		// timely fashion, so we register a failure and start cleanup procedures.
 		workerPool.State.FailureCount++
 
-		// Persist the state tracking object to Consul.
-		if err = consulClient.PersistState(workerPool.State); err != nil {
+		// Persist the state tracking object to the state store.
+		if err = stateStore.PersistState(workerPool.State); err != nil {
 			logging.Error("cloud/aws: %v", err)
 		}
 
@@ -244,7 +277,7 @@ func (sp *AwsScalingProvider) verifyScaledNode(workerPool *structs.WorkerPool,
 			"actions", instanceIP, workerPool.Name, workerPool.State.FailureCount)
 
 		// Perform post-failure cleanup tasks.
-		if err = sp.failedEventCleanup(instanceIP, workerPool); err != nil {
+		if err = sp.failedEventCleanup(ctx, instanceIP, workerPool); err != nil {
 			logging.Error("cloud/aws: %v", err)
 		}
 	}
@@ -256,7 +289,7 @@ func (sp *AwsScalingProvider) verifyScaledNode(workerPool *structs.WorkerPool,
 // after a failed scaling event is detected. The node is detached and
 // terminated unless the retry threshold has been reached, in that case the
 // node is left in a detached state for troubleshooting.
-func (sp *AwsScalingProvider) failedEventCleanup(workerNode string,
+func (sp *AwsScalingProvider) failedEventCleanup(ctx context.Context, workerNode string,
 	workerPool *structs.WorkerPool) (err error) {
 
 	// Translate the IP address of the most recently launched node to
@@ -267,7 +300,7 @@ func (sp *AwsScalingProvider) failedEventCleanup(workerNode string,
 	// will detach the instance from the autoscaling group and decrement the
 	// autoscaling group desired count.
 	if workerPool.State.FailureCount == workerPool.RetryThreshold {
-		err := detachInstance(workerPool.Name, instanceID, sp.AsgService)
+		err := detachInstance(ctx, workerPool.Name, instanceID, sp.AsgService)
 		if err != nil {
 			return fmt.Errorf("an error occurred while attempting to detach the "+
 				"failed instance %v from worker pool %v: %v", instanceID,
@@ -278,7 +311,7 @@ func (sp *AwsScalingProvider) failedEventCleanup(workerNode string,
 
 	// Attempt to terminate the most recently launched instance to allow the
 	// autoscaling group a chance to launch a new one.
-	if err := terminateInstance(instanceID, workerPool.Region); err != nil {
+	if err := terminateInstance(ctx, instanceID, workerPool.Region); err != nil {
 		logging.Error("cloud/aws: an error occurred while attempting to "+
 			"terminate instance %v from worker pool %v: %v", instanceID,
 			workerPool.Name, err)
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -36,11 +37,17 @@ func translateIptoID(ip, region string) (id string) {
 		return
 	}
 
+	// The instance may have already been terminated and no longer be listed.
+	if len(resp.Reservations) == 0 || len(resp.Reservations[0].Instances) == 0 {
+		logging.Error("cloud/aws: no EC2 instance found with IP address %v", ip)
+		return
+	}
+
 	return *resp.Reservations[0].Instances[0].InstanceId
 }
 
 // terminateInstance terminates a specified EC2 instance and confirms success.
-func terminateInstance(instanceID, region string) error {
+func terminateInstance(ctx context.Context, instanceID, region string) error {
 	// Setup the session and the EC2 service link to use for this operation.
 	sess := session.Must(session.NewSession())
 	svc := ec2.New(sess, &aws.Config{Region: aws.String(region)})
@@ -71,6 +78,9 @@ func terminateInstance(instanceID, region string) error {
 
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
 		case <-timeOut.C:
 			return fmt.Errorf("timeout reached while attempting to confirm "+
 				"the termination of instance %v", instanceID)
@@ -0,0 +1,272 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Define the pseudo resources of requests which are not authorized by policy
+// rules. ACL management requests require a management token and requests for
+// the token of the caller require any valid token.
+const (
+	aclResourceManagement = "management"
+	aclResourceSelf       = "self"
+)
+
+// aclResources maps the paths of the HTTP API to the resource requests are
+// authorized against, matching the first listed prefix. Requests to the ACL
+// bootstrap endpoint and to paths which are not listed, such as the agent
+// health check, do not require a token.
+var aclResources = []struct {
+	prefix   string
+	resource string
+}{
+	{"/v1/acl/bootstrap", ""},
+	{"/v1/acl/token/self", aclResourceSelf},
+	{"/v1/acl/", aclResourceManagement},
+	{"/v1/agent/self", structs.ACLResourceOperator},
+	{"/v1/event/stream", structs.ACLResourceOperator},
+	{"/v1/failsafe", structs.ACLResourceFailsafe},
+	{"/v1/job", structs.ACLResourceJob},
+	{"/v1/metrics", structs.ACLResourceOperator},
+	{"/v1/pool", structs.ACLResourcePool},
+	{"/v1/recommendations", structs.ACLResourceJob},
+	{"/v1/status/", structs.ACLResourceOperator},
+}
+
+// aclRequirement returns the resource and capability required to perform the
+// request. An empty resource is returned if the request does not require a
+// token. GET requests require the read capability and all other requests the
+// write capability.
+func aclRequirement(req *http.Request) (resource, capability string) {
+	capability = structs.ACLCapabilityWrite
+	if req.Method == "GET" {
+		capability = structs.ACLCapabilityRead
+	}
+
+	// The history endpoint returns the history of either a worker pool or a
+	// job depending on the query.
+	if req.URL.Path == "/v1/history" {
+		if req.URL.Query().Get("pool") != "" {
+			return structs.ACLResourcePool, capability
+		}
+		return structs.ACLResourceJob, capability
+	}
+
+	for _, r := range aclResources {
+		if strings.HasPrefix(req.URL.Path, r.prefix) {
+			return r.resource, capability
+		}
+	}
+
+	return "", capability
+}
+
+// authorize checks the request is permitted by the ACL token presented in the
+// X-Replicator-Token header when ACLs are enabled. Requests without a token
+// are authorized by the anonymous policy.
+func (s *HTTPServer) authorize(req *http.Request) error {
+	if acl := s.agent.server.Config().ACL; acl == nil || !acl.Enabled {
+		return nil
+	}
+
+	resource, capability := aclRequirement(req)
+	if resource == "" {
+		return nil
+	}
+
+	secret := aclToken(req)
+
+	var out structs.ACLResolveResponse
+	args := structs.ACLResolveRequest{SecretID: secret}
+	if err := s.agent.RPC("ACL.Resolve", &args, &out); err != nil {
+		return err
+	}
+
+	if secret != "" && out.Token == nil {
+		return CodedError(403, "ACL token not found")
+	}
+
+	var allowed bool
+	switch resource {
+	case aclResourceManagement:
+		allowed = out.Token != nil && out.Token.Type == structs.ACLTokenManagement
+	case aclResourceSelf:
+		allowed = out.Token != nil
+	default:
+		allowed = out.Token.Allowed(out.Policies, resource, capability)
+	}
+
+	if !allowed {
+		return CodedError(403, "Permission denied")
+	}
+	return nil
+}
+
+// aclToken returns the secret ID of the ACL token presented with the request,
+// which is passed to the RPC endpoints so that the server performing the
+// request can authorize it.
+func aclToken(req *http.Request) string {
+	return req.Header.Get(structs.ACLTokenHeader)
+}
+
+// ACLBootstrapRequest is used to perform the ACL.Bootstrap API request,
+// returning the initial management token.
+func (s *HTTPServer) ACLBootstrapRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "POST" && req.Method != "PUT" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.ACLBootstrapResponse
+	if err := s.agent.RPC("ACL.Bootstrap", nil, &out); err != nil {
+		return nil, err
+	}
+
+	if out.Token == nil {
+		return nil, CodedError(400, "ACLs have already been bootstrapped")
+	}
+
+	return out.Token, nil
+}
+
+// ACLTokensRequest is used to perform the ACL.ListTokens API request.
+func (s *HTTPServer) ACLTokensRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.ACLTokenListResponse
+	args := structs.QueryOptions{AuthToken: aclToken(req)}
+	if err := s.agent.RPC("ACL.ListTokens", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Tokens, nil
+}
+
+// ACLTokenSpecificRequest is used to manage ACL tokens. A token is created by
+// a request to /v1/acl/token and read, updated or deleted at
+// /v1/acl/token/<accessor>. The token of the caller is returned by
+// /v1/acl/token/self.
+func (s *HTTPServer) ACLTokenSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	accessor := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, "/v1/acl/token"), "/")
+
+	if accessor == "self" {
+		if req.Method != "GET" {
+			return nil, CodedError(405, ErrInvalidMethod)
+		}
+
+		var out structs.ACLResolveResponse
+		args := structs.ACLResolveRequest{SecretID: aclToken(req)}
+		if err := s.agent.RPC("ACL.Resolve", &args, &out); err != nil {
+			return nil, err
+		}
+
+		if out.Token == nil {
+			return nil, CodedError(404, "ACL token not found")
+		}
+		return out.Token, nil
+	}
+
+	args := structs.ACLTokenRequest{AccessorID: accessor}
+	args.AuthToken = aclToken(req)
+	var out structs.ACLTokenResponse
+	var method string
+
+	switch req.Method {
+	case "GET":
+		method = "ACL.GetToken"
+	case "POST", "PUT":
+		method = "ACL.UpsertToken"
+		if err := decodeBody(req, &args.Token); err != nil {
+			return nil, CodedError(400, fmt.Sprintf("Failed to decode request: %v", err))
+		}
+		if args.Token == nil {
+			return nil, CodedError(400, "Must specify a token")
+		}
+		args.Token.AccessorID = accessor
+	case "DELETE":
+		method = "ACL.DeleteToken"
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if accessor == "" && method != "ACL.UpsertToken" {
+		return nil, CodedError(400, "Must specify a token accessor ID")
+	}
+
+	if err := s.agent.RPC(method, &args, &out); err != nil {
+		return nil, err
+	}
+
+	if out.Token == nil {
+		return nil, CodedError(404, "ACL token not found")
+	}
+
+	return out.Token, nil
+}
+
+// ACLPoliciesRequest is used to perform the ACL.ListPolicies API request.
+func (s *HTTPServer) ACLPoliciesRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.ACLPolicyListResponse
+	args := structs.QueryOptions{AuthToken: aclToken(req)}
+	if err := s.agent.RPC("ACL.ListPolicies", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Policies, nil
+}
+
+// ACLPolicySpecificRequest is used to read, create, update or delete the ACL
+// policy referenced by the request path /v1/acl/policy/<name>.
+func (s *HTTPServer) ACLPolicySpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	args := structs.ACLPolicyRequest{
+		Name: strings.TrimPrefix(req.URL.Path, "/v1/acl/policy/"),
+	}
+
+	if args.Name == "" {
+		return nil, CodedError(400, "Must specify a policy name")
+	}
+	args.AuthToken = aclToken(req)
+
+	var out structs.ACLPolicyResponse
+	var method string
+
+	switch req.Method {
+	case "GET":
+		method = "ACL.GetPolicy"
+	case "POST", "PUT":
+		method = "ACL.UpsertPolicy"
+		if err := decodeBody(req, &args.Policy); err != nil {
+			return nil, CodedError(400, fmt.Sprintf("Failed to decode request: %v", err))
+		}
+		if args.Policy == nil {
+			return nil, CodedError(400, "Must specify a policy")
+		}
+		args.Policy.Name = args.Name
+		if err := args.Policy.Validate(); err != nil {
+			return nil, CodedError(400, err.Error())
+		}
+	case "DELETE":
+		method = "ACL.DeletePolicy"
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if err := s.agent.RPC(method, &args, &out); err != nil {
+		return nil, err
+	}
+
+	if out.Policy == nil {
+		return nil, CodedError(404, "ACL policy not found")
+	}
+
+	return out.Policy, nil
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestHTTP_aclRequirement(t *testing.T) {
+	cases := []struct {
+		method     string
+		url        string
+		resource   string
+		capability string
+	}{
+		{"GET", "/v1/agent/health", "", structs.ACLCapabilityRead},
+		{"PUT", "/v1/acl/bootstrap", "", structs.ACLCapabilityWrite},
+		{"GET", "/v1/acl/token/self", aclResourceSelf, structs.ACLCapabilityRead},
+		{"GET", "/v1/acl/tokens", aclResourceManagement, structs.ACLCapabilityRead},
+		{"GET", "/v1/jobs", structs.ACLResourceJob, structs.ACLCapabilityRead},
+		{"POST", "/v1/job/example/cache/scale", structs.ACLResourceJob, structs.ACLCapabilityWrite},
+		{"POST", "/v1/pool/example/scale", structs.ACLResourcePool, structs.ACLCapabilityWrite},
+		{"PUT", "/v1/failsafe/pool/example", structs.ACLResourceFailsafe, structs.ACLCapabilityWrite},
+		{"GET", "/v1/history?pool=example", structs.ACLResourcePool, structs.ACLCapabilityRead},
+		{"GET", "/v1/history?job=example", structs.ACLResourceJob, structs.ACLCapabilityRead},
+		{"GET", "/v1/metrics?format=prometheus", structs.ACLResourceOperator, structs.ACLCapabilityRead},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.url, nil)
+		resource, capability := aclRequirement(req)
+		if resource != c.resource || capability != c.capability {
+			t.Fatalf("expected %v %v to require %v %v but got %v %v", c.method,
+				c.url, c.capability, c.resource, capability, resource)
+		}
+	}
+}
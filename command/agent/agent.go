@@ -5,7 +5,9 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"reflect"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -27,6 +29,11 @@ type Command struct {
 	httpServer   *HTTPServer
 	server       *replicator.Server
 	shutdownChan chan struct{}
+
+	// prometheusSink is the sink used to serve metrics in the Prometheus
+	// format, which is nil unless enabled in the telemetry configuration.
+	prometheusSink *PrometheusSink
+	sinkLock       sync.RWMutex
 }
 
 // Run triggers a run of the replicator agent by setting up and parsing the
@@ -97,35 +104,11 @@ func (c *Command) Run(args []string) int {
 
 			case syscall.SIGHUP:
 				logging.Info("command/agent: caught signal %v", s)
-				server.Shutdown()
-				c.httpServer.Shutdown()
-
-				// Reload the configuration in order to make proper use of SIGHUP.
-				config := c.parseFlags()
-				if err != nil {
-					return 1
-				}
-
-				err := c.initialzeAgent(config)
-				if err != nil {
-					logging.Error("command/agent: unable to initialize agent: %v", err)
-					return 1
-				}
-
-				server, err := replicator.NewServer(conf)
-				if err != nil {
-					return 1
-				}
 
-				c.server = server
-
-				http, err := NewHTTPServer(c, conf)
-				if err != nil {
-					logging.Error("command/agent: unable to start HTTP server: %s", err)
-					return 1
+				if err := c.reload(); err != nil {
+					logging.Error("command/agent: unable to reload configuration, "+
+						"the running configuration has been retained: %v", err)
 				}
-
-				c.httpServer = http
 			}
 		}
 	}
@@ -139,6 +122,7 @@ func (c *Command) parseFlags() *structs.Config {
 	// An empty new config is setup here to allow us to fill this with any passed
 	// cli flags for later merging.
 	cliConfig := &structs.Config{
+		ACL:          &structs.ACL{},
 		Telemetry:    &structs.Telemetry{},
 		Notification: &structs.Notification{},
 	}
@@ -150,6 +134,7 @@ func (c *Command) parseFlags() *structs.Config {
 	flags.BoolVar(&dev, "dev", false, "")
 
 	// Top level configuration flags
+	flags.BoolVar(&cliConfig.ACL.Enabled, "acl-enabled", false, "")
 	flags.StringVar(&cliConfig.BindAddress, "bind-address", "", "")
 	flags.StringVar(&cliConfig.Consul, "consul", "", "")
 	flags.StringVar(&cliConfig.ConsulKeyRoot, "consul-key-root", "", "")
@@ -167,6 +152,7 @@ func (c *Command) parseFlags() *structs.Config {
 	flags.IntVar(&cliConfig.RPCPort, "rpc-port", 0, "")
 
 	// Telemetry configuration flags
+	flags.BoolVar(&cliConfig.Telemetry.PrometheusMetrics, "prometheus-metrics", false, "")
 	flags.StringVar(&cliConfig.Telemetry.StatsdAddress, "statsd-address", "", "")
 
 	// Notification configuration flags
@@ -230,6 +216,19 @@ func (c *Command) setupTelemetry(config *structs.Telemetry) error {
 		fanout = append(fanout, sink)
 	}
 
+	// Configure the Prometheus sink. An existing sink is retained when the
+	// configuration is reloaded so that counters are not reset.
+	c.sinkLock.Lock()
+	if telemetry.PrometheusMetrics {
+		if c.prometheusSink == nil {
+			c.prometheusSink = NewPrometheusSink(metricsConf.HostName)
+		}
+		fanout = append(fanout, c.prometheusSink)
+	} else {
+		c.prometheusSink = nil
+	}
+	c.sinkLock.Unlock()
+
 	// Initialize the global sink
 	if len(fanout) > 0 {
 		fanout = append(fanout, inm)
@@ -241,6 +240,14 @@ func (c *Command) setupTelemetry(config *structs.Telemetry) error {
 	return nil
 }
 
+// prometheus returns the Prometheus sink, or nil if Prometheus metrics are
+// disabled.
+func (c *Command) prometheus() *PrometheusSink {
+	c.sinkLock.RLock()
+	defer c.sinkLock.RUnlock()
+	return c.prometheusSink
+}
+
 // setupNotifier is used to setup Replicators notifier provider.
 func (c *Command) setupNotifier(config *structs.Notification) (err error) {
 
@@ -302,9 +309,80 @@ func (c *Command) initialzeAgent(config *structs.Config) (err error) {
 	return nil
 }
 
+// reload re-parses the configuration and applies it to the running agent
+// without restarting the server. Logging, telemetry and notifiers are
+// reconfigured in place, the HTTP server is only restarted if its port has
+// changed and the remaining settings are passed to the server to apply.
+func (c *Command) reload() error {
+	newConf := c.parseFlags()
+	if newConf == nil {
+		return fmt.Errorf("unable to parse the configuration")
+	}
+
+	if err := logging.ValidateLevel(newConf.LogLevel); err != nil {
+		return fmt.Errorf("invalid log level %q: %v", newConf.LogLevel, err)
+	}
+
+	config := c.server.Config()
+
+	// Notifiers are only rebuilt if their configuration has changed.
+	if notificationChanged(config.Notification, newConf.Notification) {
+		newConf.Notification.Notifiers = nil
+		if err := c.setupNotifier(newConf.Notification); err != nil {
+			return fmt.Errorf("unable to setup notifiers: %v", err)
+		}
+		logging.Info("command/agent: notifiers have been reconfigured")
+	} else {
+		newConf.Notification = config.Notification
+	}
+
+	if !reflect.DeepEqual(config.Telemetry, newConf.Telemetry) {
+		if err := c.setupTelemetry(newConf.Telemetry); err != nil {
+			return fmt.Errorf("unable to setup telemetry: %v", err)
+		}
+		logging.Info("command/agent: telemetry has been reconfigured")
+	}
+
+	if config.LogLevel != newConf.LogLevel {
+		logging.SetLevel(newConf.LogLevel)
+		logging.Info("command/agent: log level changed to %v", newConf.LogLevel)
+	}
+
+	if err := c.server.Reload(newConf); err != nil {
+		return err
+	}
+
+	if config.HTTPPort != newConf.HTTPPort {
+		logging.Info("command/agent: restarting the HTTP server on port %v",
+			newConf.HTTPPort)
+
+		c.httpServer.Shutdown()
+
+		http, err := NewHTTPServer(c, c.server.Config())
+		if err != nil {
+			return fmt.Errorf("unable to start HTTP server: %v", err)
+		}
+		c.httpServer = http
+	}
+
+	return nil
+}
+
+// notificationChanged reports whether the notifier settings differ between
+// the notification configurations.
+func notificationChanged(old, updated *structs.Notification) bool {
+	if old == nil || updated == nil {
+		return old != updated
+	}
+
+	return old.ClusterIdentifier != updated.ClusterIdentifier ||
+		old.OpsGenieAPIKey != updated.OpsGenieAPIKey ||
+		old.PagerDutyServiceKey != updated.PagerDutyServiceKey
+}
+
 // RPC is used to make an RPC call to Replicator.
-func (c *Command) RPC(method string, reply interface{}) error {
-	return c.server.RPC(method, reply)
+func (c *Command) RPC(method string, args interface{}, reply interface{}) error {
+	return c.server.RPC(method, args, reply)
 }
 
 // Help provides the help information for the agent command.
@@ -319,6 +397,11 @@ func (c *Command) Help() string {
 
   General Options:
 
+    -acl-enabled
+      Requires requests to the HTTP API to present an ACL token with the
+      X-Replicator-Token header. The initial management token is created
+      with a request to /v1/acl/bootstrap.
+
     -bind-address=<addr>
       Specifies which address the Replicator agent will bind to for HTTP API and
       RPC network  services.
@@ -382,6 +465,10 @@ func (c *Command) Help() string {
 
   Telemetry Options:
 
+    -prometheus-metrics
+      Enables the Prometheus metrics sink, which exposes metrics in the
+      Prometheus text format at /v1/metrics?format=prometheus.
+
     -statsd-address=<address:port>
       Specifies the address of a statsd server to forward metrics
       to and should include the port.
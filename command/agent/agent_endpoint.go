@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	"github.com/ugorji/go/codec"
+)
+
+// AgentHealthRequest is used to perform the Agent.Health API request. A 503
+// status code is returned if any health check is critical so that service
+// checks and load balancers can detect an unhealthy agent.
+func (s *HTTPServer) AgentHealthRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.AgentHealthResponse
+	if err := s.agent.RPC("Agent.Health", nil, &out); err != nil {
+		return nil, err
+	}
+
+	if out.Status != structs.HealthCritical {
+		return out, nil
+	}
+
+	var buf bytes.Buffer
+	if err := codec.NewEncoder(&buf, JSONHandle).Encode(out); err != nil {
+		return nil, err
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusServiceUnavailable)
+	resp.Write(buf.Bytes())
+	return nil, nil
+}
+
+// AgentSelfRequest is used to perform the Agent.Self API request.
+func (s *HTTPServer) AgentSelfRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.AgentSelfResponse
+	args := structs.QueryOptions{AuthToken: aclToken(req)}
+	if err := s.agent.RPC("Agent.Self", &args, &out); err != nil {
+		return nil, err
+	}
+
+	out.HTTPAddr = s.Addr
+	return out, nil
+}
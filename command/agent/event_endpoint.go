@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// eventHeartbeatInterval is the interval at which heartbeats are written to
+// idle event streams so that clients and proxies keep the connection open.
+const eventHeartbeatInterval = 30 * time.Second
+
+// EventStreamRequest streams events as they are published by the leader.
+// Events are written as newline delimited JSON unless the client accepts
+// server-sent events. The events can be filtered with one or more topic
+// parameters in the form topic or topic:key, for example topic=pool:default
+// or topic=job:example. Agents which are not the leader relay the event
+// stream of the leader, ending the stream if leadership changes.
+func (s *HTTPServer) EventStreamRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var topics []structs.EventTopic
+	for _, filter := range req.URL.Query()["topic"] {
+		topic, err := structs.ParseEventTopic(filter)
+		if err != nil {
+			return nil, CodedError(400, err.Error())
+		}
+		topics = append(topics, topic)
+	}
+
+	flusher, ok := resp.(http.Flusher)
+	if !ok {
+		return nil, CodedError(500, "Streaming is not supported")
+	}
+
+	stream := &eventStream{
+		flusher: flusher,
+		resp:    resp,
+		sse:     strings.Contains(req.Header.Get("Accept"), "text/event-stream"),
+	}
+
+	if !s.agent.server.IsLeader() {
+		return nil, s.relayEvents(req, stream, topics)
+	}
+
+	broker := s.agent.server.EventBroker()
+	sub := broker.Subscribe(topics)
+	defer broker.Unsubscribe(sub)
+
+	stream.start()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return nil, nil
+
+		case <-heartbeat.C:
+			stream.heartbeat()
+
+		case event, ok := <-sub.Events():
+			// The subscription is closed if the client falls too far behind,
+			// the client is expected to reconnect.
+			if !ok {
+				return nil, nil
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				logging.Error("command/http: unable to encode event %v: %v",
+					event.Index, err)
+				continue
+			}
+
+			stream.write(event.Index, event.Type, data)
+		}
+	}
+}
+
+// relayEvents streams the events published by the leader, requesting the
+// events published since the previous request until the client disconnects.
+// A heartbeat is written whenever a request returns no events. Requests are
+// forwarded to the leader over the pooled connections of the server, so each
+// poll reuses the same leader connection rather than connecting again. The
+// stream is ended if the leader cannot be reached or its event stream changes,
+// the client is expected to reconnect.
+func (s *HTTPServer) relayEvents(req *http.Request, stream *eventStream,
+	topics []structs.EventTopic) error {
+
+	args := structs.EventListRequest{
+		Topics:       topics,
+		MaxQueryTime: eventHeartbeatInterval,
+	}
+	args.AuthToken = aclToken(req)
+
+	// Determine the current position of the event stream of the leader before
+	// responding so that an unavailable leader is reported to the client.
+	var out structs.EventListResponse
+	if err := s.agent.RPC("Event.List", &args, &out); err != nil {
+		return err
+	}
+	args.StreamID, args.MinQueryIndex = out.StreamID, out.Index
+
+	stream.start()
+
+	for req.Context().Err() == nil {
+		var out structs.EventListResponse
+		if err := s.agent.RPC("Event.List", &args, &out); err != nil {
+			logging.Warning("command/http: ending the relayed event stream: %v", err)
+			return nil
+		}
+		args.MinQueryIndex = out.Index
+
+		if len(out.Events) == 0 {
+			stream.heartbeat()
+			continue
+		}
+
+		for _, data := range out.Events {
+			var event struct {
+				Index uint64 `json:"index"`
+				Type  string `json:"type"`
+			}
+			if err := json.Unmarshal(data, &event); err != nil {
+				logging.Error("command/http: unable to decode relayed event: %v", err)
+				continue
+			}
+
+			stream.write(event.Index, event.Type, data)
+		}
+	}
+
+	return nil
+}
+
+// eventStream writes events to the client of the event stream, either as
+// newline delimited JSON or as server-sent events.
+type eventStream struct {
+	flusher http.Flusher
+	resp    http.ResponseWriter
+	sse     bool
+}
+
+// start writes the response headers of the event stream.
+func (e *eventStream) start() {
+	if e.sse {
+		e.resp.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		e.resp.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	e.resp.Header().Set("Cache-Control", "no-cache")
+	e.resp.WriteHeader(http.StatusOK)
+	e.flusher.Flush()
+}
+
+// heartbeat writes a heartbeat so that clients and proxies keep an idle
+// connection open.
+func (e *eventStream) heartbeat() {
+	if e.sse {
+		e.resp.Write([]byte(": heartbeat\n\n"))
+	} else {
+		e.resp.Write([]byte("{}\n"))
+	}
+	e.flusher.Flush()
+}
+
+// write writes the JSON encoded event.
+func (e *eventStream) write(index uint64, eventType string, data []byte) {
+	if e.sse {
+		fmt.Fprintf(e.resp, "id: %d\nevent: %s\ndata: %s\n\n", index, eventType, data)
+	} else {
+		fmt.Fprintf(e.resp, "%s\n", data)
+	}
+	e.flusher.Flush()
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/replicator"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// FailsafeRequest is used to perform the Failsafe.List API request.
+func (s *HTTPServer) FailsafeRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.FailsafeListResponse
+	args := structs.QueryOptions{AuthToken: aclToken(req)}
+	if err := s.agent.RPC("Failsafe.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Resources, nil
+}
+
+// FailsafeSpecificRequest is used to perform the Failsafe.Set API request
+// for the resource referenced by the request path, either
+// /v1/failsafe/pool/<name> or /v1/failsafe/job/<job>/<group>. A PUT request
+// enables failsafe mode and a DELETE request disables it.
+func (s *HTTPServer) FailsafeSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.FailsafeRequest
+
+	switch req.Method {
+	case "PUT":
+		args.Enable = true
+	case "DELETE":
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/v1/failsafe/"), "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, CodedError(400, "Must specify a resource type and name")
+	}
+
+	switch parts[0] {
+	case "pool":
+		args.ResourceType = replicator.ClusterType
+	case "job":
+		args.ResourceType = replicator.JobType
+	default:
+		return nil, CodedError(400, "Resource type must be either pool or job")
+	}
+	args.ResourceID = parts[1]
+
+	args.Actor = s.requestActor(req)
+	args.AuthToken = aclToken(req)
+
+	var out structs.FailsafeResponse
+	if err := s.agent.RPC("Failsafe.Set", &args, &out); err != nil {
+		return nil, err
+	}
+
+	if out.Status == nil {
+		return nil, CodedError(404, "No state was found for the resource")
+	}
+
+	return out.Status, nil
+}
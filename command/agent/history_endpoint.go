@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"net/http"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// HistoryRequest is used to perform the History.List API request. The
+// history of a worker pool is returned using the pool query parameter, or of
+// a job using the job and optional group query parameters.
+func (s *HTTPServer) HistoryRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	query := req.URL.Query()
+	args := structs.HistoryRequest{
+		Group: query.Get("group"),
+		Job:   query.Get("job"),
+		Pool:  query.Get("pool"),
+	}
+	args.AuthToken = aclToken(req)
+
+	if args.Pool == "" && args.Job == "" {
+		return nil, CodedError(400, "Must specify either the pool or job query parameter")
+	}
+
+	var out structs.HistoryListResponse
+	if err := s.agent.RPC("History.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Events, nil
+}
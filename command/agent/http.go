@@ -2,11 +2,14 @@ package agent
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/NYTimes/gziphandler"
@@ -18,6 +21,11 @@ import (
 const (
 	// ErrInvalidMethod is used if the HTTP method is not supported
 	ErrInvalidMethod = "Invalid method"
+
+	// defaultQueryWait is the time a blocking query waits when the client
+	// does not specify a wait time, and maxQueryWait the longest permitted.
+	defaultQueryWait = 5 * time.Minute
+	maxQueryWait     = 10 * time.Minute
 )
 
 var (
@@ -86,6 +94,18 @@ func NewHTTPServer(agent *Command, config *structs.Config) (*HTTPServer, error)
 		return nil, fmt.Errorf("failed to start HTTP listener: %v", err)
 	}
 
+	// Serve the API over TLS when enabled, using the certificates loaded by
+	// the server so they are reloaded along with the RPC listener.
+	if config.TLS != nil && config.TLS.EnableHTTP {
+		configurator := agent.server.TLSConfigurator()
+		if configurator == nil {
+			ln.Close()
+			return nil, fmt.Errorf("failed to start HTTP listener: TLS " +
+				"certificates have not been loaded")
+		}
+		ln = tls.NewListener(ln, configurator.IncomingConfig())
+	}
+
 	// Create the mux
 	mux := http.NewServeMux()
 
@@ -120,6 +140,24 @@ func (s *HTTPServer) Shutdown() {
 
 // registerHandlers is used to attach our handlers.
 func (s *HTTPServer) registerHandlers() {
+	s.mux.HandleFunc("/v1/acl/bootstrap", s.wrap(s.ACLBootstrapRequest))
+	s.mux.HandleFunc("/v1/acl/policies", s.wrap(s.ACLPoliciesRequest))
+	s.mux.HandleFunc("/v1/acl/policy/", s.wrap(s.ACLPolicySpecificRequest))
+	s.mux.HandleFunc("/v1/acl/token", s.wrap(s.ACLTokenSpecificRequest))
+	s.mux.HandleFunc("/v1/acl/token/", s.wrap(s.ACLTokenSpecificRequest))
+	s.mux.HandleFunc("/v1/acl/tokens", s.wrap(s.ACLTokensRequest))
+	s.mux.HandleFunc("/v1/agent/health", s.wrap(s.AgentHealthRequest))
+	s.mux.HandleFunc("/v1/agent/self", s.wrap(s.AgentSelfRequest))
+	s.mux.HandleFunc("/v1/event/stream", s.wrap(s.EventStreamRequest))
+	s.mux.HandleFunc("/v1/failsafe", s.wrap(s.FailsafeRequest))
+	s.mux.HandleFunc("/v1/failsafe/", s.wrap(s.FailsafeSpecificRequest))
+	s.mux.HandleFunc("/v1/history", s.wrap(s.HistoryRequest))
+	s.mux.HandleFunc("/v1/job/", s.wrap(s.JobSpecificRequest))
+	s.mux.HandleFunc("/v1/jobs", s.wrap(s.JobsRequest))
+	s.mux.HandleFunc("/v1/metrics", s.wrap(s.MetricsRequest))
+	s.mux.HandleFunc("/v1/pool/", s.wrap(s.PoolSpecificRequest))
+	s.mux.HandleFunc("/v1/pools", s.wrap(s.PoolsRequest))
+	s.mux.HandleFunc("/v1/recommendations", s.wrap(s.RecommendationsRequest))
 	s.mux.HandleFunc("/v1/status/leader", s.wrap(s.StatusLeaderRequest))
 }
 
@@ -132,7 +170,17 @@ func (s *HTTPServer) wrap(handler func(resp http.ResponseWriter, req *http.Reque
 		defer func() {
 			logging.Debug("command/http: request %v %v (%v)", req.Method, reqURL, time.Now().Sub(start))
 		}()
-		obj, err := handler(resp, req)
+
+		// Check the request is authorized by its ACL token before invoking the
+		// handler.
+		var obj interface{}
+		err := s.authorize(req)
+		if err == nil && req.Method == "GET" && queryIndexed(req) {
+			err = s.blockingQuery(resp, req)
+		}
+		if err == nil {
+			obj, err = handler(resp, req)
+		}
 
 		// Check for an error
 	HAS_ERR:
@@ -141,6 +189,8 @@ func (s *HTTPServer) wrap(handler func(resp http.ResponseWriter, req *http.Reque
 			code := 500
 			if http, ok := err.(HTTPCodedError); ok {
 				code = http.Code()
+			} else if isACLError(err) {
+				code = 403
 			}
 			resp.WriteHeader(code)
 			resp.Write([]byte(err.Error()))
@@ -163,3 +213,148 @@ func (s *HTTPServer) wrap(handler func(resp http.ResponseWriter, req *http.Reque
 	}
 	return f
 }
+
+// blockingQuery waits for the leader to publish an event relating to the
+// resource queried with an index greater than the index query parameter, for
+// up to the duration of the wait query parameter, then sets the index of the
+// most recent such event in the X-Replicator-Index response header. The index
+// is issued by the leader so that it is shared by every agent. Requests
+// without an index parameter do not block.
+func (s *HTTPServer) blockingQuery(resp http.ResponseWriter, req *http.Request) error {
+	query := req.URL.Query()
+	args := structs.EventIndexRequest{Topics: queryTopics(req)}
+	args.AuthToken = aclToken(req)
+
+	raw := query.Get("index")
+	if raw != "" {
+		index, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return CodedError(400, fmt.Sprintf("Invalid index %q", raw))
+		}
+
+		wait := defaultQueryWait
+		if raw := query.Get("wait"); raw != "" {
+			if wait, err = time.ParseDuration(raw); err != nil || wait < 0 {
+				return CodedError(400, fmt.Sprintf("Invalid wait time %q", raw))
+			}
+		}
+		if wait > maxQueryWait {
+			wait = maxQueryWait
+		}
+
+		args.MinQueryIndex = index
+		args.MaxQueryTime = wait
+	}
+
+	var out structs.EventIndexResponse
+	if err := s.agent.RPC("Event.Index", &args, &out); err != nil {
+		// Queries which do not block are still answered without an index
+		// while no leader is available.
+		if raw == "" {
+			logging.Debug("command/http: unable to determine the query index: %v", err)
+			return nil
+		}
+		return err
+	}
+
+	resp.Header().Set(structs.QueryIndexHeader, strconv.FormatUint(out.Index, 10))
+	return nil
+}
+
+// queryIndexed reports whether the index of the leader is returned with the
+// response to the request, which requires a request to the leader. Only
+// blocking queries and queries of a resource with an index are indexed. The
+// agent, metrics and event stream endpoints are never indexed so that health
+// checks and metrics scrapes are answered locally even without a leader.
+func queryIndexed(req *http.Request) bool {
+	for _, prefix := range []string{"/v1/agent/", "/v1/event/stream", "/v1/metrics"} {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+
+	return req.URL.Query().Get("index") != "" || queryTopics(req) != nil
+}
+
+// queryTopics returns the event stream topics of the resource queried by the
+// request. Every event is included for requests which do not relate to a
+// worker pool, job or leadership.
+func queryTopics(req *http.Request) []structs.EventTopic {
+	path := req.URL.Path
+	query := req.URL.Query()
+
+	pools := structs.EventTopic{Topic: structs.TopicPool}
+	jobs := structs.EventTopic{Topic: structs.TopicJob}
+
+	switch {
+	case strings.HasPrefix(path, "/v1/pool/"):
+		pools.Key = strings.TrimPrefix(path, "/v1/pool/")
+		return []structs.EventTopic{pools}
+
+	case path == "/v1/pools":
+		return []structs.EventTopic{pools}
+
+	case strings.HasPrefix(path, "/v1/job/"):
+		jobs.Key = strings.TrimPrefix(path, "/v1/job/")
+		return []structs.EventTopic{jobs}
+
+	case path == "/v1/jobs":
+		return []structs.EventTopic{jobs}
+
+	case path == "/v1/history":
+		if pool := query.Get("pool"); pool != "" {
+			pools.Key = pool
+			return []structs.EventTopic{pools}
+		}
+
+		jobs.Key = query.Get("job")
+		if group := query.Get("group"); group != "" {
+			jobs.Key += "/" + group
+		}
+		return []structs.EventTopic{jobs}
+
+	case strings.HasPrefix(path, "/v1/failsafe"):
+		return []structs.EventTopic{pools, jobs}
+
+	case path == "/v1/status/leader":
+		return []structs.EventTopic{{Topic: structs.TopicLeader}}
+	}
+
+	return nil
+}
+
+// isACLError indicates whether the error was returned by an RPC endpoint
+// because the request was not authorized by its ACL token. Errors returned by
+// a forwarded request only retain their message.
+func isACLError(err error) bool {
+	switch err.Error() {
+	case structs.ErrPermissionDenied.Error(), structs.ErrTokenNotFound.Error():
+		return true
+	}
+	return false
+}
+
+// decodeBody is used to decode a JSON request body.
+func decodeBody(req *http.Request, out interface{}) error {
+	dec := json.NewDecoder(req.Body)
+	return dec.Decode(out)
+}
+
+// requestActor identifies the client making a request so that it can be
+// recorded against administrative changes. When ACLs are enabled the client
+// is identified by its ACL token, otherwise by its address.
+func (s *HTTPServer) requestActor(req *http.Request) string {
+	if acl := s.agent.server.Config().ACL; acl != nil && acl.Enabled {
+		var out structs.ACLResolveResponse
+		args := structs.ACLResolveRequest{SecretID: aclToken(req)}
+		if err := s.agent.RPC("ACL.Resolve", &args, &out); err == nil {
+			return out.Token.Actor()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return "api:" + host
+}
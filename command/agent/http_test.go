@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTP_queryIndexed(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected bool
+	}{
+		{"/v1/agent/health", false},
+		{"/v1/agent/health?index=10", false},
+		{"/v1/agent/self", false},
+		{"/v1/event/stream?topic=pool", false},
+		{"/v1/metrics?format=prometheus", false},
+		{"/v1/acl/tokens", false},
+		{"/v1/acl/tokens?index=10", true},
+		{"/v1/history?pool=default", true},
+		{"/v1/job/example", true},
+		{"/v1/pools", true},
+		{"/v1/status/leader", true},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", c.url, nil)
+		if indexed := queryIndexed(req); indexed != c.expected {
+			t.Fatalf("expected %v to be indexed %v but got %v", c.url, c.expected,
+				indexed)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// JobsRequest is used to perform the Jobs.List API request.
+func (s *HTTPServer) JobsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.JobListResponse
+	args := structs.QueryOptions{AuthToken: aclToken(req)}
+	if err := s.agent.RPC("Jobs.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Groups, nil
+}
+
+// JobSpecificRequest is used to perform the Jobs.Get API request for the job
+// referenced by the request path, or a scaling request for a group of the
+// job at /v1/job/<id>/<group>/scale.
+func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/job/")
+	if strings.HasSuffix(path, "/scale") {
+		parts := strings.Split(strings.TrimSuffix(path, "/scale"), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, CodedError(400, "Must specify a job ID and group")
+		}
+		return s.jobScaleRequest(resp, req, parts[0], parts[1])
+	}
+
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.JobRequest{
+		JobID: path,
+	}
+	args.AuthToken = aclToken(req)
+
+	if args.JobID == "" {
+		return nil, CodedError(400, "Must specify a job ID")
+	}
+
+	var out structs.JobListResponse
+	if err := s.agent.RPC("Jobs.Get", &args, &out); err != nil {
+		return nil, err
+	}
+
+	if len(out.Groups) == 0 {
+		return nil, CodedError(404, "Job not found or has no scaling policy")
+	}
+
+	return out.Groups, nil
+}
+
+// jobScaleRequest is used to perform the Scaling.Job API request. The
+// request body is a ScaleRequest specifying either the Count or Direction.
+func (s *HTTPServer) jobScaleRequest(resp http.ResponseWriter, req *http.Request,
+	jobID, group string) (interface{}, error) {
+
+	if req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var args structs.ScaleRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, fmt.Sprintf("Failed to decode request: %v", err))
+	}
+
+	args.Actor = s.requestActor(req)
+	args.AuthToken = aclToken(req)
+	args.Group = group
+	args.JobID = jobID
+
+	var out structs.ScaleResponse
+	if err := s.agent.RPC("Scaling.Job", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Events, nil
+}
@@ -0,0 +1,27 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// MetricsRequest is used to expose the agent metrics. Only the Prometheus
+// text format is currently supported and must be requested with
+// ?format=prometheus.
+func (s *HTTPServer) MetricsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if format := req.URL.Query().Get("format"); format != "prometheus" {
+		return nil, CodedError(400, fmt.Sprintf("Unsupported metrics format %q", format))
+	}
+
+	sink := s.agent.prometheus()
+	if sink == nil {
+		return nil, CodedError(404, "Prometheus metrics are not enabled")
+	}
+
+	resp.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	return nil, sink.Write(resp)
+}
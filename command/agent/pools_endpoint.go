@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// PoolsRequest is used to perform the Pools.List API request.
+func (s *HTTPServer) PoolsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.PoolListResponse
+	args := structs.QueryOptions{AuthToken: aclToken(req)}
+	if err := s.agent.RPC("Pools.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Pools, nil
+}
+
+// PoolSpecificRequest is used to perform the Pools.Get API request for the
+// worker pool referenced by the request path, or a scaling request for the
+// worker pool at /v1/pool/<name>/scale.
+func (s *HTTPServer) PoolSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/pool/")
+	if strings.HasSuffix(path, "/scale") {
+		name := strings.TrimSuffix(path, "/scale")
+		if name == "" || strings.Contains(name, "/") {
+			return nil, CodedError(400, "Must specify a worker pool name")
+		}
+		return s.poolScaleRequest(resp, req, name)
+	}
+
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	args := structs.PoolRequest{
+		Name: path,
+	}
+	args.AuthToken = aclToken(req)
+
+	if args.Name == "" {
+		return nil, CodedError(400, "Must specify a worker pool name")
+	}
+
+	var out structs.PoolListResponse
+	if err := s.agent.RPC("Pools.Get", &args, &out); err != nil {
+		return nil, err
+	}
+
+	if len(out.Pools) == 0 {
+		return nil, CodedError(404, "Worker pool not found")
+	}
+
+	return out.Pools[0], nil
+}
+
+// poolScaleRequest is used to perform the Scaling.Pool API request. The
+// request body is a ScaleRequest specifying either the Count or Direction.
+func (s *HTTPServer) poolScaleRequest(resp http.ResponseWriter, req *http.Request,
+	name string) (interface{}, error) {
+
+	if req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var args structs.ScaleRequest
+	if err := decodeBody(req, &args); err != nil {
+		return nil, CodedError(400, fmt.Sprintf("Failed to decode request: %v", err))
+	}
+
+	args.Actor = s.requestActor(req)
+	args.AuthToken = aclToken(req)
+	args.Pool = name
+
+	var out structs.ScaleResponse
+	if err := s.agent.RPC("Scaling.Pool", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Events, nil
+}
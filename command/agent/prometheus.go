@@ -0,0 +1,241 @@
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// prometheusNamespace is the prefix of every metric exposed by the
+// Prometheus sink.
+const prometheusNamespace = "replicator"
+
+// Define the Prometheus metric types used by the sink.
+const (
+	prometheusCounter = "counter"
+	prometheusGauge   = "gauge"
+	prometheusSummary = "summary"
+)
+
+// prometheusInvalidChars matches the characters which are not permitted in a
+// Prometheus metric name.
+var prometheusInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// prometheusRule translates a metric key into a metric name with labels. Each
+// element of the pattern is either a literal which must match the key, a
+// label name enclosed in braces which captures the element of the key as the
+// value of the label, or * which appends the element of the key to the
+// metric name.
+type prometheusRule struct {
+	pattern []string
+	name    string
+}
+
+// prometheusRules are the translation rules for each metric type. Keys which
+// do not match a rule are exposed without labels.
+var prometheusRules = map[string][]prometheusRule{
+	prometheusCounter: {
+		{[]string{"cluster", "{pool}", "{operation}", "{outcome}"}, "cluster_scaling"},
+		{[]string{"job", "{job}", "{group}", "{operation}", "{outcome}"}, "job_scaling"},
+	},
+	prometheusGauge: {
+		{[]string{"pool", "{pool}", "*"}, "pool"},
+		{[]string{"job", "{job}", "{group}", "*"}, "job_group"},
+	},
+}
+
+// prometheusSeries is a single time series retained by the sink.
+type prometheusSeries struct {
+	name   string
+	labels [][2]string
+	kind   string
+	value  float64
+	count  uint64
+}
+
+// PrometheusSink is a metrics sink which retains the current value of every
+// metric so it can be exposed in the Prometheus text format. The metric keys
+// emitted by Replicator embed the names of worker pools and job groups, so
+// these are translated into labels.
+type PrometheusSink struct {
+	hostName string
+
+	lock   sync.Mutex
+	series map[string]*prometheusSeries
+}
+
+// NewPrometheusSink returns a new Prometheus sink. The host name is removed
+// from gauge keys when it has been inserted by the metrics library.
+func NewPrometheusSink(hostName string) *PrometheusSink {
+	return &PrometheusSink{
+		hostName: hostName,
+		series:   make(map[string]*prometheusSeries),
+	}
+}
+
+// SetGauge sets the current value of a gauge.
+func (p *PrometheusSink) SetGauge(key []string, val float32) {
+	key = p.trimKey(key)
+	if p.hostName != "" && len(key) > 0 && key[0] == p.hostName {
+		key = key[1:]
+	}
+
+	p.update(prometheusGauge, key, func(s *prometheusSeries) {
+		s.value = float64(val)
+	})
+}
+
+// EmitKey is not supported by Prometheus and the value is discarded.
+func (p *PrometheusSink) EmitKey(key []string, val float32) {}
+
+// IncrCounter increments a counter.
+func (p *PrometheusSink) IncrCounter(key []string, val float32) {
+	p.update(prometheusCounter, p.trimKey(key), func(s *prometheusSeries) {
+		s.value += float64(val)
+	})
+}
+
+// AddSample records a sample, which is exposed as the sum and count of a
+// summary.
+func (p *PrometheusSink) AddSample(key []string, val float32) {
+	p.update(prometheusSummary, p.trimKey(key), func(s *prometheusSeries) {
+		s.value += float64(val)
+		s.count++
+	})
+}
+
+// trimKey removes the service name inserted by the metrics library.
+func (p *PrometheusSink) trimKey(key []string) []string {
+	if len(key) > 0 && key[0] == prometheusNamespace {
+		return key[1:]
+	}
+	return key
+}
+
+// update applies a change to the series identified by the metric type and
+// key, creating the series if required.
+func (p *PrometheusSink) update(kind string, key []string,
+	apply func(*prometheusSeries)) {
+	name, labels := prometheusName(kind, key)
+
+	id := kind + "|" + name
+	for _, label := range labels {
+		id += "|" + label[0] + "=" + label[1]
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	s, ok := p.series[id]
+	if !ok {
+		s = &prometheusSeries{name: name, labels: labels, kind: kind}
+		p.series[id] = s
+	}
+	apply(s)
+}
+
+// Write writes every series retained by the sink in the Prometheus text
+// format, sorted by metric name.
+func (p *PrometheusSink) Write(w io.Writer) error {
+	p.lock.Lock()
+	series := make([]prometheusSeries, 0, len(p.series))
+	for _, s := range p.series {
+		series = append(series, *s)
+	}
+	p.lock.Unlock()
+
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].name != series[j].name {
+			return series[i].name < series[j].name
+		}
+		return formatLabels(series[i].labels) < formatLabels(series[j].labels)
+	})
+
+	buf := bufio.NewWriter(w)
+
+	var last string
+	for _, s := range series {
+		if s.name != last {
+			fmt.Fprintf(buf, "# TYPE %s %s\n", s.name, s.kind)
+			last = s.name
+		}
+
+		labels := formatLabels(s.labels)
+
+		if s.kind == prometheusSummary {
+			fmt.Fprintf(buf, "%s_sum%s %s\n", s.name, labels, formatValue(s.value))
+			fmt.Fprintf(buf, "%s_count%s %d\n", s.name, labels, s.count)
+			continue
+		}
+
+		fmt.Fprintf(buf, "%s%s %s\n", s.name, labels, formatValue(s.value))
+	}
+
+	return buf.Flush()
+}
+
+// prometheusName translates a metric key into a Prometheus metric name and
+// labels using the first matching rule for the metric type.
+func prometheusName(kind string, key []string) (string, [][2]string) {
+	var suffix string
+	if kind == prometheusCounter {
+		suffix = "_total"
+	}
+
+RULES:
+	for _, rule := range prometheusRules[kind] {
+		if len(rule.pattern) != len(key) {
+			continue
+		}
+
+		name := rule.name
+		var labels [][2]string
+
+		for i, element := range rule.pattern {
+			switch {
+			case element == "*":
+				name += "_" + key[i]
+			case strings.HasPrefix(element, "{") && strings.HasSuffix(element, "}"):
+				labels = append(labels, [2]string{strings.Trim(element, "{}"), key[i]})
+			case element != key[i]:
+				continue RULES
+			}
+		}
+
+		return sanitizeName(prometheusNamespace+"_"+name) + suffix, labels
+	}
+
+	return sanitizeName(prometheusNamespace+"_"+strings.Join(key, "_")) + suffix, nil
+}
+
+// sanitizeName replaces the characters which are not permitted in a
+// Prometheus metric name.
+func sanitizeName(name string) string {
+	return prometheusInvalidChars.ReplaceAllString(name, "_")
+}
+
+// formatLabels formats labels as a Prometheus label set.
+func formatLabels(labels [][2]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for _, label := range labels {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).
+			Replace(label[1])
+		pairs = append(pairs, label[0]+`="`+value+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatValue formats a sample value.
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrometheusSink_Write(t *testing.T) {
+	sink := NewPrometheusSink("host-1")
+
+	sink.IncrCounter([]string{"replicator", "cluster", "pool-a", "scale_out", "success"}, 1)
+	sink.IncrCounter([]string{"replicator", "cluster", "pool-a", "scale_out", "success"}, 1)
+	sink.IncrCounter([]string{"replicator", "cluster", "leadership", "election"}, 1)
+	sink.IncrCounter([]string{"replicator", "job", "web", "frontend", "resize", "failure"}, 1)
+	sink.SetGauge([]string{"replicator", "host-1", "pool", "pool-a", "node_count"}, 3)
+	sink.SetGauge([]string{"replicator", "host-1", "job", "web", `front"end`, "desired_count"}, 2.5)
+	sink.AddSample([]string{"replicator", "runtime", "gc_pause_ns"}, 10)
+	sink.AddSample([]string{"replicator", "runtime", "gc_pause_ns"}, 5)
+	sink.EmitKey([]string{"replicator", "ignored"}, 1)
+
+	var buf bytes.Buffer
+	if err := sink.Write(&buf); err != nil {
+		t.Fatalf("unexpected error writing metrics: %v", err)
+	}
+
+	expected := `# TYPE replicator_cluster_leadership_election_total counter
+replicator_cluster_leadership_election_total 1
+# TYPE replicator_cluster_scaling_total counter
+replicator_cluster_scaling_total{pool="pool-a",operation="scale_out",outcome="success"} 2
+# TYPE replicator_job_group_desired_count gauge
+replicator_job_group_desired_count{job="web",group="front\"end"} 2.5
+# TYPE replicator_job_scaling_total counter
+replicator_job_scaling_total{job="web",group="frontend",operation="resize",outcome="failure"} 1
+# TYPE replicator_pool_node_count gauge
+replicator_pool_node_count{pool="pool-a"} 3
+# TYPE replicator_runtime_gc_pause_ns summary
+replicator_runtime_gc_pause_ns_sum 15
+replicator_runtime_gc_pause_ns_count 2
+`
+
+	if buf.String() != expected {
+		t.Fatalf("expected metrics:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
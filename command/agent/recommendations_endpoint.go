@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"net/http"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// RecommendationsRequest is used to perform the Recommendations.List API
+// request. Results can be filtered to a single job using the job query
+// parameter.
+func (s *HTTPServer) RecommendationsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	var out structs.RecommendationListResponse
+	args := structs.QueryOptions{AuthToken: aclToken(req)}
+	if err := s.agent.RPC("Recommendations.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	job := req.URL.Query().Get("job")
+	if job == "" {
+		return out.Recommendations, nil
+	}
+
+	filtered := make([]*structs.Recommendation, 0)
+	for _, recommendation := range out.Recommendations {
+		if recommendation.JobID == job {
+			filtered = append(filtered, recommendation)
+		}
+	}
+
+	return filtered, nil
+}
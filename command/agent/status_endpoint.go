@@ -13,7 +13,7 @@ func (s *HTTPServer) StatusLeaderRequest(resp http.ResponseWriter, req *http.Req
 	}
 
 	var leader structs.LeaderResponse
-	if err := s.agent.RPC("Status.Leader", &leader); err != nil {
+	if err := s.agent.RPC("Status.Leader", nil, &leader); err != nil {
 		return nil, err
 	}
 	return leader, nil
@@ -20,6 +20,7 @@ const (
 	DefaultHTTPPort    = "1313"
 	LocalConsulAddress = "localhost:8500"
 	LocalNomadAddress  = "http://localhost:4646"
+	DefaultStatePath   = "replicator.db"
 )
 
 var (
@@ -46,6 +47,9 @@ func DefaultConfig() *structs.Config {
 
 		Telemetry:    &structs.Telemetry{},
 		Notification: &structs.Notification{},
+		History:      DefaultHistory(),
+		RightSizing:  DefaultRightSizing(),
+		State:        DefaultState(),
 	}
 }
 
@@ -68,11 +72,49 @@ func DevConfig() *structs.Config {
 
 		Telemetry:    &structs.Telemetry{},
 		Notification: &structs.Notification{},
+		History:      DefaultHistory(),
+		RightSizing:  DefaultRightSizing(),
+		State:        DefaultState(),
+	}
+}
+
+// DefaultHistory returns the default scaling event history retention which
+// retains up to 500 events per resource for one week.
+func DefaultHistory() *structs.History {
+	return &structs.History{
+		MaxEvents:      500,
+		RetentionHours: 168,
+	}
+}
+
+// DefaultRightSizing returns the default configuration used when calculating
+// task resource right-sizing recommendations. With the default job scaling
+// interval of 10 seconds, the history covers the previous 24 hours.
+func DefaultRightSizing() *structs.RightSizing {
+	return &structs.RightSizing{
+		CPUPercentile:    95,
+		HeadroomPercent:  15,
+		HistorySize:      8640,
+		MemoryPercentile: 99,
+		MinSamples:       60,
+	}
+}
+
+// DefaultState returns the default state storage configuration which
+// persists state in the Consul Key/Value store. The state of removed worker
+// pools and job groups is deleted after 24 hours.
+func DefaultState() *structs.State {
+	return &structs.State{
+		Backend:       structs.StateBackendConsul,
+		GCGracePeriod: 86400,
+		GCInterval:    300,
+		Path:          DefaultStatePath,
 	}
 }
 
 // InitializeClients completes the setup process for the Nomad and Consul
-// clients. Must be called after configuration merging is complete.
+// clients and the state store. Must be called after configuration merging is
+// complete.
 func InitializeClients(config *structs.Config) (err error) {
 	// Setup the Nomad Client
 	nClient, err := client.NewNomadClient(config.Nomad, config.NomadToken,
@@ -91,6 +133,20 @@ func InitializeClients(config *structs.Config) (err error) {
 	config.ConsulClient = cClient
 	config.NomadClient = nClient
 
+	// The raft state store is setup by the server once the Raft cluster has
+	// been formed.
+	if config.State != nil && config.State.Backend == structs.StateBackendRaft {
+		return
+	}
+
+	// Setup the state store
+	stateStore, err := client.NewStateStore(config)
+	if err != nil {
+		return
+	}
+
+	config.StateStore = stateStore
+
 	return
 }
 
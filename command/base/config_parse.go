@@ -69,6 +69,7 @@ func parseConfig(result *structs.Config, list *ast.ObjectList) error {
 
 	// Check for invalid keys
 	valid := []string{
+		"acl",
 		"bind_address",
 		"http_port",
 		"rpc_port",
@@ -82,7 +83,11 @@ func parseConfig(result *structs.Config, list *ast.ObjectList) error {
 		"job_scaling_interval",
 		"cluster_scaling_interval",
 		"telemetry",
+		"tls",
 		"notification",
+		"history",
+		"right_sizing",
+		"state",
 		"cluster_scaling_disable",
 		"job_scaling_disable",
 		"scaling_concurrency",
@@ -98,25 +103,89 @@ func parseConfig(result *structs.Config, list *ast.ObjectList) error {
 		return err
 	}
 
+	delete(m, "acl")
 	delete(m, "telemetry")
+	delete(m, "tls")
 	delete(m, "notification")
+	delete(m, "history")
+	delete(m, "right_sizing")
+	delete(m, "state")
 
 	if err := mapstructure.WeakDecode(m, result); err != nil {
 		return err
 	}
 
+	if o := list.Filter("acl"); len(o.Items) > 0 {
+		if err := parseACL(&result.ACL, o); err != nil {
+			return multierror.Prefix(err, "acl ->")
+		}
+	}
+
 	if o := list.Filter("telemetry"); len(o.Items) > 0 {
 		if err := parseTelemetry(&result.Telemetry, o); err != nil {
 			return multierror.Prefix(err, "telemetry ->")
 		}
 	}
 
+	if o := list.Filter("tls"); len(o.Items) > 0 {
+		if err := parseTLS(&result.TLS, o); err != nil {
+			return multierror.Prefix(err, "tls ->")
+		}
+	}
+
 	if o := list.Filter("notification"); len(o.Items) > 0 {
 		if err := parseNotification(&result.Notification, o); err != nil {
 			return multierror.Prefix(err, "notification ->")
 		}
 	}
 
+	if o := list.Filter("history"); len(o.Items) > 0 {
+		if err := parseHistory(&result.History, o); err != nil {
+			return multierror.Prefix(err, "history ->")
+		}
+	}
+
+	if o := list.Filter("right_sizing"); len(o.Items) > 0 {
+		if err := parseRightSizing(&result.RightSizing, o); err != nil {
+			return multierror.Prefix(err, "right_sizing ->")
+		}
+	}
+
+	if o := list.Filter("state"); len(o.Items) > 0 {
+		if err := parseState(&result.State, o); err != nil {
+			return multierror.Prefix(err, "state ->")
+		}
+	}
+
+	return nil
+}
+
+func parseACL(result **structs.ACL, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'ACL' block allowed")
+	}
+
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"enabled",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var acl structs.ACL
+	if err := mapstructure.WeakDecode(m, &acl); err != nil {
+		return err
+	}
+	*result = &acl
 	return nil
 }
 
@@ -130,6 +199,7 @@ func parseTelemetry(result **structs.Telemetry, list *ast.ObjectList) error {
 
 	// Check for invalid keys
 	valid := []string{
+		"prometheus_metrics",
 		"statsd_address",
 	}
 	if err := checkHCLKeys(listVal, valid); err != nil {
@@ -149,6 +219,40 @@ func parseTelemetry(result **structs.Telemetry, list *ast.ObjectList) error {
 	return nil
 }
 
+func parseTLS(result **structs.TLS, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'TLS' block allowed")
+	}
+
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"ca_file",
+		"cert_file",
+		"http",
+		"key_file",
+		"rpc",
+		"verify_incoming",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var tls structs.TLS
+	if err := mapstructure.WeakDecode(m, &tls); err != nil {
+		return err
+	}
+	*result = &tls
+	return nil
+}
+
 func parseNotification(result **structs.Notification, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) > 1 {
@@ -180,6 +284,103 @@ func parseNotification(result **structs.Notification, list *ast.ObjectList) erro
 	return nil
 }
 
+func parseHistory(result **structs.History, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'History' block allowed")
+	}
+
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"max_events",
+		"retention_hours",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var history structs.History
+	if err := mapstructure.WeakDecode(m, &history); err != nil {
+		return err
+	}
+	*result = &history
+	return nil
+}
+
+func parseRightSizing(result **structs.RightSizing, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'RightSizing' block allowed")
+	}
+
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"cpu_percentile",
+		"headroom_percent",
+		"history_size",
+		"memory_percentile",
+		"min_samples",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var rightSizing structs.RightSizing
+	if err := mapstructure.WeakDecode(m, &rightSizing); err != nil {
+		return err
+	}
+	*result = &rightSizing
+	return nil
+}
+
+func parseState(result **structs.State, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'State' block allowed")
+	}
+
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"backend",
+		"gc_disable",
+		"gc_grace_period",
+		"gc_interval",
+		"path",
+		"raft_peers",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var state structs.State
+	if err := mapstructure.WeakDecode(m, &state); err != nil {
+		return err
+	}
+	*result = &state
+	return nil
+}
+
 func checkHCLKeys(node ast.Node, valid []string) error {
 	var list *ast.ObjectList
 	switch n := node.(type) {
@@ -20,8 +20,22 @@ func TestConfigParse_LoadConfigFile(t *testing.T) {
     cluster_scaling_interval = 2
     scaling_concurrency      = 5
 
+    acl {
+      enabled = true
+    }
+
     telemetry {
-      statsd_address = "10.0.0.10:8125"
+      prometheus_metrics = true
+      statsd_address     = "10.0.0.10:8125"
+    }
+
+    tls {
+      http            = true
+      rpc             = true
+      ca_file         = "/etc/replicator/ca.pem"
+      cert_file       = "/etc/replicator/agent.pem"
+      key_file        = "/etc/replicator/agent-key.pem"
+      verify_incoming = true
     }
 
     notification {
@@ -30,6 +44,27 @@ func TestConfigParse_LoadConfigFile(t *testing.T) {
       cluster_identifier    = "nomad-prod"
     }
 
+    history {
+      max_events      = 100
+      retention_hours = 24
+    }
+
+    right_sizing {
+      cpu_percentile    = 90
+      memory_percentile = 95
+      headroom_percent  = 20
+      history_size      = 360
+      min_samples       = 30
+    }
+
+    state {
+      backend         = "raft"
+      gc_grace_period = 3600
+      gc_interval     = 60
+      path            = "/var/lib/replicator/raft.db"
+      raft_peers      = ["10.0.0.1:1314", "10.0.0.2:1314"]
+    }
+
   `), t)
 	defer test.DeleteTempfile(configFile, t)
 
@@ -48,8 +83,22 @@ func TestConfigParse_LoadConfigFile(t *testing.T) {
 		ClusterScalingInterval: 2,
 		ScalingConcurrency:     5,
 
+		ACL: &structs.ACL{
+			Enabled: true,
+		},
+
 		Telemetry: &structs.Telemetry{
-			StatsdAddress: "10.0.0.10:8125",
+			PrometheusMetrics: true,
+			StatsdAddress:     "10.0.0.10:8125",
+		},
+
+		TLS: &structs.TLS{
+			CAFile:         "/etc/replicator/ca.pem",
+			CertFile:       "/etc/replicator/agent.pem",
+			EnableHTTP:     true,
+			EnableRPC:      true,
+			KeyFile:        "/etc/replicator/agent-key.pem",
+			VerifyIncoming: true,
 		},
 
 		Notification: &structs.Notification{
@@ -57,6 +106,27 @@ func TestConfigParse_LoadConfigFile(t *testing.T) {
 			OpsGenieAPIKey:      "thisisafakeapikey",
 			ClusterIdentifier:   "nomad-prod",
 		},
+
+		History: &structs.History{
+			MaxEvents:      100,
+			RetentionHours: 24,
+		},
+
+		RightSizing: &structs.RightSizing{
+			CPUPercentile:    90,
+			MemoryPercentile: 95,
+			HeadroomPercent:  20,
+			HistorySize:      360,
+			MinSamples:       30,
+		},
+
+		State: &structs.State{
+			Backend:       "raft",
+			GCGracePeriod: 3600,
+			GCInterval:    60,
+			Path:          "/var/lib/replicator/raft.db",
+			RaftPeers:     []string{"10.0.0.1:1314", "10.0.0.2:1314"},
+		},
 	}
 	if !reflect.DeepEqual(c, expected) {
 		t.Fatalf("expected \n%#v\n\n, got \n\n%#v\n\n", expected, c)
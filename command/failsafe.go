@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"os/user"
 	"strings"
 
 	"github.com/elsevier-core-engineering/replicator/command/base"
@@ -34,6 +35,9 @@ Usage: replicator failsafe [options]
   To exit failsafe mode, an operator must explicitly remove the failsafe
   lock after identifying the root cause of the failures.
 
+  This command is not supported with the raft state backend; failsafe mode
+  must instead be changed through the /v1/failsafe API endpoints.
+
   General Options:
 
     -config=<path>
@@ -104,18 +108,27 @@ func (c *FailsafeCommand) Run(args []string) int {
 		return 1
 	}
 
+	// The raft state store is only available to the running servers, so
+	// failsafe mode must be changed through the API of an agent instead.
+	if conf.Config.State != nil &&
+		conf.Config.State.Backend == structs.StateBackendRaft {
+		c.UI.Error("The failsafe command is not supported with the raft state " +
+			"backend, use the /v1/failsafe API endpoints of an agent instead")
+		return 1
+	}
+
 	// Set the state path in the state object.
 	state.StatePath = c.statePath
 
-	// Setup the Consul client.
+	// Setup the clients and state store.
 	if err := base.InitializeClients(conf.Config); err != nil {
 		c.UI.Error(fmt.Sprintf("An error occurred while attempting to initialize "+
-			"the Consul client: %v", err))
+			"the state store: %v", err))
 		return 1
 	}
 
-	// Grab the initialized consul client from the returned configuration object.
-	consul := conf.Config.ConsulClient
+	// Grab the initialized state store from the returned configuration object.
+	stateStore := conf.Config.StateStore
 
 	// Check that we were sent either enable or disable, but not both.
 	if (conf.Enable && conf.Disable) || (!conf.Enable && !conf.Disable) {
@@ -123,8 +136,8 @@ func (c *FailsafeCommand) Run(args []string) int {
 		return 1
 	}
 
-	// Attempt to load state tracking data from Consul.
-	consul.ReadState(state, false)
+	// Attempt to load state tracking data from the state store.
+	stateStore.ReadState(state, false)
 
 	// If there was no state object at the specified path, throw an error.
 	if state.LastUpdated.IsZero() {
@@ -176,8 +189,13 @@ func (c *FailsafeCommand) Run(args []string) int {
 		}
 	}
 
-	// Indicate that failsafe mode was administratively updated.
+	// Indicate that failsafe mode was administratively updated and record the
+	// operator making the change.
 	state.FailsafeAdmin = true
+	state.FailsafeChangedBy = "cli"
+	if u, err := user.Current(); err == nil {
+		state.FailsafeChangedBy = "cli:" + u.Username
+	}
 
 	// Setup a failure message to pass to the failsafe method.
 	message := &notifier.FailureMessage{
@@ -195,7 +213,7 @@ func (c *FailsafeCommand) Run(args []string) int {
 		return 1
 	}
 
-	if err := consul.PersistState(state); err != nil {
+	if err := stateStore.PersistState(state); err != nil {
 		c.UI.Error(fmt.Sprintf("An error occurred while attempting to %v "+
 			"failsafe mode for %v %v: %v", conf.Verb, state.ResourceType,
 			state.ResourceName, err))
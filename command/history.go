@@ -0,0 +1,170 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// HistoryCommand is a command implementation that displays the scaling event
+// history of a worker pool or job.
+type HistoryCommand struct {
+	Meta
+}
+
+// Help provides the help information for the history command.
+func (c *HistoryCommand) Help() string {
+	helpText := `
+Usage: replicator history [options]
+
+  Displays the scaling event history of a worker pool or job for
+  post-incident review. Each event records the scaling direction, the count
+  before and after the operation, the metric values which triggered it, the
+  Replicator instance which performed it and its outcome, including failsafe
+  mode transitions. Events are retained according to the history
+  configuration block.
+
+  General Options:
+
+    -address=<addr>
+      The address of the Replicator agent HTTP API. Overrides the
+      REPLICATOR_ADDR environment variable. By default, this is
+      http://127.0.0.1:1313.
+
+    -token=<token>
+      The secret ID of the ACL token used to authorize requests. Overrides
+      the REPLICATOR_TOKEN environment variable.
+
+    -ca-cert=<path>
+      Path to a PEM encoded CA certificate file used to verify the
+      certificate of the Replicator agent. Overrides the REPLICATOR_CACERT
+      environment variable.
+
+    -client-cert=<path>
+      Path to a PEM encoded client certificate presented to the Replicator
+      agent when it verifies incoming connections. Overrides the
+      REPLICATOR_CLIENT_CERT environment variable.
+
+    -client-key=<path>
+      Path to the unencrypted PEM encoded private key of the client
+      certificate. Overrides the REPLICATOR_CLIENT_KEY environment variable.
+
+    -tls-server-name=<name>
+      The server name used as the SNI host when connecting via TLS.
+      Overrides the REPLICATOR_TLS_SERVER_NAME environment variable.
+
+    -tls-skip-verify
+      Do not verify the certificate of the Replicator agent. This is not
+      recommended. Overrides the REPLICATOR_SKIP_VERIFY environment variable.
+
+  History Options:
+
+    -pool=<name>
+      Display the history of the specified worker pool.
+
+    -job=<job_id>
+      Display the history of the groups of the specified job.
+
+    -group=<group>
+      When used with -job, only display the history of the specified group.
+
+    -limit=<count>
+      Only display the specified number of most recent events.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is provides a brief summary of the history command.
+func (c *HistoryCommand) Synopsis() string {
+	return "Display the scaling event history of a worker pool or job"
+}
+
+// Run triggers the history command to query and display scaling events from
+// the Replicator agent.
+func (c *HistoryCommand) Run(args []string) int {
+	var pool, job, group string
+	var limit int
+
+	// Initialize command flags.
+	flags := c.Meta.FlagSet("history", FlagSetHTTP)
+	flags.Usage = func() { c.UI.Error(c.Help()) }
+	flags.StringVar(&pool, "pool", "", "")
+	flags.StringVar(&job, "job", "", "")
+	flags.StringVar(&group, "group", "", "")
+	flags.IntVar(&limit, "limit", 0, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Exactly one of a worker pool or job must be specified.
+	if (pool == "") == (job == "") || (group != "" && job == "") {
+		c.UI.Error(c.Help())
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing client: %v", err))
+		return 1
+	}
+
+	var events []*structs.ScalingEvent
+	if pool != "" {
+		events, _, err = client.History().Pool(pool, nil)
+	} else {
+		events, _, err = client.History().Job(job, group, nil)
+	}
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error querying scaling event history: %v", err))
+		return 1
+	}
+
+	if len(events) == 0 {
+		c.UI.Output("No scaling events have been recorded")
+		return 0
+	}
+
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+
+	c.UI.Output(formatEvents(events))
+	return 0
+}
+
+// formatEvents formats scaling events as a table for display.
+func formatEvents(events []*structs.ScalingEvent) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Time\tResource\tType\tDirection\tCount\tOutcome\tNode\t"+
+		"Metrics\tReason")
+
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d -> %d\t%s\t%s\t%s\t%s\n",
+			e.Timestamp.Format(time.RFC3339), e.ResourceID, e.Type, e.Direction,
+			e.CountBefore, e.CountAfter, e.Outcome, e.NodeID,
+			formatEventMetrics(e.Metrics), e.Reason)
+	}
+	w.Flush()
+
+	return strings.TrimSpace(buf.String())
+}
+
+// formatEventMetrics formats the metric values of a scaling event as a
+// sorted list of key=value pairs.
+func formatEventMetrics(metrics map[string]float64) string {
+	pairs := make([]string, 0, len(metrics))
+	for k, v := range metrics {
+		pairs = append(pairs, fmt.Sprintf("%s=%.2f", k, v))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
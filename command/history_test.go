@@ -0,0 +1,59 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	"github.com/mitchellh/cli"
+)
+
+func TestHistoryCommand_implements(t *testing.T) {
+	var _ cli.Command = &HistoryCommand{}
+}
+
+func TestHistoryCommand_Run(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &HistoryCommand{Meta: Meta{UI: ui}}
+
+	// A worker pool or job must be specified, but not both.
+	for _, args := range [][]string{
+		{},
+		{"-pool=example-pool", "-job=example"},
+		{"-group=cache"},
+	} {
+		if code := cmd.Run(args); code != 1 {
+			t.Fatalf("expected exit code 1 for %v, got: %d", args, code)
+		}
+	}
+}
+
+func TestHistoryCommand_formatEvents(t *testing.T) {
+	events := []*structs.ScalingEvent{
+		{
+			CountAfter:  4,
+			CountBefore: 3,
+			Direction:   structs.ScalingDirectionOut,
+			Metrics: map[string]float64{
+				"memory_percent": 91.5,
+				"cpu_percent":    40,
+			},
+			NodeID:     "replicator-1",
+			Outcome:    structs.EventOutcomeSuccess,
+			ResourceID: "example/cache",
+			Timestamp:  time.Now(),
+			Type:       structs.EventTypeScale,
+		},
+	}
+
+	out := formatEvents(events)
+	for _, expected := range []string{
+		"example/cache", "3 -> 4", "replicator-1",
+		"cpu_percent=40.00,memory_percent=91.50",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("expected output to contain %q but got:\n%s", expected, out)
+		}
+	}
+}
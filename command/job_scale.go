@@ -0,0 +1,121 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// JobScaleCommand is a command implementation that allows operators to
+// manually scale a job group.
+type JobScaleCommand struct {
+	Meta
+}
+
+// Help provides the help information for the job scale command.
+func (c *JobScaleCommand) Help() string {
+	helpText := `
+Usage: replicator job scale [options] <job> <group>
+
+  Requests a manual scaling operation against a job group. The operation is
+  performed by the Replicator leader using the same path as automatic
+  scaling and waits for the operation to be confirmed. The operation is
+  recorded in the scaling event history as a manual event.
+
+  The scaling policy min and max bounds and failsafe mode of the group are
+  honoured unless the -force flag is specified.
+
+  General Options:
+
+    -address=<addr>
+      The address of the Replicator agent HTTP API. Overrides the
+      REPLICATOR_ADDR environment variable. By default, this is
+      http://127.0.0.1:1313.
+
+    -token=<token>
+      The secret ID of the ACL token used to authorize requests. Overrides
+      the REPLICATOR_TOKEN environment variable.
+
+    -ca-cert=<path>
+      Path to a PEM encoded CA certificate file used to verify the
+      certificate of the Replicator agent. Overrides the REPLICATOR_CACERT
+      environment variable.
+
+    -client-cert=<path>
+      Path to a PEM encoded client certificate presented to the Replicator
+      agent when it verifies incoming connections. Overrides the
+      REPLICATOR_CLIENT_CERT environment variable.
+
+    -client-key=<path>
+      Path to the unencrypted PEM encoded private key of the client
+      certificate. Overrides the REPLICATOR_CLIENT_KEY environment variable.
+
+    -tls-server-name=<name>
+      The server name used as the SNI host when connecting via TLS.
+      Overrides the REPLICATOR_TLS_SERVER_NAME environment variable.
+
+    -tls-skip-verify
+      Do not verify the certificate of the Replicator agent. This is not
+      recommended. Overrides the REPLICATOR_SKIP_VERIFY environment variable.
+
+  Scale Options:
+
+    -count=<count>
+      Scale the group to the specified count.
+
+    -direction=<in|out>
+      Scale the group in or out by a single allocation.
+
+    -force
+      Override the scaling policy bounds and failsafe mode.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is provides a brief summary of the job scale command.
+func (c *JobScaleCommand) Synopsis() string {
+	return "Manually scale a job group"
+}
+
+// Run triggers the job scale command to request a manual scaling operation
+// from the Replicator agent.
+func (c *JobScaleCommand) Run(args []string) int {
+	var count int
+	req := &structs.ScaleRequest{}
+
+	flags := c.Meta.FlagSet("job scale", FlagSetHTTP)
+	flags.Usage = func() { c.UI.Error(c.Help()) }
+	flags.IntVar(&count, "count", -1, "")
+	flags.StringVar(&req.Direction, "direction", "", "")
+	flags.BoolVar(&req.Force, "force", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Exactly one of a count or direction must be specified.
+	args = flags.Args()
+	if len(args) != 2 || (count < 0) == (req.Direction == "") {
+		c.UI.Error(c.Help())
+		return 1
+	}
+
+	if count >= 0 {
+		req.Count = &count
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing client: %v", err))
+		return 1
+	}
+
+	events, _, err := client.Jobs().Scale(args[0], args[1], req, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error scaling job group: %v", err))
+		return 1
+	}
+
+	return outputScaleEvents(c.UI, events)
+}
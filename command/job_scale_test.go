@@ -0,0 +1,28 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestJobScaleCommand_implements(t *testing.T) {
+	var _ cli.Command = &JobScaleCommand{}
+}
+
+func TestJobScaleCommand_Run(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &JobScaleCommand{Meta: Meta{UI: ui}}
+
+	// A job and group and exactly one of a count or direction are required.
+	for _, args := range [][]string{
+		{},
+		{"-count=2", "example"},
+		{"example", "cache"},
+		{"-count=2", "-direction=out", "example", "cache"},
+	} {
+		if code := cmd.Run(args); code != 1 {
+			t.Fatalf("expected exit code 1 for %v, got: %d", args, code)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"io"
 
+	"github.com/elsevier-core-engineering/replicator/api"
 	"github.com/mitchellh/cli"
 )
 
@@ -17,6 +18,8 @@ const (
 	FlagSetNone FlagSetFlags = 0
 	// FlagSetClient is our enum.
 	FlagSetClient FlagSetFlags = 1 << iota
+	// FlagSetHTTP is set when the command talks to the Replicator HTTP API.
+	FlagSetHTTP
 	// FlagSetDefault is our default flag set.
 	FlagSetDefault = FlagSetClient
 )
@@ -25,6 +28,18 @@ const (
 // Replicator commands can inherit.
 type Meta struct {
 	UI cli.Ui
+
+	// flagAddress is the address of the Replicator agent HTTP API and
+	// flagToken the ACL token used to authorize requests.
+	flagAddress string
+	flagToken   string
+
+	// The TLS settings used when the agent serves the HTTP API over TLS.
+	flagCACert        string
+	flagClientCert    string
+	flagClientKey     string
+	flagTLSServerName string
+	flagTLSSkipVerify bool
 }
 
 // FlagSet returns a FlagSet with the common flags that every
@@ -34,6 +49,17 @@ type Meta struct {
 func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 	f := flag.NewFlagSet(n, flag.ContinueOnError)
 
+	// Add the flags used to reach the Replicator HTTP API.
+	if fs&FlagSetHTTP != 0 {
+		f.StringVar(&m.flagAddress, "address", "", "")
+		f.StringVar(&m.flagToken, "token", "", "")
+		f.StringVar(&m.flagCACert, "ca-cert", "", "")
+		f.StringVar(&m.flagClientCert, "client-cert", "", "")
+		f.StringVar(&m.flagClientKey, "client-key", "", "")
+		f.StringVar(&m.flagTLSServerName, "tls-server-name", "", "")
+		f.BoolVar(&m.flagTLSSkipVerify, "tls-skip-verify", false, "")
+	}
+
 	// Create an io.Writer that writes to our UI properly for errors.
 	// This is kind of a hack, but it does the job. Basically: create
 	// a pipe, use a scanner to break it into lines, and output each line
@@ -49,3 +75,44 @@ func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 
 	return f
 }
+
+// Client is used to initialize and return a new API client using the
+// address, ACL token and TLS settings passed to the command, if any, falling
+// back to the REPLICATOR_* environment variables.
+func (m *Meta) Client() (*api.Client, error) {
+	config := api.DefaultConfig()
+
+	if m.flagAddress != "" {
+		config.Address = m.flagAddress
+	}
+
+	if m.flagToken != "" {
+		config.Token = m.flagToken
+	}
+
+	// The TLS flags override the TLS configuration read from the environment.
+	if m.flagCACert != "" || m.flagClientCert != "" || m.flagClientKey != "" ||
+		m.flagTLSServerName != "" || m.flagTLSSkipVerify {
+		if config.TLSConfig == nil {
+			config.TLSConfig = &api.TLSConfig{}
+		}
+
+		if m.flagCACert != "" {
+			config.TLSConfig.CACert = m.flagCACert
+		}
+		if m.flagClientCert != "" {
+			config.TLSConfig.ClientCert = m.flagClientCert
+		}
+		if m.flagClientKey != "" {
+			config.TLSConfig.ClientKey = m.flagClientKey
+		}
+		if m.flagTLSServerName != "" {
+			config.TLSConfig.TLSServerName = m.flagTLSServerName
+		}
+		if m.flagTLSSkipVerify {
+			config.TLSConfig.Insecure = true
+		}
+	}
+
+	return api.NewClient(config)
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	"github.com/mitchellh/cli"
+)
+
+// PoolScaleCommand is a command implementation that allows operators to
+// manually scale a worker pool.
+type PoolScaleCommand struct {
+	Meta
+}
+
+// Help provides the help information for the pool scale command.
+func (c *PoolScaleCommand) Help() string {
+	helpText := `
+Usage: replicator pool scale [options] <pool>
+
+  Requests a manual scaling operation against a worker pool. The operation
+  is performed by the Replicator leader using the same path as automatic
+  scaling, one node at a time: nodes are added by the scaling provider, or
+  the least allocated node is drained before being removed by the scaling
+  provider. Each operation is recorded in the scaling event history as a
+  manual event.
+
+  The scaling provider safety check, which enforces the provider min and max
+  bounds, and failsafe mode of the pool are honoured unless the -force flag
+  is specified.
+
+  General Options:
+
+    -address=<addr>
+      The address of the Replicator agent HTTP API. Overrides the
+      REPLICATOR_ADDR environment variable. By default, this is
+      http://127.0.0.1:1313.
+
+    -token=<token>
+      The secret ID of the ACL token used to authorize requests. Overrides
+      the REPLICATOR_TOKEN environment variable.
+
+    -ca-cert=<path>
+      Path to a PEM encoded CA certificate file used to verify the
+      certificate of the Replicator agent. Overrides the REPLICATOR_CACERT
+      environment variable.
+
+    -client-cert=<path>
+      Path to a PEM encoded client certificate presented to the Replicator
+      agent when it verifies incoming connections. Overrides the
+      REPLICATOR_CLIENT_CERT environment variable.
+
+    -client-key=<path>
+      Path to the unencrypted PEM encoded private key of the client
+      certificate. Overrides the REPLICATOR_CLIENT_KEY environment variable.
+
+    -tls-server-name=<name>
+      The server name used as the SNI host when connecting via TLS.
+      Overrides the REPLICATOR_TLS_SERVER_NAME environment variable.
+
+    -tls-skip-verify
+      Do not verify the certificate of the Replicator agent. This is not
+      recommended. Overrides the REPLICATOR_SKIP_VERIFY environment variable.
+
+  Scale Options:
+
+    -count=<count>
+      Scale the worker pool to the specified number of nodes.
+
+    -direction=<in|out>
+      Scale the worker pool in or out by a single node.
+
+    -force
+      Override the scaling provider safety check and failsafe mode.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is provides a brief summary of the pool scale command.
+func (c *PoolScaleCommand) Synopsis() string {
+	return "Manually scale a worker pool"
+}
+
+// Run triggers the pool scale command to request a manual scaling operation
+// from the Replicator agent.
+func (c *PoolScaleCommand) Run(args []string) int {
+	var count int
+	req := &structs.ScaleRequest{}
+
+	flags := c.Meta.FlagSet("pool scale", FlagSetHTTP)
+	flags.Usage = func() { c.UI.Error(c.Help()) }
+	flags.IntVar(&count, "count", -1, "")
+	flags.StringVar(&req.Direction, "direction", "", "")
+	flags.BoolVar(&req.Force, "force", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Exactly one of a count or direction must be specified.
+	args = flags.Args()
+	if len(args) != 1 || (count < 0) == (req.Direction == "") {
+		c.UI.Error(c.Help())
+		return 1
+	}
+
+	if count >= 0 {
+		req.Count = &count
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing client: %v", err))
+		return 1
+	}
+
+	events, _, err := client.Pools().Scale(args[0], req, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error scaling worker pool: %v", err))
+		return 1
+	}
+
+	return outputScaleEvents(c.UI, events)
+}
+
+// outputScaleEvents displays the events describing the scaling operations
+// performed by a manual scaling request. A non-zero exit code is returned if
+// any operation did not succeed.
+func outputScaleEvents(ui cli.Ui, events []*structs.ScalingEvent) int {
+	if len(events) == 0 {
+		ui.Output("The resource is already at the desired count, no scaling " +
+			"operation was performed")
+		return 0
+	}
+
+	ui.Output(formatEvents(events))
+
+	for _, event := range events {
+		if event.Outcome != structs.EventOutcomeSuccess {
+			return 1
+		}
+	}
+
+	return 0
+}
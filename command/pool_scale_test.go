@@ -0,0 +1,48 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	"github.com/mitchellh/cli"
+)
+
+func TestPoolScaleCommand_implements(t *testing.T) {
+	var _ cli.Command = &PoolScaleCommand{}
+}
+
+func TestPoolScaleCommand_Run(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &PoolScaleCommand{Meta: Meta{UI: ui}}
+
+	// A worker pool and exactly one of a count or direction are required.
+	for _, args := range [][]string{
+		{},
+		{"-count=2"},
+		{"example-pool"},
+		{"-count=2", "-direction=in", "example-pool"},
+	} {
+		if code := cmd.Run(args); code != 1 {
+			t.Fatalf("expected exit code 1 for %v, got: %d", args, code)
+		}
+	}
+}
+
+func TestPoolScaleCommand_outputScaleEvents(t *testing.T) {
+	ui := new(cli.MockUi)
+
+	if code := outputScaleEvents(ui, nil); code != 0 ||
+		!strings.Contains(ui.OutputWriter.String(), "already at the desired count") {
+		t.Fatalf("expected no operation to be reported but got %d: %s", code,
+			ui.OutputWriter.String())
+	}
+
+	events := []*structs.ScalingEvent{
+		{CountBefore: 2, CountAfter: 3, Outcome: structs.EventOutcomeSuccess},
+		{CountBefore: 3, CountAfter: 3, Outcome: structs.EventOutcomeFailure},
+	}
+	if code := outputScaleEvents(ui, events); code != 1 {
+		t.Fatalf("expected a failed operation to return exit code 1, got: %d", code)
+	}
+}
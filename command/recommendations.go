@@ -0,0 +1,117 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// RecommendationsCommand is a command implementation that displays task
+// resource right-sizing recommendations.
+type RecommendationsCommand struct {
+	Meta
+}
+
+// Help provides the help information for the recommendations command.
+func (c *RecommendationsCommand) Help() string {
+	helpText := `
+Usage: replicator recommendations [options]
+
+  Displays resource right-sizing recommendations for the tasks of jobs with
+  a Replicator scaling policy. Recommendations are calculated by the
+  Replicator leader from the rolling utilization history of each task, using
+  the percentiles and headroom defined in the right_sizing configuration
+  block.
+
+  General Options:
+
+    -address=<addr>
+      The address of the Replicator agent HTTP API. Overrides the
+      REPLICATOR_ADDR environment variable. By default, this is
+      http://127.0.0.1:1313.
+
+    -token=<token>
+      The secret ID of the ACL token used to authorize requests. Overrides
+      the REPLICATOR_TOKEN environment variable.
+
+    -ca-cert=<path>
+      Path to a PEM encoded CA certificate file used to verify the
+      certificate of the Replicator agent. Overrides the REPLICATOR_CACERT
+      environment variable.
+
+    -client-cert=<path>
+      Path to a PEM encoded client certificate presented to the Replicator
+      agent when it verifies incoming connections. Overrides the
+      REPLICATOR_CLIENT_CERT environment variable.
+
+    -client-key=<path>
+      Path to the unencrypted PEM encoded private key of the client
+      certificate. Overrides the REPLICATOR_CLIENT_KEY environment variable.
+
+    -tls-server-name=<name>
+      The server name used as the SNI host when connecting via TLS.
+      Overrides the REPLICATOR_TLS_SERVER_NAME environment variable.
+
+    -tls-skip-verify
+      Do not verify the certificate of the Replicator agent. This is not
+      recommended. Overrides the REPLICATOR_SKIP_VERIFY environment variable.
+
+    -job=<job_id>
+      Only display recommendations for the tasks of the specified job.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is provides a brief summary of the recommendations command.
+func (c *RecommendationsCommand) Synopsis() string {
+	return "Display task resource right-sizing recommendations"
+}
+
+// Run triggers the recommendations command to query and display right-sizing
+// recommendations from the Replicator agent.
+func (c *RecommendationsCommand) Run(args []string) int {
+	var job string
+
+	// Initialize command flags.
+	flags := c.Meta.FlagSet("recommendations", FlagSetHTTP)
+	flags.Usage = func() { c.UI.Error(c.Help()) }
+	flags.StringVar(&job, "job", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing client: %v", err))
+		return 1
+	}
+
+	recommendations, _, err := client.Recommendations().List(job, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error querying recommendations: %v", err))
+		return 1
+	}
+
+	if len(recommendations) == 0 {
+		c.UI.Output("No recommendations are currently available")
+		return 0
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Job\tGroup\tTask\tSamples\tCPU (MHz)\tRecommended CPU\t"+
+		"Memory (MB)\tRecommended Memory")
+
+	for _, r := range recommendations {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n", r.JobID, r.GroupName,
+			r.TaskName, r.Samples, r.CPUMHz, r.RecommendedCPUMHz, r.MemoryMB,
+			r.RecommendedMemoryMB)
+	}
+	w.Flush()
+
+	c.UI.Output(strings.TrimSpace(buf.String()))
+	return 0
+}
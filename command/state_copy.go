@@ -0,0 +1,280 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/command/base"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// StateCopyCommand is a command implementation that copies scaling state
+// between state storage backends.
+type StateCopyCommand struct {
+	Meta
+}
+
+// Help provides the help information for the state copy command.
+func (c *StateCopyCommand) Help() string {
+	helpText := `
+Usage: replicator state copy [options]
+
+  Copies all scaling state objects, their scaling event history and the ACL
+  tokens and policies from one state storage backend to another. This
+  allows a deployment to migrate between the Consul Key/Value store and a
+  local BoltDB database. Replicator agents using a BoltDB database must be
+  stopped before copying as the database is locked while in use. The raft
+  state backend is not supported as its state is only available through the
+  running Replicator servers.
+
+  General Options:
+
+    -config=<path>
+      The path to either a single config file or a directory of config
+      files used to determine the Consul and state configuration.
+
+    -consul=<address:port>
+      This is the address of the Consul agent. By default, this is
+      localhost:8500.
+
+    -consul-token=<token>
+      The Consul ACL token to use when communicating with an ACL protected
+      Consul cluster.
+
+    -path=<path>
+      The path of the BoltDB database file. By default, the path from the
+      state configuration block is used.
+
+  Copy Options:
+
+    -source=<backend>
+      The state backend to copy from, either consul or bolt.
+
+    -destination=<backend>
+      The state backend to copy to, either consul or bolt.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is provides a brief summary of the state copy command.
+func (c *StateCopyCommand) Synopsis() string {
+	return "Copy scaling state between state storage backends"
+}
+
+// Run triggers the state copy command to read all state objects from the
+// source backend and write them to the destination backend.
+func (c *StateCopyCommand) Run(args []string) int {
+	var configPath, source, destination string
+
+	cliConfig := &structs.Config{State: &structs.State{}}
+
+	flags := c.Meta.FlagSet("state copy", FlagSetClient)
+	flags.Usage = func() { c.UI.Error(c.Help()) }
+	flags.StringVar(&configPath, "config", "", "")
+	flags.StringVar(&cliConfig.Consul, "consul", "", "")
+	flags.StringVar(&cliConfig.ConsulToken, "consul-token", "", "")
+	flags.StringVar(&cliConfig.State.Path, "path", "", "")
+	flags.StringVar(&source, "source", "", "")
+	flags.StringVar(&destination, "destination", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if source == "" || destination == "" || source == destination {
+		c.UI.Error("A differing -source and -destination backend must be specified")
+		c.UI.Error(c.Help())
+		return 1
+	}
+
+	config := base.DefaultConfig()
+	if configPath != "" {
+		current, err := base.LoadConfig(configPath)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error loading configuration from %s: %s",
+				configPath, err))
+			return 1
+		}
+		config = config.Merge(current)
+	}
+	config = config.Merge(cliConfig)
+
+	src, err := newStateStore(source, config)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing source state backend: %v", err))
+		return 1
+	}
+	defer closeStateStore(src)
+
+	dst, err := newStateStore(destination, config)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing destination state backend: %v", err))
+		return 1
+	}
+	defer closeStateStore(dst)
+
+	count, err := copyState(src, dst, config.ConsulKeyRoot+"/state/")
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error copying state: %v", err))
+		return 1
+	}
+
+	events, err := copyHistory(src, dst, config.ConsulKeyRoot+"/state/",
+		config.History)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error copying scaling event history: %v", err))
+		return 1
+	}
+
+	acl, err := copyACL(src, dst, config.ConsulKeyRoot+"/acl")
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error copying ACL state: %v", err))
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("Successfully copied %v state object(s) and %v scaling "+
+		"event(s) from %v to %v", count, events, source, destination))
+	if acl {
+		c.UI.Info("Successfully copied the ACL tokens and policies")
+	}
+
+	return 0
+}
+
+// newStateStore initializes the state store for the specified backend.
+func newStateStore(backend string, config *structs.Config) (structs.StateStore, error) {
+	state := *config.State
+	state.Backend = backend
+
+	return client.NewStateStore(&structs.Config{
+		Consul:      config.Consul,
+		ConsulToken: config.ConsulToken,
+		State:       &state,
+	})
+}
+
+// closeStateStore releases any resources held by a state store.
+func closeStateStore(store structs.StateStore) {
+	if closer, ok := store.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// copyState copies all state objects stored under the path prefix from the
+// source to the destination state store, returning the number copied.
+func copyState(src, dst structs.StateStore, prefix string) (int, error) {
+	states, err := src.ListState(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, state := range states {
+		// Write against the index of any existing copy in the destination so
+		// the copy overwrites it rather than being merged as a conflict.
+		existing := &structs.ScalingState{StatePath: state.StatePath}
+		dst.ReadState(existing, false)
+		state.ModifyIndex = existing.ModifyIndex
+
+		if err := dst.PersistState(state); err != nil {
+			return i, err
+		}
+	}
+
+	return len(states), nil
+}
+
+// copyHistory copies the scaling event history of each state object stored
+// under the path prefix from the source to the destination state store,
+// returning the number of events copied. Only events newer than the latest
+// event already held by the destination are copied so that repeating a copy
+// does not duplicate the history.
+func copyHistory(src, dst structs.StateStore, prefix string,
+	retention *structs.History) (int, error) {
+
+	states, err := src.ListState(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, state := range states {
+		path, resourceID := structs.HistoryPath(state.StatePath)
+		if path == "" {
+			continue
+		}
+
+		events, err := resourceEvents(src, path, resourceID)
+		if err != nil {
+			return count, err
+		}
+
+		existing, err := resourceEvents(dst, path, resourceID)
+		if err != nil {
+			return count, err
+		}
+
+		for _, event := range events {
+			if n := len(existing); n > 0 &&
+				!event.Timestamp.After(existing[n-1].Timestamp) {
+				continue
+			}
+
+			if err := dst.AppendEvent(path, event, retention); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// resourceEvents returns the scaling events stored at the history path which
+// belong to the resource, oldest first. Events of other resources whose path
+// shares the prefix are excluded.
+func resourceEvents(store structs.StateStore, path,
+	resourceID string) (events []*structs.ScalingEvent, err error) {
+
+	all, err := store.ListEvents(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range all {
+		if event.ResourceID == resourceID {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// copyACL copies the ACL tokens and policies stored at the path from the
+// source to the destination state store, reporting whether any ACL state
+// existed to be copied.
+func copyACL(src, dst structs.StateStore, path string) (bool, error) {
+	acl := &structs.ACLState{Path: path}
+	if err := src.ReadACL(acl); err != nil {
+		return false, err
+	}
+
+	if !acl.Bootstrapped && len(acl.Tokens) == 0 && len(acl.Policies) == 0 {
+		return false, nil
+	}
+
+	// Write against the index of any existing ACL state in the destination so
+	// the copy overwrites it.
+	existing := &structs.ACLState{Path: path}
+	if err := dst.ReadACL(existing); err != nil {
+		return false, err
+	}
+	acl.ModifyIndex = existing.ModifyIndex
+
+	if err := dst.PersistACL(acl); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,188 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	"github.com/mitchellh/cli"
+)
+
+func TestStateCopyCommand_implements(t *testing.T) {
+	var _ cli.Command = &StateCopyCommand{}
+}
+
+func TestStateCopyCommand_copyState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := client.NewBoltStateStore(filepath.Join(dir, "src.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStateStore(src)
+
+	dst, err := client.NewBoltStateStore(filepath.Join(dir, "dst.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStateStore(dst)
+
+	paths := []string{
+		"replicator/config/state/jobs/example/cache",
+		"replicator/config/state/nodes/example-pool",
+		"replicator/config/other",
+	}
+	for _, path := range paths {
+		if err := src.PersistState(&structs.ScalingState{
+			FailureCount: 1,
+			StatePath:    path,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := copyState(src, dst, "replicator/config/state/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 state objects to be copied but got %v", count)
+	}
+
+	state := &structs.ScalingState{StatePath: paths[1]}
+	dst.ReadState(state, false)
+	if state.FailureCount != 1 {
+		t.Fatalf("expected copied state to have a failure count of 1 but got %v",
+			state.FailureCount)
+	}
+}
+
+func TestStateCopyCommand_copyHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := client.NewBoltStateStore(filepath.Join(dir, "src.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStateStore(src)
+
+	dst, err := client.NewBoltStateStore(filepath.Join(dir, "dst.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStateStore(dst)
+
+	now := time.Now()
+	for i, pool := range []string{"example-pool", "example-pool-2"} {
+		statePath := "replicator/config/state/nodes/" + pool
+		if err := src.PersistState(&structs.ScalingState{StatePath: statePath}); err != nil {
+			t.Fatal(err)
+		}
+
+		path, resourceID := structs.HistoryPath(statePath)
+		for j := 0; j <= i; j++ {
+			if err := src.AppendEvent(path, &structs.ScalingEvent{
+				ResourceID: resourceID,
+				Timestamp:  now.Add(time.Duration(j) * time.Second),
+			}, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	count, err := copyHistory(src, dst, "replicator/config/state/", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 events to be copied but got %v", count)
+	}
+
+	events, err := dst.ListEvents("replicator/config/history/nodes/example-pool-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 copied events but got %v", len(events))
+	}
+
+	// Repeating the copy does not duplicate the history.
+	count, err = copyHistory(src, dst, "replicator/config/state/", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no events to be copied again but got %v", count)
+	}
+}
+
+func TestStateCopyCommand_copyACL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := client.NewBoltStateStore(filepath.Join(dir, "src.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStateStore(src)
+
+	dst, err := client.NewBoltStateStore(filepath.Join(dir, "dst.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStateStore(dst)
+
+	path := "replicator/config/acl"
+
+	// Nothing is copied before ACLs have been bootstrapped.
+	copied, err := copyACL(src, dst, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if copied {
+		t.Fatalf("expected no ACL state to be copied")
+	}
+
+	if err := src.PersistACL(&structs.ACLState{
+		Bootstrapped: true,
+		Path:         path,
+		Tokens: map[string]*structs.ACLToken{
+			"accessor": {AccessorID: "accessor", Name: "Bootstrap Token"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copying twice overwrites the ACL state of the destination.
+	for i := 0; i < 2; i++ {
+		if copied, err = copyACL(src, dst, path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !copied {
+			t.Fatalf("expected the ACL state to be copied")
+		}
+	}
+
+	acl := &structs.ACLState{Path: path}
+	if err := dst.ReadACL(acl); err != nil {
+		t.Fatal(err)
+	}
+	if !acl.Bootstrapped || acl.Tokens["accessor"] == nil {
+		t.Fatalf("expected the copied ACL state to contain the token but got %#v", acl)
+	}
+}
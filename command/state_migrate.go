@@ -0,0 +1,157 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/command/base"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// StateMigrateCommand is a command implementation that upgrades persisted
+// scaling state to the current schema version.
+type StateMigrateCommand struct {
+	Meta
+}
+
+// Help provides the help information for the state migrate command.
+func (c *StateMigrateCommand) Help() string {
+	helpText := `
+Usage: replicator state migrate [options]
+
+  Upgrades every scaling state object stored under the state path of the
+  configured state backend to the current schema version in place. State
+  objects are also upgraded automatically when they are read by Replicator,
+  however this command allows all objects to be upgraded at once. This
+  command is not supported with the raft state backend, whose state objects
+  are only upgraded automatically as they are read.
+
+  General Options:
+
+    -config=<path>
+      The path to either a single config file or a directory of config
+      files used to determine the Consul and state configuration.
+
+    -consul=<address:port>
+      This is the address of the Consul agent. By default, this is
+      localhost:8500.
+
+    -consul-token=<token>
+      The Consul ACL token to use when communicating with an ACL protected
+      Consul cluster.
+
+  Migrate Options:
+
+    -dry-run
+      Report the state objects which require an upgrade without writing
+      any changes.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is provides a brief summary of the state migrate command.
+func (c *StateMigrateCommand) Synopsis() string {
+	return "Upgrade persisted scaling state to the current schema version"
+}
+
+// Run triggers the state migrate command to upgrade all state objects.
+func (c *StateMigrateCommand) Run(args []string) int {
+	var configPath string
+	var dryRun bool
+
+	cliConfig := &structs.Config{}
+
+	flags := c.Meta.FlagSet("state migrate", FlagSetClient)
+	flags.Usage = func() { c.UI.Error(c.Help()) }
+	flags.StringVar(&configPath, "config", "", "")
+	flags.StringVar(&cliConfig.Consul, "consul", "", "")
+	flags.StringVar(&cliConfig.ConsulToken, "consul-token", "", "")
+	flags.BoolVar(&dryRun, "dry-run", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	config := base.DefaultConfig()
+	if configPath != "" {
+		current, err := base.LoadConfig(configPath)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error loading configuration from %s: %s",
+				configPath, err))
+			return 1
+		}
+		config = config.Merge(current)
+	}
+	config = config.Merge(cliConfig)
+
+	store, err := client.NewStateStore(config)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing state backend: %v", err))
+		return 1
+	}
+	defer closeStateStore(store)
+
+	outdated, err := migrateState(store, config.ConsulKeyRoot+"/state/", dryRun)
+	for _, state := range outdated {
+		c.UI.Output(fmt.Sprintf("%v: schema version %v -> %v", state.StatePath,
+			state.SchemaVersion, structs.StateSchemaVersion))
+	}
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error migrating state: %v", err))
+		return 1
+	}
+
+	switch {
+	case len(outdated) == 0:
+		c.UI.Info("All state objects are at the current schema version")
+	case dryRun:
+		c.UI.Info(fmt.Sprintf("%v state object(s) require migration, no changes "+
+			"were made", len(outdated)))
+	default:
+		c.UI.Info(fmt.Sprintf("Successfully migrated %v state object(s)",
+			len(outdated)))
+	}
+
+	return 0
+}
+
+// migrateState upgrades all state objects stored under the path prefix which
+// were written with an outdated schema version. The outdated objects are
+// returned with their original schema version. If dryRun is true no changes
+// are written.
+func migrateState(store structs.StateStore, prefix string,
+	dryRun bool) (outdated []*structs.ScalingState, err error) {
+
+	states, err := store.ListState(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, state := range states {
+		if state.SchemaVersion >= structs.StateSchemaVersion {
+			continue
+		}
+
+		// Documents written before versioning was introduced are version 1.
+		if state.SchemaVersion == 0 {
+			state.SchemaVersion = 1
+		}
+
+		outdated = append(outdated, state)
+		if dryRun {
+			continue
+		}
+
+		// Read the state object to track its index so the upgraded object is
+		// written using check-and-set.
+		current := &structs.ScalingState{StatePath: state.StatePath}
+		store.ReadState(current, false)
+
+		if err = store.PersistState(current); err != nil {
+			return outdated, err
+		}
+	}
+
+	return outdated, nil
+}
@@ -0,0 +1,111 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	"github.com/mitchellh/cli"
+)
+
+// memState is the subset of scaling state retained by memStateStore.
+type memState struct {
+	failureCount  int
+	schemaVersion int
+}
+
+// memStateStore is a minimal in-memory state store used for testing.
+type memStateStore struct {
+	states map[string]memState
+}
+
+func (m *memStateStore) AppendEvent(string, *structs.ScalingEvent, *structs.History) error {
+	return nil
+}
+
+func (m *memStateStore) DeleteState(state *structs.ScalingState) error {
+	delete(m.states, state.StatePath)
+	return nil
+}
+
+func (m *memStateStore) ListEvents(string) ([]*structs.ScalingEvent, error) {
+	return nil, nil
+}
+
+func (m *memStateStore) ListState(prefix string) (states []*structs.ScalingState, err error) {
+	for path, s := range m.states {
+		if strings.HasPrefix(path, prefix) {
+			states = append(states, &structs.ScalingState{
+				FailureCount:  s.failureCount,
+				SchemaVersion: s.schemaVersion,
+				StatePath:     path,
+			})
+		}
+	}
+	return
+}
+
+func (m *memStateStore) PersistACL(*structs.ACLState) error {
+	return nil
+}
+
+func (m *memStateStore) PersistState(state *structs.ScalingState) error {
+	state.SchemaVersion = structs.StateSchemaVersion
+	m.states[state.StatePath] = memState{
+		failureCount:  state.FailureCount,
+		schemaVersion: state.SchemaVersion,
+	}
+	return nil
+}
+
+func (m *memStateStore) ReadACL(*structs.ACLState) error {
+	return nil
+}
+
+func (m *memStateStore) ReadState(state *structs.ScalingState, force bool) {
+	s := m.states[state.StatePath]
+	state.FailureCount = s.failureCount
+	state.SchemaVersion = s.schemaVersion
+}
+
+func TestStateMigrateCommand_implements(t *testing.T) {
+	var _ cli.Command = &StateMigrateCommand{}
+}
+
+func TestStateMigrateCommand_migrateState(t *testing.T) {
+	legacy := "replicator/config/state/nodes/legacy"
+	store := &memStateStore{
+		states: map[string]memState{
+			legacy: {failureCount: 1},
+			"replicator/config/state/nodes/current": {
+				schemaVersion: structs.StateSchemaVersion,
+			},
+		},
+	}
+	prefix := "replicator/config/state/"
+
+	outdated, err := migrateState(store, prefix, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outdated) != 1 || outdated[0].SchemaVersion != 1 {
+		t.Fatalf("expected a single version 1 object but got %v", len(outdated))
+	}
+	if store.states[legacy].schemaVersion != 0 {
+		t.Fatalf("expected dry run not to modify state")
+	}
+
+	if _, err = migrateState(store, prefix, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	migrated := store.states[legacy]
+	if migrated.schemaVersion != structs.StateSchemaVersion || migrated.failureCount != 1 {
+		t.Fatalf("expected state to be migrated in place but got %+v", migrated)
+	}
+
+	outdated, _ = migrateState(store, prefix, true)
+	if len(outdated) != 0 {
+		t.Fatalf("expected no outdated state objects but got %v", len(outdated))
+	}
+}
@@ -0,0 +1,137 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/command/base"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// StatePinCommand is a command implementation that allows operators to pin
+// the scaling state of a resource so that it is retained by garbage
+// collection.
+type StatePinCommand struct {
+	Meta
+}
+
+// Help provides the help information for the state pin command.
+func (c *StatePinCommand) Help() string {
+	helpText := `
+Usage: replicator state pin [options]
+
+  Administratively pins the scaling state of a worker pool or job group.
+  Replicator garbage collects the state of resources which no longer exist
+  once the configured grace period has passed, and resets the state of
+  resources which reappear after being removed. Pinned state is retained
+  indefinitely and is kept when the resource reappears. This command is not
+  supported with the raft state backend.
+
+  General Options:
+
+    -config=<path>
+      The path to either a single config file or a directory of config
+      files used to determine the Consul and state configuration.
+
+    -consul=<address:port>
+      This is the address of the Consul agent. By default, this is
+      localhost:8500.
+
+    -consul-token=<token>
+      The Consul ACL token to use when communicating with an ACL protected
+      Consul cluster.
+
+    -state-path=<key>
+      The full path where the state object for the resource is stored. By
+      default, Replicator stores all state objects at a common base path,
+      replicator/config/state; within this base context, worker pools state
+      is stored at nodes/<pool_name> and jobs at jobs/<job_name>/<group_name>.
+
+  Pin Options:
+
+    -unpin
+      Remove the pin from the state object, allowing it to be garbage
+      collected.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is provides a brief summary of the state pin command.
+func (c *StatePinCommand) Synopsis() string {
+	return "Pin scaling state so it is retained by garbage collection"
+}
+
+// Run triggers the state pin command to update the pinned flag of a state
+// object.
+func (c *StatePinCommand) Run(args []string) int {
+	var configPath, statePath string
+	var unpin bool
+
+	cliConfig := &structs.Config{}
+
+	flags := c.Meta.FlagSet("state pin", FlagSetClient)
+	flags.Usage = func() { c.UI.Error(c.Help()) }
+	flags.StringVar(&configPath, "config", "", "")
+	flags.StringVar(&cliConfig.Consul, "consul", "", "")
+	flags.StringVar(&cliConfig.ConsulToken, "consul-token", "", "")
+	flags.StringVar(&statePath, "state-path", "", "")
+	flags.BoolVar(&unpin, "unpin", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if statePath == "" {
+		c.UI.Error(c.Help())
+		return 1
+	}
+
+	config := base.DefaultConfig()
+	if configPath != "" {
+		current, err := base.LoadConfig(configPath)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error loading configuration from %s: %s",
+				configPath, err))
+			return 1
+		}
+		config = config.Merge(current)
+	}
+	config = config.Merge(cliConfig)
+
+	store, err := client.NewStateStore(config)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing state backend: %v", err))
+		return 1
+	}
+	defer closeStateStore(store)
+
+	verb := "pinned"
+	if unpin {
+		verb = "unpinned"
+	}
+
+	if err := pinState(store, statePath, !unpin); err != nil {
+		c.UI.Error(fmt.Sprintf("Error updating state: %v", err))
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("Successfully %s state at location %s", verb,
+		statePath))
+	return 0
+}
+
+// pinState sets the pinned flag of the state object stored at the path
+// provided.
+func pinState(store structs.StateStore, path string, pinned bool) error {
+	state := &structs.ScalingState{StatePath: path}
+	store.ReadState(state, false)
+
+	if state.LastUpdated.IsZero() {
+		return fmt.Errorf("no state object was found at location %v", path)
+	}
+
+	state.Pinned = pinned
+
+	return store.PersistState(state)
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	"github.com/mitchellh/cli"
+)
+
+func TestStatePinCommand_implements(t *testing.T) {
+	var _ cli.Command = &StatePinCommand{}
+}
+
+func TestStatePinCommand_pinState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStateStore(store)
+
+	path := "replicator/config/state/jobs/example/cache"
+	if err := pinState(store, path, true); err == nil {
+		t.Fatalf("expected an error pinning a missing state object")
+	}
+
+	if err := store.PersistState(&structs.ScalingState{StatePath: path}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pinState(store, path, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state := &structs.ScalingState{StatePath: path}
+	store.ReadState(state, false)
+	if !state.Pinned {
+		t.Fatalf("expected state to be pinned")
+	}
+}
@@ -41,6 +41,41 @@ func Commands(metaPtr *command.Meta) map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"history": func() (cli.Command, error) {
+			return &command.HistoryCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job scale": func() (cli.Command, error) {
+			return &command.JobScaleCommand{
+				Meta: meta,
+			}, nil
+		},
+		"pool scale": func() (cli.Command, error) {
+			return &command.PoolScaleCommand{
+				Meta: meta,
+			}, nil
+		},
+		"recommendations": func() (cli.Command, error) {
+			return &command.RecommendationsCommand{
+				Meta: meta,
+			}, nil
+		},
+		"state copy": func() (cli.Command, error) {
+			return &command.StateCopyCommand{
+				Meta: meta,
+			}, nil
+		},
+		"state migrate": func() (cli.Command, error) {
+			return &command.StateMigrateCommand{
+				Meta: meta,
+			}, nil
+		},
+		"state pin": func() (cli.Command, error) {
+			return &command.StatePinCommand{
+				Meta: meta,
+			}, nil
+		},
 		"version": func() (cli.Command, error) {
 			ver := version.Version
 			rel := version.VersionPrerelease
@@ -1,9 +1,12 @@
 package helper
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
+	"sort"
 	"time"
 
 	"github.com/elsevier-core-engineering/replicator/logging"
@@ -44,6 +47,28 @@ func Min(values ...float64) float64 {
 	return min
 }
 
+// Percentile returns the value at the given percentile (0-100) of a list of
+// floats using the nearest-rank method. An empty list returns zero.
+func Percentile(values []float64, percentile float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
+
 // StringInSlice checks if a given string is in a slice.
 func StringInSlice(a string, list []string) bool {
 	for _, b := range list {
@@ -94,8 +119,9 @@ func HasObjectChanged(objectA, objectB interface{}) (changed bool, err error) {
 // to determine if a node has been registered with a specific worker pool.
 //
 // The method searches by node IP address and if no result is found, will
-// continue polling the node registry for up to 5 minutes.
-func FindNodeByAddress(nodeRegistry *structs.NodeRegistry,
+// continue polling the node registry for up to 5 minutes or until the context
+// is cancelled.
+func FindNodeByAddress(ctx context.Context, nodeRegistry *structs.NodeRegistry,
 	workerPoolName, nodeAddress string) (ok bool) {
 
 	// Setup a ticker to poll the node registry for the specified worker node
@@ -111,6 +137,12 @@ func FindNodeByAddress(nodeRegistry *structs.NodeRegistry,
 
 	for {
 		select {
+		case <-ctx.Done():
+			logging.Warning("core/helper: interrupted while searching the node "+
+				"registry for a node with address %v registered in worker pool %v: "+
+				"%v", nodeAddress, workerPoolName, ctx.Err())
+			return
+
 		case <-timeout.C:
 			logging.Error("core/helper: timeout reached while searching the "+
 				"node registry for a node with address %v registered in worker "+
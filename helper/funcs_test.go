@@ -58,6 +58,32 @@ func TestHelper_Min(t *testing.T) {
 	}
 }
 
+func TestHelper_Percentile(t *testing.T) {
+	values := []float64{15, 20, 35, 40, 50}
+
+	type percentileTest struct {
+		percentile float64
+		expected   float64
+	}
+
+	var percentileTests = []percentileTest{
+		{0, 15}, {5, 15}, {30, 20}, {40, 20}, {50, 35}, {100, 50},
+	}
+
+	for _, test := range percentileTests {
+		actual := Percentile(values, test.percentile)
+
+		if actual != test.expected {
+			t.Fatalf("expected percentile %v to be %v got %v", test.percentile,
+				test.expected, actual)
+		}
+	}
+
+	if actual := Percentile([]float64{}, 95); actual != 0 {
+		t.Fatalf("expected 0 got %v", actual)
+	}
+}
+
 func TestHelper_HasObjectChanged(t *testing.T) {
 	policyA := &structs.GroupScalingPolicy{
 		GroupName:   "core-engineering",
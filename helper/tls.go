@@ -0,0 +1,146 @@
+package helper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// TLSConfigurator provides the TLS configuration of the HTTP API and RPC
+// listener. The certificates are loaded when the configurator is created and
+// may be reloaded while the agent is running; new connections use the most
+// recently loaded certificates.
+type TLSConfigurator struct {
+	lock           sync.RWMutex
+	caPool         *x509.CertPool
+	cert           *tls.Certificate
+	verifyIncoming bool
+}
+
+// NewTLSConfigurator is used to construct a new TLS configurator, loading the
+// certificates referenced by the TLS configuration.
+func NewTLSConfigurator(config *structs.TLS) (*TLSConfigurator, error) {
+	c := &TLSConfigurator{}
+	if err := c.Load(config); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load loads the certificates referenced by the TLS configuration. The
+// previously loaded certificates are retained if any certificate cannot be
+// loaded.
+func (c *TLSConfigurator) Load(config *structs.TLS) error {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return fmt.Errorf("both cert_file and key_file must be specified to " +
+			"enable TLS")
+	}
+
+	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("unable to load the TLS certificate %v: %v",
+			config.CertFile, err)
+	}
+
+	var pool *x509.CertPool
+	if config.CAFile != "" {
+		data, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return fmt.Errorf("unable to read the CA certificate %v: %v",
+				config.CAFile, err)
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates were found in the CA certificate %v",
+				config.CAFile)
+		}
+	} else if config.VerifyIncoming {
+		return fmt.Errorf("ca_file must be specified to verify incoming " +
+			"connections")
+	}
+
+	c.lock.Lock()
+	c.caPool = pool
+	c.cert = &cert
+	c.verifyIncoming = config.VerifyIncoming
+	c.lock.Unlock()
+
+	return nil
+}
+
+// IncomingConfig returns the TLS configuration used by the HTTP API and RPC
+// listener. Clients are required to present a certificate signed by the CA
+// when incoming verification is enabled.
+func (c *TLSConfigurator) IncomingConfig() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.lock.RLock()
+			defer c.lock.RUnlock()
+
+			config := &tls.Config{
+				Certificates: []tls.Certificate{*c.cert},
+				ClientCAs:    c.caPool,
+				MinVersion:   tls.VersionTLS12,
+			}
+			if c.verifyIncoming {
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// OutgoingRPCConfig returns the TLS configuration used to connect to the RPC
+// listener of another server. The agent presents its own certificate and the
+// certificate of the server is verified against the CA, or the system roots
+// if no CA is configured. Servers are addressed by their advertised IP
+// address so the host name of the certificate is not verified.
+func (c *TLSConfigurator) OutgoingRPCConfig() *tls.Config {
+	c.lock.RLock()
+	cert, pool := c.cert, c.caPool
+	c.lock.RUnlock()
+
+	return &tls.Config{
+		Certificates:       []tls.Certificate{*cert},
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS12,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyChain(rawCerts, pool)
+		},
+	}
+}
+
+// verifyChain verifies the certificate chain presented by a server against
+// the CA pool without verifying the host name.
+func verifyChain(rawCerts [][]byte, pool *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("no certificate was presented by the server")
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("unable to parse the server certificate: %v", err)
+		}
+		certs[i] = cert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		Roots:         pool,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
@@ -0,0 +1,162 @@
+package helper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// writeTestCert generates a certificate signed by the parent, or self-signed
+// if no parent is provided, and writes the certificate and key to the
+// directory.
+func writeTestCert(t *testing.T, dir, name string, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth,
+			x509.ExtKeyUsageClientAuth},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent,
+		&key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cert, key
+}
+
+func TestHelper_TLSConfigurator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator-tls")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	writeTestCert(t, dir, "server", ca, caKey)
+	writeTestCert(t, dir, "rogue", nil, nil)
+
+	config := &structs.TLS{
+		CertFile:       filepath.Join(dir, "server.pem"),
+		KeyFile:        filepath.Join(dir, "server-key.pem"),
+		VerifyIncoming: true,
+	}
+
+	if _, err := NewTLSConfigurator(config); err == nil {
+		t.Fatalf("expected an error verifying incoming connections without a CA")
+	}
+
+	config.CAFile = filepath.Join(dir, "ca.pem")
+	server, err := NewTLSConfigurator(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A server presenting a certificate signed by the CA is accepted.
+	if err := handshake(server.IncomingConfig(), server.OutgoingRPCConfig()); err != nil {
+		t.Fatalf("unexpected handshake error: %v", err)
+	}
+
+	// A client presenting a certificate which is not signed by the CA is
+	// rejected when incoming connections are verified.
+	rogue, err := NewTLSConfigurator(&structs.TLS{
+		CAFile:   filepath.Join(dir, "ca.pem"),
+		CertFile: filepath.Join(dir, "rogue.pem"),
+		KeyFile:  filepath.Join(dir, "rogue-key.pem"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := handshake(server.IncomingConfig(), rogue.OutgoingRPCConfig()); err == nil {
+		t.Fatalf("expected the handshake of an untrusted client to fail")
+	}
+
+	// Reloading an invalid certificate retains the loaded certificates.
+	if err := server.Load(&structs.TLS{CertFile: config.CAFile,
+		KeyFile: filepath.Join(dir, "server-key.pem")}); err == nil {
+		t.Fatalf("expected an error loading a mismatched key")
+	}
+
+	if err := handshake(server.IncomingConfig(), server.OutgoingRPCConfig()); err != nil {
+		t.Fatalf("unexpected handshake error after a failed reload: %v", err)
+	}
+}
+
+// handshake performs a TLS handshake between a server and client using the
+// configurations provided, returning the error of the client.
+func handshake(serverConfig, clientConfig *tls.Config) error {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	go func() {
+		conn := tls.Server(serverConn, serverConfig)
+		if err := conn.Handshake(); err != nil {
+			serverConn.Close()
+			return
+		}
+		// Confirm the handshake to the client once the server has accepted it.
+		conn.Write([]byte{0})
+	}()
+
+	conn := tls.Client(clientConn, clientConfig)
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+
+	// With TLS 1.3 the client completes the handshake before the server has
+	// verified its certificate, so wait for the server to confirm.
+	_, err := conn.Read(make([]byte, 1))
+	return err
+}
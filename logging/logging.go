@@ -30,6 +30,12 @@ func SetLevel(level string) {
 	log.SetLevel(lvl)
 }
 
+// ValidateLevel returns an error if the log level is not recognized.
+func ValidateLevel(level string) error {
+	_, err := log.ParseLevel(level)
+	return err
+}
+
 // Debug logs a message with severity DEBUG.
 func Debug(format string, v ...interface{}) {
 	log.Debug(fmt.Sprintf(format, v...))
@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+)
+
+// Health describes the outcome of the notifications sent by a notifier so
+// that failing notification backends can be reported by the agent.
+type Health struct {
+	// LastAttempt is the time the last notification was sent.
+	LastAttempt time.Time
+
+	// LastError is the error returned by the last notification, which is
+	// empty if the notification was sent successfully.
+	LastError string
+
+	// LastSuccess is the time the last notification was successfully sent.
+	LastSuccess time.Time
+}
+
+// healthTracker records the outcome of each notification sent by a notifier.
+type healthTracker struct {
+	lock   sync.Mutex
+	health Health
+}
+
+// record updates the health following an attempt to send a notification.
+func (h *healthTracker) record(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.health.LastAttempt = time.Now()
+	if err != nil {
+		h.health.LastError = err.Error()
+		return
+	}
+
+	h.health.LastError = ""
+	h.health.LastSuccess = h.health.LastAttempt
+}
+
+// Health returns the outcome of the notifications sent by the notifier.
+func (h *healthTracker) Health() Health {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.health
+}
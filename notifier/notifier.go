@@ -18,6 +18,7 @@ type FailureMessage struct {
 // Notifier is the interface to the Notifiers functions. All notifers are
 // expected to implament this set of functions.
 type Notifier interface {
+	Health() Health
 	Name() string
 	SendNotification(FailureMessage)
 }
@@ -11,6 +11,7 @@ import (
 // OpsGenieProvider contains the required configuration to send OpsGenie
 // notifications.
 type OpsGenieProvider struct {
+	healthTracker
 	config map[string]string
 }
 
@@ -62,8 +63,11 @@ func (og *OpsGenieProvider) SendNotification(message FailureMessage) {
 	resp, err := alertCli.Create(request)
 	if err != nil {
 		logging.Error("notifier/opsgenie: an error occurred creating the OpsGenie event: %v", err)
+		og.record(err)
 		return
 	}
 
+	og.record(nil)
+
 	logging.Info("notifier/opsgenie: incident %s has been triggerd", resp.RequestID)
 }
@@ -10,6 +10,7 @@ import (
 // PagerDutyProvider contains the required configuration to send PagerDuty
 // notifications.
 type PagerDutyProvider struct {
+	healthTracker
 	config map[string]string
 }
 
@@ -50,8 +51,11 @@ func (p *PagerDutyProvider) SendNotification(message FailureMessage) {
 	resp, err := pagerduty.CreateEvent(event)
 	if err != nil {
 		logging.Error("notifier/pagerduty: an error occurred creating the PagerDuty event: %v", err)
+		p.record(err)
 		return
 	}
 
+	p.record(nil)
+
 	logging.Info("notifier/pagerduty: incident %s has been triggerd", resp.IncidentKey)
 }
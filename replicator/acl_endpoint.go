@@ -0,0 +1,328 @@
+package replicator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad/helper/uuid"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// ACL endpoint is used to bootstrap the ACL system and manage the tokens and
+// policies which authorize requests to the HTTP API and RPC endpoints. Tokens
+// are resolved by the local agent while changes are forwarded to the leader.
+// Managing tokens and policies requires a management token when ACLs are
+// enabled.
+type ACL struct {
+	srv *Server
+}
+
+// Bootstrap creates the initial management token. No token is returned if
+// the ACL system has already been bootstrapped.
+func (a *ACL) Bootstrap(args interface{}, reply *structs.ACLBootstrapResponse) error {
+	if done, err := a.srv.forward("ACL.Bootstrap", args, reply); done {
+		return err
+	}
+
+	return a.srv.updateACL(func(state *structs.ACLState) (bool, error) {
+		if state.Bootstrapped {
+			return false, nil
+		}
+
+		token := &structs.ACLToken{
+			AccessorID: uuid.Generate(),
+			SecretID:   uuid.Generate(),
+			Name:       "Bootstrap Token",
+			Type:       structs.ACLTokenManagement,
+			CreateTime: time.Now(),
+		}
+
+		state.Bootstrapped = true
+		state.Tokens[token.AccessorID] = token
+		reply.Token = token
+
+		logging.Info("core/acl: the ACL system has been bootstrapped with "+
+			"management token %v", token.AccessorID)
+		return true, nil
+	})
+}
+
+// Resolve returns the token matching the secret ID and its policies.
+func (a *ACL) Resolve(args *structs.ACLResolveRequest, reply *structs.ACLResolveResponse) error {
+	state, err := a.srv.readACL()
+	if err != nil {
+		return err
+	}
+
+	reply.Token, reply.Policies = state.Resolve(args.SecretID)
+	return nil
+}
+
+// ListTokens returns every token sorted by name, without their secret IDs.
+func (a *ACL) ListTokens(args *structs.QueryOptions, reply *structs.ACLTokenListResponse) error {
+	if _, err := a.srv.authorizeManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	state, err := a.srv.readACL()
+	if err != nil {
+		return err
+	}
+
+	reply.Tokens = make([]*structs.ACLToken, 0, len(state.Tokens))
+	for _, token := range state.SortedTokens() {
+		reply.Tokens = append(reply.Tokens, token.Redacted())
+	}
+	return nil
+}
+
+// GetToken returns the token with the accessor ID provided.
+func (a *ACL) GetToken(args *structs.ACLTokenRequest, reply *structs.ACLTokenResponse) error {
+	if _, err := a.srv.authorizeManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	state, err := a.srv.readACL()
+	if err != nil {
+		return err
+	}
+
+	reply.Token = state.Tokens[args.AccessorID]
+	return nil
+}
+
+// UpsertToken creates a token, generating its accessor and secret IDs, or
+// updates the name and policies of the existing token with the accessor ID
+// of the token provided. No token is returned if the token to update does not
+// exist.
+func (a *ACL) UpsertToken(args *structs.ACLTokenRequest, reply *structs.ACLTokenResponse) error {
+	if done, err := a.srv.forward("ACL.UpsertToken", args, reply); done {
+		return err
+	}
+
+	if _, err := a.srv.authorizeManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	if args.Token == nil {
+		return fmt.Errorf("missing token")
+	}
+
+	return a.srv.updateACL(func(state *structs.ACLState) (bool, error) {
+		token := *args.Token
+		if err := token.Validate(state); err != nil {
+			return false, err
+		}
+
+		if token.AccessorID == "" {
+			token.AccessorID = uuid.Generate()
+			token.SecretID = uuid.Generate()
+			token.CreateTime = time.Now()
+		} else if existing, ok := state.Tokens[token.AccessorID]; ok {
+			token.SecretID = existing.SecretID
+			token.CreateTime = existing.CreateTime
+		} else {
+			return false, nil
+		}
+
+		state.Tokens[token.AccessorID] = &token
+		reply.Token = &token
+		return true, nil
+	})
+}
+
+// DeleteToken removes the token with the accessor ID provided.
+func (a *ACL) DeleteToken(args *structs.ACLTokenRequest, reply *structs.ACLTokenResponse) error {
+	if done, err := a.srv.forward("ACL.DeleteToken", args, reply); done {
+		return err
+	}
+
+	if _, err := a.srv.authorizeManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	return a.srv.updateACL(func(state *structs.ACLState) (bool, error) {
+		reply.Token = state.Tokens[args.AccessorID]
+		delete(state.Tokens, args.AccessorID)
+		return reply.Token != nil, nil
+	})
+}
+
+// ListPolicies returns every policy sorted by name.
+func (a *ACL) ListPolicies(args *structs.QueryOptions, reply *structs.ACLPolicyListResponse) error {
+	if _, err := a.srv.authorizeManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	state, err := a.srv.readACL()
+	if err != nil {
+		return err
+	}
+
+	reply.Policies = state.SortedPolicies()
+	return nil
+}
+
+// GetPolicy returns the policy with the name provided.
+func (a *ACL) GetPolicy(args *structs.ACLPolicyRequest, reply *structs.ACLPolicyResponse) error {
+	if _, err := a.srv.authorizeManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	state, err := a.srv.readACL()
+	if err != nil {
+		return err
+	}
+
+	reply.Policy = state.Policies[args.Name]
+	return nil
+}
+
+// UpsertPolicy creates or replaces a policy.
+func (a *ACL) UpsertPolicy(args *structs.ACLPolicyRequest, reply *structs.ACLPolicyResponse) error {
+	if done, err := a.srv.forward("ACL.UpsertPolicy", args, reply); done {
+		return err
+	}
+
+	if _, err := a.srv.authorizeManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	if args.Policy == nil {
+		return fmt.Errorf("missing policy")
+	}
+
+	return a.srv.updateACL(func(state *structs.ACLState) (bool, error) {
+		policy := *args.Policy
+		if err := policy.Validate(); err != nil {
+			return false, err
+		}
+
+		policy.ModifyTime = time.Now()
+		state.Policies[policy.Name] = &policy
+		reply.Policy = &policy
+		return true, nil
+	})
+}
+
+// DeletePolicy removes the policy with the name provided. Tokens referencing
+// the policy are no longer granted its capabilities.
+func (a *ACL) DeletePolicy(args *structs.ACLPolicyRequest, reply *structs.ACLPolicyResponse) error {
+	if done, err := a.srv.forward("ACL.DeletePolicy", args, reply); done {
+		return err
+	}
+
+	if _, err := a.srv.authorizeManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	return a.srv.updateACL(func(state *structs.ACLState) (bool, error) {
+		reply.Policy = state.Policies[args.Name]
+		delete(state.Policies, args.Name)
+		return reply.Policy != nil, nil
+	})
+}
+
+// authorize checks the RPC request is granted the capability on the resource
+// by the ACL token with the secret ID provided, returning the resolved token.
+// Every request is permitted and no token is returned when ACLs are disabled.
+// Requests without a token are authorized by the anonymous policy.
+func (s *Server) authorize(secretID, resource, capability string) (*structs.ACLToken, error) {
+	token, policies, enabled, err := s.resolveToken(secretID)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	if !token.Allowed(policies, resource, capability) {
+		return nil, structs.ErrPermissionDenied
+	}
+	return token, nil
+}
+
+// authorizeManagement checks the RPC request presents a management token
+// when ACLs are enabled.
+func (s *Server) authorizeManagement(secretID string) (*structs.ACLToken, error) {
+	token, _, enabled, err := s.resolveToken(secretID)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	if token == nil || token.Type != structs.ACLTokenManagement {
+		return nil, structs.ErrPermissionDenied
+	}
+	return token, nil
+}
+
+// aclActor returns the operator recorded against an administrative change
+// made with the ACL token. When ACLs are enabled the change is attributed to
+// the token rather than the actor supplied with the request.
+func (s *Server) aclActor(token *structs.ACLToken, actor string) string {
+	if acl := s.Config().ACL; acl == nil || !acl.Enabled {
+		return actor
+	}
+	return token.Actor()
+}
+
+// resolveToken resolves the ACL token with the secret ID provided and its
+// policies, and reports whether ACLs are enabled. An error is returned if a
+// secret ID is provided which does not match a token.
+func (s *Server) resolveToken(secretID string) (*structs.ACLToken,
+	[]*structs.ACLPolicy, bool, error) {
+
+	if acl := s.Config().ACL; acl == nil || !acl.Enabled {
+		return nil, nil, false, nil
+	}
+
+	state, err := s.readACL()
+	if err != nil {
+		return nil, nil, true, err
+	}
+
+	token, policies := state.Resolve(secretID)
+	if secretID != "" && token == nil {
+		return nil, nil, true, structs.ErrTokenNotFound
+	}
+	return token, policies, true, nil
+}
+
+// readACL reads the ACL tokens and policies from the state backend.
+func (s *Server) readACL() (*structs.ACLState, error) {
+	if s.Config().StateStore == nil {
+		return nil, fmt.Errorf("no state backend is available to store ACLs")
+	}
+
+	state := &structs.ACLState{Path: s.Config().ConsulKeyRoot + "/acl"}
+	if err := s.Config().StateStore.ReadACL(state); err != nil {
+		return nil, err
+	}
+
+	if state.Policies == nil {
+		state.Policies = make(map[string]*structs.ACLPolicy)
+	}
+	if state.Tokens == nil {
+		state.Tokens = make(map[string]*structs.ACLToken)
+	}
+
+	return state, nil
+}
+
+// updateACL applies an update to the ACL tokens and policies and persists
+// the result if the update reports a change.
+func (s *Server) updateACL(update func(*structs.ACLState) (bool, error)) error {
+	s.aclLock.Lock()
+	defer s.aclLock.Unlock()
+
+	state, err := s.readACL()
+	if err != nil {
+		return err
+	}
+
+	changed, err := update(state)
+	if err != nil || !changed {
+		return err
+	}
+
+	return s.Config().StateStore.PersistACL(state)
+}
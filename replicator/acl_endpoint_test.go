@@ -0,0 +1,342 @@
+package replicator
+
+import (
+	"context"
+	"io/ioutil"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestACL_BootstrapAndResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	s := &Server{
+		candidate: &LeaderCandidate{leader: true},
+		config: &structs.Config{
+			ConsulKeyRoot: "replicator/config",
+			StateStore:    store,
+		},
+		rpcServer: rpc.NewServer(),
+	}
+	s.rpcServer.Register(&ACL{s})
+
+	var bootstrap structs.ACLBootstrapResponse
+	if err := s.RPC("ACL.Bootstrap", nil, &bootstrap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bootstrap.Token == nil || bootstrap.Token.Type != structs.ACLTokenManagement {
+		t.Fatalf("expected a management token but got %+v", bootstrap.Token)
+	}
+
+	// The ACL system can only be bootstrapped once.
+	var again structs.ACLBootstrapResponse
+	if err := s.RPC("ACL.Bootstrap", nil, &again); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again.Token != nil {
+		t.Fatalf("expected no token bootstrapping a second time")
+	}
+
+	var policy structs.ACLPolicyResponse
+	if err := s.RPC("ACL.UpsertPolicy", &structs.ACLPolicyRequest{
+		Policy: &structs.ACLPolicy{
+			Name: "readonly",
+			Rules: map[string]string{
+				structs.ACLResourceJob:  structs.ACLCapabilityRead,
+				structs.ACLResourcePool: structs.ACLCapabilityRead,
+			},
+		},
+	}, &policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A client token must reference existing policies.
+	var token structs.ACLTokenResponse
+	err = s.RPC("ACL.UpsertToken", &structs.ACLTokenRequest{
+		Token: &structs.ACLToken{
+			Name:     "ci",
+			Type:     structs.ACLTokenClient,
+			Policies: []string{"missing"},
+		},
+	}, &token)
+	if err == nil {
+		t.Fatalf("expected an error creating a token with a missing policy")
+	}
+
+	if err := s.RPC("ACL.UpsertToken", &structs.ACLTokenRequest{
+		Token: &structs.ACLToken{
+			Name:     "ci",
+			Type:     structs.ACLTokenClient,
+			Policies: []string{"readonly"},
+		},
+	}, &token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.Token == nil || token.Token.SecretID == "" || token.Token.AccessorID == "" {
+		t.Fatalf("expected a token with generated IDs but got %+v", token.Token)
+	}
+
+	var resolved structs.ACLResolveResponse
+	if err := s.RPC("ACL.Resolve", &structs.ACLResolveRequest{
+		SecretID: token.Token.SecretID,
+	}, &resolved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Token == nil || resolved.Token.Name != "ci" || len(resolved.Policies) != 1 {
+		t.Fatalf("expected the ci token and its policy but got %+v", resolved)
+	}
+	if !resolved.Token.Allowed(resolved.Policies, structs.ACLResourceJob, structs.ACLCapabilityRead) ||
+		resolved.Token.Allowed(resolved.Policies, structs.ACLResourceJob, structs.ACLCapabilityWrite) {
+		t.Fatalf("expected the ci token to be granted read access to jobs only")
+	}
+
+	var list structs.ACLTokenListResponse
+	if err := s.RPC("ACL.ListTokens", nil, &list); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Tokens) != 2 || list.Tokens[0].Name != "Bootstrap Token" ||
+		list.Tokens[0].SecretID != "" {
+		t.Fatalf("expected two redacted tokens but got %+v", list.Tokens)
+	}
+
+	// A deleted token no longer resolves.
+	var deleted structs.ACLTokenResponse
+	if err := s.RPC("ACL.DeleteToken", &structs.ACLTokenRequest{
+		AccessorID: token.Token.AccessorID,
+	}, &deleted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resolved = structs.ACLResolveResponse{}
+	if err := s.RPC("ACL.Resolve", &structs.ACLResolveRequest{
+		SecretID: token.Token.SecretID,
+	}, &resolved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Token != nil {
+		t.Fatalf("expected the deleted token not to resolve")
+	}
+}
+
+func TestACL_RPCEnforcement(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	s := &Server{
+		candidate: &LeaderCandidate{leader: true},
+		config: &structs.Config{
+			ACL:           &structs.ACL{Enabled: true},
+			ConsulKeyRoot: "replicator/config",
+			StateStore:    store,
+		},
+		nodeRegistry: structs.NewNodeRegistry(),
+		rpcServer:    rpc.NewServer(),
+	}
+	s.rpcServer.Register(&ACL{s})
+	s.rpcServer.Register(&Failsafe{s})
+	s.rpcServer.Register(&Scaling{s})
+
+	// Scaling operations are refused once authorized as leadership has not
+	// been confirmed.
+	s.leaderCtx, s.leaderCancel = context.WithCancel(context.Background())
+	s.leaderCancel()
+
+	var bootstrap structs.ACLBootstrapResponse
+	if err := s.RPC("ACL.Bootstrap", nil, &bootstrap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	management := bootstrap.Token.SecretID
+
+	// Tokens and policies cannot be read or managed without a management
+	// token.
+	var list structs.ACLTokenListResponse
+	err = s.RPC("ACL.ListTokens", &structs.QueryOptions{}, &list)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission to be denied listing tokens but got %v", err)
+	}
+
+	var token structs.ACLTokenResponse
+	err = s.RPC("ACL.GetToken", &structs.ACLTokenRequest{
+		AccessorID: bootstrap.Token.AccessorID,
+	}, &token)
+	if err == nil || token.Token != nil {
+		t.Fatalf("expected permission to be denied reading the management token")
+	}
+
+	err = s.RPC("ACL.ListTokens", &structs.QueryOptions{AuthToken: "unknown"}, &list)
+	if err == nil || err.Error() != structs.ErrTokenNotFound.Error() {
+		t.Fatalf("expected an unknown token to be rejected but got %v", err)
+	}
+
+	var policy structs.ACLPolicyResponse
+	args := &structs.ACLPolicyRequest{
+		Policy: &structs.ACLPolicy{
+			Name:  "operator",
+			Rules: map[string]string{structs.ACLResourcePool: structs.ACLCapabilityWrite},
+		},
+	}
+	args.AuthToken = management
+	if err := s.RPC("ACL.UpsertPolicy", args, &policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tokenArgs := &structs.ACLTokenRequest{
+		Token: &structs.ACLToken{
+			Name:     "operator",
+			Type:     structs.ACLTokenClient,
+			Policies: []string{"operator"},
+		},
+	}
+	tokenArgs.AuthToken = management
+	if err := s.RPC("ACL.UpsertToken", tokenArgs, &token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	operator := token.Token.SecretID
+
+	// A client token cannot manage tokens.
+	tokenArgs.AuthToken = operator
+	err = s.RPC("ACL.UpsertToken", tokenArgs, &structs.ACLTokenResponse{})
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission to be denied creating a token but got %v", err)
+	}
+
+	// Scaling and failsafe requests are authorized by the policies of the
+	// token.
+	count := 1
+	scale := &structs.ScaleRequest{Pool: "default", Count: &count}
+	err = s.RPC("Scaling.Pool", scale, &structs.ScaleResponse{})
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission to be denied scaling without a token but got %v", err)
+	}
+
+	scale.AuthToken = operator
+	err = s.RPC("Scaling.Pool", scale, &structs.ScaleResponse{})
+	if err == nil || err.Error() == structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected the operator token to be permitted to scale but got %v", err)
+	}
+
+	failsafe := &structs.FailsafeRequest{ResourceType: ClusterType,
+		ResourceID: "default", Enable: true}
+	failsafe.AuthToken = operator
+	err = s.RPC("Failsafe.Set", failsafe, &structs.FailsafeResponse{})
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission to be denied enabling failsafe but got %v", err)
+	}
+	// Read requests are authorized by the policies of the token.
+	s.rpcServer.Register(&Agent{s})
+	s.rpcServer.Register(&Event{s})
+	s.rpcServer.Register(&History{s})
+	s.rpcServer.Register(&Jobs{s})
+	s.rpcServer.Register(&Pools{s})
+	s.rpcServer.Register(&Recommendations{s})
+
+	denied := []struct {
+		method string
+		args   interface{}
+		reply  interface{}
+	}{
+		{"Agent.Self", &structs.QueryOptions{AuthToken: operator}, &structs.AgentSelfResponse{}},
+		{"Event.Index", &structs.EventIndexRequest{}, &structs.EventIndexResponse{}},
+		{"History.List", &structs.HistoryRequest{Pool: "default"}, &structs.HistoryListResponse{}},
+		{"Jobs.Get", &structs.JobRequest{JobID: "example"}, &structs.JobListResponse{}},
+		{"Jobs.List", &structs.QueryOptions{AuthToken: operator}, &structs.JobListResponse{}},
+		{"Pools.Get", &structs.PoolRequest{Name: "default"}, &structs.PoolListResponse{}},
+		{"Pools.List", &structs.QueryOptions{}, &structs.PoolListResponse{}},
+		{"Recommendations.List", &structs.QueryOptions{AuthToken: operator}, &structs.RecommendationListResponse{}},
+	}
+	for _, c := range denied {
+		err = s.RPC(c.method, c.args, c.reply)
+		if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+			t.Fatalf("expected permission to be denied for %v but got %v", c.method, err)
+		}
+	}
+
+	if err := s.RPC("Pools.List", &structs.QueryOptions{AuthToken: operator},
+		&structs.PoolListResponse{}); err != nil {
+		t.Fatalf("expected the operator token to be permitted to list pools but got %v", err)
+	}
+}
+
+func TestACL_RPCActor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	s := &Server{
+		candidate: &LeaderCandidate{leader: true},
+		config: &structs.Config{
+			ACL:           &structs.ACL{Enabled: true},
+			ConsulKeyRoot: "replicator/config",
+			Notification:  &structs.Notification{},
+			StateStore:    store,
+		},
+		rpcServer: rpc.NewServer(),
+	}
+	s.rpcServer.Register(&ACL{s})
+	s.rpcServer.Register(&Failsafe{s})
+
+	var bootstrap structs.ACLBootstrapResponse
+	if err := s.RPC("ACL.Bootstrap", nil, &bootstrap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path, err := failsafeStatePath(s.config.ConsulKeyRoot, ClusterType, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PersistState(&structs.ScalingState{
+		LastUpdated: time.Now(),
+		StatePath:   path,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The change is attributed to the token rather than the actor supplied
+	// with the request.
+	args := &structs.FailsafeRequest{ResourceType: ClusterType,
+		ResourceID: "default", Enable: true, Actor: "api:127.0.0.1"}
+	args.AuthToken = bootstrap.Token.SecretID
+	if err := s.RPC("Failsafe.Set", args, &structs.FailsafeResponse{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state := &structs.ScalingState{StatePath: path}
+	store.ReadState(state, false)
+	if expected := bootstrap.Token.Actor(); state.FailsafeChangedBy != expected {
+		t.Fatalf("expected failsafe to be changed by %q but got %q", expected,
+			state.FailsafeChangedBy)
+	}
+}
@@ -0,0 +1,244 @@
+package replicator
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	"github.com/elsevier-core-engineering/replicator/version"
+)
+
+// watcherStallThreshold is the time after which a watcher which has not
+// received a response from the Nomad API is considered wedged. Blocking
+// queries against the Nomad API return after at most five minutes so a
+// healthy watcher polls well within this threshold.
+const watcherStallThreshold = 10 * time.Minute
+
+// Agent endpoint is used to query the health and status of the local agent.
+// Requests are never forwarded to the leader so that each agent reports its
+// own health.
+type Agent struct {
+	srv *Server
+}
+
+// Health runs the health checks of the agent.
+func (a *Agent) Health(args interface{}, reply *structs.AgentHealthResponse) error {
+	*reply = *a.srv.agentHealth(time.Now())
+	return nil
+}
+
+// Self returns the status of the agent, including the result of the health
+// checks.
+func (a *Agent) Self(args *structs.QueryOptions, reply *structs.AgentSelfResponse) error {
+	s := a.srv
+
+	if _, err := s.authorize(args.AuthToken, structs.ACLResourceOperator,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	reply.Name = hostname
+	reply.Version = version.Get()
+	reply.Leader = s.candidate.isLeader()
+	reply.ClusterScalingEnabled = !s.Config().ClusterScalingDisable
+	reply.JobScalingEnabled = !s.Config().JobScalingDisable
+	reply.StateBackend = stateBackend(s.Config())
+
+	if s.rpcAdvertise != nil {
+		reply.RPCAddr = s.rpcAdvertise.String()
+	}
+
+	var leader structs.LeaderResponse
+	if err := (&Status{s}).Leader(nil, &leader); err == nil {
+		reply.LeaderAddr = leader.RPCAddr
+	}
+
+	reply.Pools = make([]string, 0)
+	s.nodeRegistry.Lock.RLock()
+	for name := range s.nodeRegistry.WorkerPools {
+		reply.Pools = append(reply.Pools, name)
+	}
+	s.nodeRegistry.Lock.RUnlock()
+	sort.Strings(reply.Pools)
+
+	reply.Watchers = s.watcherStatus()
+	reply.Notifiers = notifierStatus(s.Config())
+	reply.Health = s.agentHealth(time.Now())
+
+	return nil
+}
+
+// agentHealth checks the connectivity of the agent to Nomad and Consul, the
+// progress of the watchers, the leadership of the cluster and the outcome of
+// the notifications sent by each notifier.
+func (s *Server) agentHealth(now time.Time) *structs.AgentHealthResponse {
+	var checks []*structs.HealthCheck
+	check := func(name, status, output string, args ...interface{}) {
+		checks = append(checks, &structs.HealthCheck{
+			Name:   name,
+			Status: status,
+			Output: fmt.Sprintf(output, args...),
+		})
+	}
+
+	if leader, err := s.Config().NomadClient.Leader(); err != nil {
+		check("nomad", structs.HealthCritical, "unable to reach Nomad: %v", err)
+	} else {
+		check("nomad", structs.HealthPassing, "Nomad leader is %v", leader)
+	}
+
+	// Consul connectivity is only critical when Consul is used for leader
+	// election or state storage.
+	if leader, err := s.Config().ConsulClient.Leader(); err != nil {
+		status := structs.HealthWarning
+		if s.consulRequired() {
+			status = structs.HealthCritical
+		}
+		check("consul", status, "unable to reach Consul: %v", err)
+	} else {
+		check("consul", structs.HealthPassing, "Consul leader is %v", leader)
+	}
+
+	var leader structs.LeaderResponse
+	if err := (&Status{s}).Leader(nil, &leader); err != nil {
+		check("leader", structs.HealthWarning, "unable to identify the leader: %v", err)
+	} else if leader.RPCAddr == "" {
+		check("leader", structs.HealthWarning, "no Replicator leader is elected")
+	} else if s.candidate.isLeader() {
+		check("leader", structs.HealthPassing, "this agent is the leader")
+	} else {
+		check("leader", structs.HealthPassing, "the leader is %v", leader.RPCAddr)
+	}
+
+	s.subsystemLock.Lock()
+	started := make(map[string]time.Time)
+	for _, name := range []string{subsystemJobWatcher, subsystemNodeWatcher} {
+		if sub, ok := s.subsystems[name]; ok {
+			started[name] = sub.started
+		}
+	}
+	s.subsystemLock.Unlock()
+
+	for _, watcher := range s.watcherStatus() {
+		if !watcher.Running {
+			continue
+		}
+
+		// A watcher which has not polled since it was started is measured from
+		// the time it was started.
+		last := watcher.LastPoll
+		if last.Before(started[watcher.Name]) {
+			last = started[watcher.Name]
+		}
+
+		if since := now.Sub(last); since > watcherStallThreshold {
+			check(watcher.Name, structs.HealthCritical, "no response has been "+
+				"received from the Nomad API for %v", since.Truncate(time.Second))
+		} else {
+			check(watcher.Name, structs.HealthPassing, "last index %v",
+				watcher.LastIndex)
+		}
+	}
+
+	for _, n := range notifierStatus(s.Config()) {
+		name := "notifier:" + n.Name
+		if n.LastError != "" {
+			check(name, structs.HealthWarning, "the last notification failed: %v",
+				n.LastError)
+		} else {
+			check(name, structs.HealthPassing, "no failed notifications")
+		}
+	}
+
+	health := &structs.AgentHealthResponse{
+		Status: structs.HealthPassing,
+		Checks: checks,
+	}
+
+	for _, c := range checks {
+		if c.Status == structs.HealthCritical {
+			health.Status = structs.HealthCritical
+			break
+		}
+		if c.Status == structs.HealthWarning {
+			health.Status = structs.HealthWarning
+		}
+	}
+
+	return health
+}
+
+// consulRequired indicates whether Consul is used for leader election or
+// state storage.
+func (s *Server) consulRequired() bool {
+	if s.candidate.raft == nil && !s.candidate.standalone {
+		return true
+	}
+	return stateBackend(s.Config()) == structs.StateBackendConsul
+}
+
+// watcherStatus returns the progress of the job and node watchers.
+func (s *Server) watcherStatus() []*structs.WatcherStatus {
+	s.subsystemLock.Lock()
+	_, jobRunning := s.subsystems[subsystemJobWatcher]
+	_, nodeRunning := s.subsystems[subsystemNodeWatcher]
+	s.subsystemLock.Unlock()
+
+	policies := s.jobScalingPolicies
+	policies.Lock.RLock()
+	jobWatcher := &structs.WatcherStatus{
+		Name:      subsystemJobWatcher,
+		Running:   jobRunning,
+		LastIndex: policies.LastChangeIndex,
+		LastPoll:  policies.LastPoll,
+	}
+	policies.Lock.RUnlock()
+
+	registry := s.nodeRegistry
+	registry.Lock.RLock()
+	nodeWatcher := &structs.WatcherStatus{
+		Name:      subsystemNodeWatcher,
+		Running:   nodeRunning,
+		LastIndex: registry.LastChangeIndex,
+		LastPoll:  registry.LastPoll,
+	}
+	registry.Lock.RUnlock()
+
+	return []*structs.WatcherStatus{jobWatcher, nodeWatcher}
+}
+
+// notifierStatus returns the outcome of the notifications sent by each
+// configured notifier.
+func notifierStatus(config *structs.Config) []*structs.NotifierStatus {
+	notifiers := make([]*structs.NotifierStatus, 0)
+	if config.Notification == nil {
+		return notifiers
+	}
+
+	for _, n := range config.Notification.Notifiers {
+		health := n.Health()
+		notifiers = append(notifiers, &structs.NotifierStatus{
+			Name:        n.Name(),
+			LastAttempt: health.LastAttempt,
+			LastError:   health.LastError,
+			LastSuccess: health.LastSuccess,
+		})
+	}
+
+	return notifiers
+}
+
+// stateBackend returns the configured state storage backend.
+func stateBackend(config *structs.Config) string {
+	if config.State == nil || config.State.Backend == "" {
+		return structs.StateBackendConsul
+	}
+	return config.State.Backend
+}
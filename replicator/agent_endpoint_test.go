@@ -0,0 +1,134 @@
+package replicator
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/notifier"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// testHealthClient is a Nomad and Consul client which reports the configured
+// leader error.
+type testHealthClient struct {
+	structs.ConsulClient
+	structs.NomadClient
+	err error
+}
+
+func (c *testHealthClient) Leader() (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	return "10.0.0.1:4647", nil
+}
+
+// testNotifier is a notifier which reports the configured health.
+type testNotifier struct {
+	health notifier.Health
+}
+
+func (n *testNotifier) Health() notifier.Health                  { return n.health }
+func (n *testNotifier) Name() string                             { return "test" }
+func (n *testNotifier) SendNotification(notifier.FailureMessage) {}
+
+func TestAgent_Health(t *testing.T) {
+	nomadClient := &testHealthClient{}
+	consulClient := &testHealthClient{}
+	notify := &testNotifier{}
+
+	s := &Server{
+		candidate: &LeaderCandidate{leader: true, standalone: true},
+		config: &structs.Config{
+			ConsulClient: consulClient,
+			NomadClient:  nomadClient,
+			Notification: &structs.Notification{
+				Notifiers: []notifier.Notifier{notify},
+			},
+			State: &structs.State{Backend: structs.StateBackendBolt},
+		},
+		jobScalingPolicies: newJobScalingPolicy(),
+		nodeRegistry:       structs.NewNodeRegistry(),
+		rpcAdvertise:       &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1314},
+		rpcServer:          rpc.NewServer(),
+		subsystems:         make(map[string]*subsystem),
+	}
+	s.rpcServer.Register(&Agent{s})
+
+	now := time.Now()
+	s.subsystems[subsystemJobWatcher] = &subsystem{started: now.Add(-time.Hour)}
+	s.jobScalingPolicies.LastChangeIndex = 42
+	s.jobScalingPolicies.LastPoll = now.Add(-time.Minute)
+
+	statuses := func(health *structs.AgentHealthResponse) map[string]string {
+		checks := make(map[string]string)
+		for _, c := range health.Checks {
+			checks[c.Name] = c.Status
+		}
+		return checks
+	}
+
+	var health structs.AgentHealthResponse
+	if err := s.RPC("Agent.Health", nil, &health); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"consul":        structs.HealthPassing,
+		"job_watcher":   structs.HealthPassing,
+		"leader":        structs.HealthPassing,
+		"nomad":         structs.HealthPassing,
+		"notifier:test": structs.HealthPassing,
+	}
+	if health.Status != structs.HealthPassing || fmt.Sprint(statuses(&health)) != fmt.Sprint(expected) {
+		t.Fatalf("expected passing checks %v but got %v (%v)", expected,
+			statuses(&health), health.Status)
+	}
+
+	// A failing notifier and an unreachable Consul cluster which is not used
+	// for leadership or state are reported as warnings.
+	notify.health.LastError = "connection refused"
+	consulClient.err = fmt.Errorf("connection refused")
+
+	health = *s.agentHealth(now)
+	checks := statuses(&health)
+	if health.Status != structs.HealthWarning ||
+		checks["consul"] != structs.HealthWarning ||
+		checks["notifier:test"] != structs.HealthWarning {
+		t.Fatalf("expected warning checks but got %v (%v)", checks, health.Status)
+	}
+
+	// A watcher which has not polled Nomad beyond the threshold and an
+	// unreachable Nomad cluster are critical.
+	nomadClient.err = fmt.Errorf("connection refused")
+	health = *s.agentHealth(now.Add(watcherStallThreshold + time.Minute))
+	checks = statuses(&health)
+	if health.Status != structs.HealthCritical ||
+		checks["job_watcher"] != structs.HealthCritical ||
+		checks["nomad"] != structs.HealthCritical {
+		t.Fatalf("expected critical checks but got %v (%v)", checks, health.Status)
+	}
+
+	var self structs.AgentSelfResponse
+	if err := s.RPC("Agent.Self", nil, &self); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !self.Leader || self.LeaderAddr != "127.0.0.1:1314" ||
+		self.StateBackend != structs.StateBackendBolt {
+		t.Fatalf("unexpected agent status %+v", self)
+	}
+
+	if len(self.Watchers) != 2 || !self.Watchers[0].Running ||
+		self.Watchers[0].LastIndex != 42 || self.Watchers[1].Running {
+		t.Fatalf("unexpected watcher status %+v %+v", self.Watchers[0],
+			self.Watchers[1])
+	}
+
+	if len(self.Notifiers) != 1 || self.Notifiers[0].LastError != "connection refused" {
+		t.Fatalf("unexpected notifier status %+v", self.Notifiers)
+	}
+}
@@ -1,6 +1,7 @@
 package replicator
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -8,15 +9,15 @@ import (
 
 	metrics "github.com/armon/go-metrics"
 
-	"github.com/elsevier-core-engineering/replicator/client"
 	"github.com/elsevier-core-engineering/replicator/logging"
 	"github.com/elsevier-core-engineering/replicator/notifier"
 	"github.com/elsevier-core-engineering/replicator/replicator/structs"
 )
 
 // asyncClusterScaling triggers concurrent cluster scaling operations for
-// each worker pool in the node registry.
-func (s *Server) asyncClusterScaling(nodeRegistry *structs.NodeRegistry,
+// each worker pool in the node registry. Scaling operations are interrupted
+// when the context is cancelled.
+func (s *Server) asyncClusterScaling(ctx context.Context, nodeRegistry *structs.NodeRegistry,
 	jobRegistry *structs.JobScalingPolicies) {
 
 	// Setup our wait group to ensure we block until all worker pool scaling
@@ -33,7 +34,7 @@ func (s *Server) asyncClusterScaling(nodeRegistry *structs.NodeRegistry,
 	pools := make(chan string, poolCount)
 
 	// Calculate the number of worker threads to initiate.
-	maxConcurrency := s.config.ScalingConcurrency
+	maxConcurrency := s.Config().ScalingConcurrency
 
 	if poolCount < maxConcurrency {
 		maxConcurrency = poolCount
@@ -44,7 +45,7 @@ func (s *Server) asyncClusterScaling(nodeRegistry *structs.NodeRegistry,
 
 	// Initiate workers to implement worker pool scaling.
 	for w := 1; w <= maxConcurrency; w++ {
-		go s.workerPoolScaling(w, pools, nodeRegistry, jobRegistry, &wg)
+		go s.workerPoolScaling(ctx, w, pools, nodeRegistry, jobRegistry, &wg)
 	}
 
 	// Add worker pools to the worker channel.
@@ -58,13 +59,13 @@ func (s *Server) asyncClusterScaling(nodeRegistry *structs.NodeRegistry,
 
 // workerPoolScaling is a thread safe method for scaling an individual
 // worker pool.
-func (s *Server) workerPoolScaling(id int, pools <-chan string,
+func (s *Server) workerPoolScaling(ctx context.Context, id int, pools <-chan string,
 	nodeRegistry *structs.NodeRegistry, jobs *structs.JobScalingPolicies,
 	wg *sync.WaitGroup) {
 
-	// Setup references to clients for Nomad and Consul.
-	nomadClient := s.config.NomadClient
-	consulClient := s.config.ConsulClient
+	// Setup references to the Nomad client and state store.
+	nomadClient := s.Config().NomadClient
+	stateStore := s.Config().StateStore
 
 	for poolName := range pools {
 		// Create anonymous function to simplify wg.Done()
@@ -73,6 +74,14 @@ func (s *Server) workerPoolScaling(id int, pools <-chan string,
 			logging.Debug("core/cluster_scaling: scaling thread %v evaluating scaling "+
 				"for worker pool %v", id, poolName)
 
+			// Skip worker pools with a manual scaling operation in progress.
+			if !s.lockResource(ClusterType + "/" + poolName) {
+				logging.Debug("core/cluster_scaling: worker pool %v has a scaling "+
+					"operation in progress and will not be evaluated", poolName)
+				return
+			}
+			defer s.unlockResource(ClusterType + "/" + poolName)
+
 			// Obtain a read-only lock on the Node registry, grab a reference to
 			// our worker pool object and release the lock.
 			nodeRegistry.Lock.RLock()
@@ -86,12 +95,30 @@ func (s *Server) workerPoolScaling(id int, pools <-chan string,
 			workerPool.State = &structs.ScalingState{
 				ResourceName: workerPool.Name,
 				ResourceType: ClusterType,
-				StatePath: s.config.ConsulKeyRoot + "/state/nodes/" +
+				StatePath: s.Config().ConsulKeyRoot + "/state/nodes/" +
 					workerPool.Name,
 			}
 
 			// Attempt to load state from persistent storage.
-			consulClient.ReadState(workerPool.State, true)
+			stateStore.ReadState(workerPool.State, true)
+
+			// Reset state left behind by a removed worker pool of the same name.
+			if reviveState(workerPool.State) {
+				if err := stateStore.PersistState(workerPool.State); err != nil {
+					logging.Error("core/cluster_scaling: %v", err)
+				}
+			}
+
+			// Recover any operation interrupted by a previous leader.
+			if s.recoverPoolOperation(ctx, workerPool, nodeRegistry) {
+				if err := stateStore.PersistState(workerPool.State); err != nil {
+					logging.Error("core/cluster_scaling: %v", err)
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
 
 			// Setup a failure message to pass to the failsafe check.
 			msg := &notifier.FailureMessage{
@@ -102,7 +129,7 @@ func (s *Server) workerPoolScaling(id int, pools <-chan string,
 
 			// If the worker pool is in failsafe mode, decline to perform any scaling
 			// evaluation or action.
-			if !FailsafeCheck(workerPool.State, s.config, workerPool.RetryThreshold, msg) {
+			if !FailsafeCheck(workerPool.State, s.Config(), workerPool.RetryThreshold, msg) {
 				logging.Warning("core/cluster_scaling: worker pool %v is in failsafe "+
 					"mode, no scaling evaluations will be performed", workerPool.Name)
 				return
@@ -110,6 +137,35 @@ func (s *Server) workerPoolScaling(id int, pools <-chan string,
 
 			// Evaluate worker pool to determine if a scaling operation is required.
 			scale, err := nomadClient.EvaluatePoolScaling(poolCapacity, workerPool, jobs)
+
+			// Retain the result of the evaluation to be reported by the API.
+			if err == nil {
+				capacity := *poolCapacity
+				nodeRegistry.Lock.Lock()
+				workerPool.Capacity = &capacity
+				nodeRegistry.Lock.Unlock()
+			}
+
+			// Track the rate of change of the worker pool utilization when
+			// emergency scaling is configured.
+			var slope float64
+			if err == nil && workerPool.EmergencySlope > 0 &&
+				poolCapacity.ScalingMetric.Capacity > 0 {
+				slope = workerPool.State.UtilizationSlope(
+					float64(poolCapacity.ScalingMetric.Utilization)/
+						float64(poolCapacity.ScalingMetric.Capacity)*100, time.Now())
+
+				if !scale {
+					if perr := stateStore.PersistState(workerPool.State); perr != nil {
+						logging.Error("core/cluster_scaling: %v", perr)
+					}
+				}
+			}
+
+			publishEvaluation(s.Config(), structs.TopicPool, workerPool.Name,
+				poolCapacity.ScalingDirection, workerPoolMetrics(workerPool,
+					poolCapacity, slope), err)
+
 			if err != nil || !scale {
 				logging.Debug("core/cluster_scaling: scaling operation for worker pool %v "+
 					"is either not required or not permitted: %v", workerPool.Name, err)
@@ -119,8 +175,8 @@ func (s *Server) workerPoolScaling(id int, pools <-chan string,
 			// Copy the desired scsaling direction to the state object.
 			workerPool.State.ScalingDirection = poolCapacity.ScalingDirection
 
-			// Attempt to update state tracking information in Consul.
-			if err = consulClient.PersistState(workerPool.State); err != nil {
+			// Attempt to update state tracking information in persistent storage.
+			if err = stateStore.PersistState(workerPool.State); err != nil {
 				logging.Error("core/cluster_scaling: %v", err)
 			}
 
@@ -132,15 +188,22 @@ func (s *Server) workerPoolScaling(id int, pools <-chan string,
 				return
 			}
 
-			// Determine if the scaling cooldown threshold has been met.
-			ok := checkCooldownThreshold(workerPool)
-			if !ok {
+			// Determine if the scaling cooldown threshold has been met, unless
+			// utilization is climbing fast enough to warrant an emergency scale-out.
+			if emergencyScaleOut(poolCapacity.ScalingDirection, slope,
+				workerPool.EmergencySlope) {
+				logging.Info("core/cluster_scaling: utilization of worker pool %v is "+
+					"increasing at %.2f%% per minute, exceeding the emergency slope of "+
+					"%v%%; cooldown will be bypassed", workerPool.Name, slope,
+					workerPool.EmergencySlope)
+
+			} else if ok := checkCooldownThreshold(workerPool); !ok {
 				return
 			}
 
 			// Determine if we've reached the required number of consecutive scaling
 			// requests.
-			ok = checkPoolScalingThreshold(workerPool, s.config)
+			ok := checkPoolScalingThreshold(workerPool, s.Config())
 			if !ok {
 				return
 			}
@@ -156,78 +219,146 @@ func (s *Server) workerPoolScaling(id int, pools <-chan string,
 					poolCapacity.MaxAllowedUtilization)
 			}
 
-			if poolCapacity.ScalingDirection == structs.ScalingDirectionOut {
-				// Initiate cluster scaling operation by calling the scaling provider.
-				err = workerPool.ScalingProvider.Scale(workerPool, s.config, nodeRegistry)
-				if err != nil {
-					logging.Error("core/cluster_scaling: an error occurred while "+
-						"attempting a scaling operation against worker pool %v: %v",
-						workerPool.Name, err)
-					return
-				}
-
-				// Obtain a read/write lock on the node registry, write the worker
-				// pool state object back to the node registry and release the lock.
-				nodeRegistry.Lock.Lock()
-				nodeRegistry.WorkerPools[workerPool.Name].State = workerPool.State
-				nodeRegistry.Lock.Unlock()
+			// Describe the scaling operation for the scaling event history.
+			event := &structs.ScalingEvent{
+				CountAfter:  len(workerPool.Nodes),
+				CountBefore: len(workerPool.Nodes),
+				Direction:   poolCapacity.ScalingDirection,
+				Metrics:     workerPoolMetrics(workerPool, poolCapacity, slope),
+				Outcome:     structs.EventOutcomeFailure,
+				Type:        structs.EventTypeScale,
 			}
 
-			if poolCapacity.ScalingDirection == client.ScalingDirectionIn {
-				// Identify the least allocated node in the worker pool.
-				nodeID, nodeIP := nomadClient.LeastAllocatedNode(poolCapacity,
-					workerPool.ProtectedNode)
-				if nodeIP == "" || nodeID == "" {
-					logging.Error("core/cluster_scaling: unable to identify the least "+
-						"allocated node in worker pool %v", workerPool.Name)
-					return
-				}
-
-				logging.Info("core/cluster_scaling: identified node %v as the least "+
-					"allocated node in worker pool %v", nodeID, workerPool.Name)
-
-				// Register the least allocated node as eligible for scaling actions.
-				workerPool.State.EligibleNodes = append(workerPool.State.EligibleNodes,
-					nodeIP)
-
-				// Place the least allocated noded in drain mode.
-				logging.Info("core/cluster_scaling: placing node %v from worker pool %v "+
-					"in drain mode", nodeID, workerPool.Name)
+			// Record the operation before it starts so that it can be recovered if
+			// it is interrupted by leadership loss or shutdown.
+			beginOperation(s.Config(), workerPool.State, event)
+			defer func() { endOperation(s.Config(), workerPool.State, ctx.Err()) }()
 
-				if err = nomadClient.DrainNode(nodeID); err != nil {
-					logging.Error("core/cluster_scaling: an error occurred while "+
-						"attempting to place node %v from worker pool %v in drain mode: "+
-						"%v", nodeID, workerPool.Name, err)
+			switch poolCapacity.ScalingDirection {
+			case structs.ScalingDirectionOut:
+				err = s.scalePoolOut(ctx, workerPool, nodeRegistry)
+			case structs.ScalingDirectionIn:
+				err = s.scalePoolIn(ctx, workerPool, poolCapacity, nodeRegistry)
+			}
 
-					metrics.IncrCounter([]string{"cluster", workerPool.Name, "scale_in",
-						"failure"}, 1)
+			if err != nil {
+				if ctx.Err() != nil {
+					logging.Warning("core/cluster_scaling: scaling operation against "+
+						"worker pool %v was interrupted: %v", workerPool.Name, err)
 					return
 				}
 
-				// Initiate cluster scaling operation by calling the scaling provider.
-				err := workerPool.ScalingProvider.Scale(workerPool, s.config, nodeRegistry)
-				if err != nil {
-					logging.Error("core/cluster_scaling: an error occurred while "+
-						"attempting a scaling operation against worker pool %v: %v",
-						workerPool.Name, err)
-					return
-				}
+				logging.Error("core/cluster_scaling: an error occurred while "+
+					"attempting a scaling operation against worker pool %v: %v",
+					workerPool.Name, err)
 
-				// Obtain a read/write lock on the node registry, write the worker
-				// pool state object back to the node registry and release the lock.
-				nodeRegistry.Lock.Lock()
-				nodeRegistry.WorkerPools[workerPool.Name].State = workerPool.State
-				nodeRegistry.Lock.Unlock()
+				event.Reason = err.Error()
+				recordScalingEvent(s.Config(), workerPool.State, event)
+				return
+			}
 
+			switch poolCapacity.ScalingDirection {
+			case structs.ScalingDirectionOut:
+				event.CountAfter++
+			case structs.ScalingDirectionIn:
+				event.CountAfter--
 			}
 
 			// Our metric counter to track successful cluster scaling activities.
 			m := fmt.Sprintf("scale_%s", strings.ToLower(poolCapacity.ScalingDirection))
 			metrics.IncrCounter([]string{"cluster", workerPool.Name, m, "success"}, 1)
+
+			// Record the successful scaling operation in the scaling event history.
+			if event.Direction != structs.ScalingDirectionNone {
+				event.Outcome = structs.EventOutcomeSuccess
+				recordScalingEvent(s.Config(), workerPool.State, event)
+			}
 		}()
 	}
 }
 
+// scalePoolOut performs the launch step of a worker pool scale-out operation
+// by requesting an additional node from the scaling provider. The state
+// object must hold the pending operation.
+func (s *Server) scalePoolOut(ctx context.Context, workerPool *structs.WorkerPool,
+	nodeRegistry *structs.NodeRegistry) error {
+
+	advanceOperation(s.Config(), workerPool.State, structs.OperationStepLaunch)
+
+	// Initiate cluster scaling operation by calling the scaling provider.
+	if err := workerPool.ScalingProvider.Scale(ctx, workerPool, s.Config(),
+		nodeRegistry); err != nil {
+		return err
+	}
+
+	// Obtain a read/write lock on the node registry, write the worker pool
+	// state object back to the node registry and release the lock.
+	nodeRegistry.Lock.Lock()
+	nodeRegistry.WorkerPools[workerPool.Name].State = workerPool.State
+	nodeRegistry.Lock.Unlock()
+
+	return nil
+}
+
+// scalePoolIn performs the drain, detach and terminate steps of a worker
+// pool scale-in operation against the least allocated node identified from
+// the pool capacity. The state object must hold the pending operation.
+func (s *Server) scalePoolIn(ctx context.Context, workerPool *structs.WorkerPool,
+	poolCapacity *structs.ClusterCapacity, nodeRegistry *structs.NodeRegistry) error {
+
+	nomadClient := s.Config().NomadClient
+
+	// Identify the least allocated node in the worker pool.
+	nodeID, nodeIP := nomadClient.LeastAllocatedNode(poolCapacity,
+		workerPool.ProtectedNode)
+	if nodeIP == "" || nodeID == "" {
+		return fmt.Errorf("unable to identify the least allocated node")
+	}
+
+	logging.Info("core/cluster_scaling: identified node %v as the least "+
+		"allocated node in worker pool %v", nodeID, workerPool.Name)
+
+	// Register the least allocated node as eligible for scaling actions.
+	workerPool.State.EligibleNodes = append(workerPool.State.EligibleNodes,
+		nodeIP)
+
+	// Record the node to be drained so an interrupted operation can be
+	// rolled back or completed.
+	workerPool.State.PendingOperation.NodeAddress = nodeIP
+	workerPool.State.PendingOperation.NodeID = nodeID
+	advanceOperation(s.Config(), workerPool.State, structs.OperationStepDrain)
+
+	// Place the least allocated noded in drain mode.
+	logging.Info("core/cluster_scaling: placing node %v from worker pool %v "+
+		"in drain mode", nodeID, workerPool.Name)
+
+	if err := nomadClient.DrainNode(ctx, nodeID); err != nil {
+		if ctx.Err() == nil {
+			metrics.IncrCounter([]string{"cluster", workerPool.Name, "scale_in",
+				"failure"}, 1)
+		}
+		return fmt.Errorf("unable to drain node %v: %v", nodeID, err)
+	}
+
+	// The node has been drained and can now be removed by the scaling
+	// provider, the provider records any further steps it performs.
+	advanceOperation(s.Config(), workerPool.State, structs.OperationStepDetach)
+
+	// Initiate cluster scaling operation by calling the scaling provider.
+	if err := workerPool.ScalingProvider.Scale(ctx, workerPool, s.Config(),
+		nodeRegistry); err != nil {
+		return err
+	}
+
+	// Obtain a read/write lock on the node registry, write the worker pool
+	// state object back to the node registry and release the lock.
+	nodeRegistry.Lock.Lock()
+	nodeRegistry.WorkerPools[workerPool.Name].State = workerPool.State
+	nodeRegistry.Lock.Unlock()
+
+	return nil
+}
+
 // checkPoolScalingThreshold determines if we've reached the required number
 // of consecutive scaling attempts.
 func checkPoolScalingThreshold(workerPool *structs.WorkerPool,
@@ -277,7 +408,7 @@ func checkPoolScalingThreshold(workerPool *structs.WorkerPool,
 		workerPool.State.ScaleOutRequests = 0
 	}
 
-	if err := config.ConsulClient.PersistState(workerPool.State); err != nil {
+	if err := config.StateStore.PersistState(workerPool.State); err != nil {
 		logging.Error("core:cluster_scaling: unable to update cluster scaling "+
 			"state to persistent store at path %v: %v",
 			workerPool.State.StatePath, err)
@@ -297,9 +428,12 @@ func checkCooldownThreshold(workerPool *structs.WorkerPool) bool {
 		return true
 	}
 
-	// Calculate the cooldown threshold.
-	cooldown := workerPool.State.LastScalingEvent.Add(
-		time.Duration(workerPool.Cooldown) * time.Second)
+	// Calculate the cooldown threshold for the requested scaling direction.
+	cooldown := cooldownExpiry(workerPool.State,
+		workerPool.State.ScalingDirection,
+		time.Duration(workerPool.Cooldown)*time.Second,
+		time.Duration(workerPool.ScaleInCooldown)*time.Second,
+		time.Duration(workerPool.ScaleOutCooldown)*time.Second)
 
 	if time.Now().Before(cooldown) {
 		logging.Debug("core/cluster_scaling: cluster scaling cooldown threshold "+
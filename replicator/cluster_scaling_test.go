@@ -14,8 +14,8 @@ func TestClusterScaling_scalingThreshold(t *testing.T) {
 	c, s := testutil.MakeClientWithConfig(t)
 	defer s.Stop()
 
-	consul, _ := client.NewConsulClient(s.HTTPAddr, "")
-	c.ConsulClient = consul
+	store, _ := client.NewConsulStateStore(s.HTTPAddr, "")
+	c.StateStore = store
 
 	state := &structs.ScalingState{}
 
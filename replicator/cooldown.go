@@ -0,0 +1,34 @@
+package replicator
+
+import (
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// cooldownExpiry calculates the time at which the cooldown for a scaling
+// direction expires. When a direction-specific cooldown is configured,
+// scale-out is measured from the last scale-out event so capacity can be
+// added quickly, while scale-in is measured from the last scaling event in
+// any direction to remain conservative. Otherwise the general cooldown is
+// measured from the last scaling event.
+func cooldownExpiry(state *structs.ScalingState, direction string,
+	cooldown, scaleInCooldown, scaleOutCooldown time.Duration) time.Time {
+
+	switch {
+	case direction == structs.ScalingDirectionOut && scaleOutCooldown > 0:
+		return state.LastScaleOutEvent.Add(scaleOutCooldown)
+	case direction == structs.ScalingDirectionIn && scaleInCooldown > 0:
+		return state.LastScalingEvent.Add(scaleInCooldown)
+	}
+
+	return state.LastScalingEvent.Add(cooldown)
+}
+
+// emergencyScaleOut determines whether a scale-out should bypass the cooldown
+// because utilization is climbing faster than the configured slope, in
+// percentage points per minute. A slope of zero disables emergency scaling.
+func emergencyScaleOut(direction string, slope, threshold float64) bool {
+	return direction == structs.ScalingDirectionOut && threshold > 0 &&
+		slope >= threshold
+}
@@ -0,0 +1,66 @@
+package replicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestCooldown_cooldownExpiry(t *testing.T) {
+	state := &structs.ScalingState{}
+	state.RecordScalingEvent(structs.ScalingDirectionOut)
+
+	if state.LastScaleOutEvent != state.LastScalingEvent || !state.LastScaleInEvent.IsZero() {
+		t.Fatalf("expected only the scale-out event to be recorded: %+v", state)
+	}
+
+	// A scale-in shortly after the scale-out resets the last scaling event
+	// but not the last scale-out event.
+	state.LastScaleOutEvent = state.LastScaleOutEvent.Add(-10 * time.Minute)
+	state.RecordScalingEvent(structs.ScalingDirectionIn)
+
+	cooldown := 5 * time.Minute
+	scaleIn := 15 * time.Minute
+	scaleOut := time.Minute
+
+	expiry := cooldownExpiry(state, structs.ScalingDirectionOut, cooldown, scaleIn, scaleOut)
+	if !time.Now().After(expiry) {
+		t.Fatalf("expected scale-out cooldown to have expired: %v", expiry)
+	}
+
+	expiry = cooldownExpiry(state, structs.ScalingDirectionIn, cooldown, scaleIn, scaleOut)
+	if expected := state.LastScalingEvent.Add(scaleIn); expiry != expected {
+		t.Fatalf("expected scale-in expiry %v but got %v", expected, expiry)
+	}
+
+	// Without direction-specific cooldowns the general cooldown applies.
+	expiry = cooldownExpiry(state, structs.ScalingDirectionOut, cooldown, 0, 0)
+	if expected := state.LastScalingEvent.Add(cooldown); expiry != expected {
+		t.Fatalf("expected general expiry %v but got %v", expected, expiry)
+	}
+}
+
+func TestCooldown_emergencyScaleOut(t *testing.T) {
+	state := &structs.ScalingState{}
+	now := time.Now()
+
+	if slope := state.UtilizationSlope(40, now); slope != 0 {
+		t.Fatalf("expected initial slope of 0 but got %v", slope)
+	}
+
+	slope := state.UtilizationSlope(70, now.Add(2*time.Minute))
+	if slope != 15 {
+		t.Fatalf("expected slope of 15 but got %v", slope)
+	}
+
+	if !emergencyScaleOut(structs.ScalingDirectionOut, slope, 10) {
+		t.Fatalf("expected emergency scale-out at slope %v", slope)
+	}
+	if emergencyScaleOut(structs.ScalingDirectionIn, slope, 10) {
+		t.Fatalf("expected no emergency scale-in")
+	}
+	if emergencyScaleOut(structs.ScalingDirectionOut, slope, 0) {
+		t.Fatalf("expected emergency scaling to be disabled with no slope")
+	}
+}
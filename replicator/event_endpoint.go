@@ -0,0 +1,125 @@
+package replicator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Event endpoint is used to query the event stream of the leader.
+type Event struct {
+	srv *Server
+}
+
+// Index returns the index of the most recent event of the topics requested,
+// blocking until it exceeds the minimum query index if a maximum query time is
+// provided. Scaling events are only published by the leader so requests are
+// forwarded to it, giving every agent the same index for a resource.
+func (e *Event) Index(args *structs.EventIndexRequest, reply *structs.EventIndexResponse) error {
+	if done, err := e.srv.forward("Event.Index", args, reply); done {
+		return err
+	}
+
+	for _, resource := range eventResources(args.Topics) {
+		if _, err := e.srv.authorize(args.AuthToken, resource,
+			structs.ACLCapabilityRead); err != nil {
+			return err
+		}
+	}
+
+	broker := e.srv.EventBroker()
+	if args.MaxQueryTime <= 0 {
+		reply.Index = broker.Index(args.Topics)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(e.srv.shutdownCtx, args.MaxQueryTime)
+	defer cancel()
+
+	reply.Index = broker.WaitIndex(ctx, args.Topics, args.MinQueryIndex)
+	return nil
+}
+
+// List returns the events of the topics requested which were published after
+// the position in the event stream provided, blocking until an event is
+// published if none are available. Scaling events are only published by the
+// leader so requests are forwarded to it, allowing any agent to relay the
+// event stream of the leader.
+func (e *Event) List(args *structs.EventListRequest, reply *structs.EventListResponse) error {
+	if done, err := e.srv.forward("Event.List", args, reply); done {
+		return err
+	}
+
+	if _, err := e.srv.authorize(args.AuthToken, structs.ACLResourceOperator,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	broker := e.srv.EventBroker()
+	reply.StreamID = broker.ID()
+
+	if args.StreamID == "" {
+		reply.Index = broker.Index(nil)
+		return nil
+	}
+
+	if args.StreamID != broker.ID() {
+		return fmt.Errorf("the event stream has changed, leadership may have " +
+			"been lost")
+	}
+
+	ctx, cancel := context.WithTimeout(e.srv.shutdownCtx, args.MaxQueryTime)
+	defer cancel()
+
+	events, index, err := broker.WaitEvents(ctx, args.Topics, args.MinQueryIndex)
+	if err != nil {
+		return err
+	}
+
+	reply.Index = index
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("unable to encode event %v: %v", event.Index, err)
+		}
+		reply.Events = append(reply.Events, data)
+	}
+
+	return nil
+}
+
+// eventResources returns the ACL resources which must be readable to query
+// the events of the topics. Events of worker pools and jobs require read
+// access to the pool and job resources and all other events, including every
+// event when no topics are specified, require read access to the operator
+// resource.
+func eventResources(topics []structs.EventTopic) []string {
+	seen := make(map[string]bool)
+	var resources []string
+
+	add := func(resource string) {
+		if !seen[resource] {
+			seen[resource] = true
+			resources = append(resources, resource)
+		}
+	}
+
+	if len(topics) == 0 {
+		add(structs.ACLResourceOperator)
+	}
+
+	for _, topic := range topics {
+		switch topic.Topic {
+		case structs.TopicJob:
+			add(structs.ACLResourceJob)
+		case structs.TopicPool:
+			add(structs.ACLResourcePool)
+		default:
+			add(structs.ACLResourceOperator)
+		}
+	}
+
+	return resources
+}
@@ -0,0 +1,173 @@
+package replicator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestEvent_Index(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping raft cluster test in short mode")
+	}
+
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	servers := testRaftCluster(t, dir, 3)
+	defer func() {
+		for _, s := range servers {
+			s.Shutdown()
+		}
+	}()
+
+	leader := waitForLeader(t, servers)
+	broker := leader.EventBroker()
+	broker.Publish(structs.TopicPool, "default", structs.StreamEventScaling, nil)
+	index := broker.Index(nil)
+
+	pool := []structs.EventTopic{{Topic: structs.TopicPool, Key: "default"}}
+
+	// Every server returns the index of the leader.
+	for _, s := range servers {
+		reply := &structs.EventIndexResponse{}
+		if err := s.RPC("Event.Index", &structs.EventIndexRequest{Topics: pool}, reply); err != nil {
+			t.Fatalf("error querying the index on %v: %v", s.rpcAdvertise, err)
+		}
+
+		if reply.Index != index {
+			t.Fatalf("expected index %v from %v but got %v", index, s.rpcAdvertise,
+				reply.Index)
+		}
+	}
+
+	// A blocking query of a follower is woken by an event of the resource
+	// published by the leader, but not by events of other resources.
+	var follower *Server
+	for _, s := range servers {
+		if s != leader {
+			follower = s
+			break
+		}
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		broker.Publish(structs.TopicPool, "other", structs.StreamEventScaling, nil)
+		time.Sleep(50 * time.Millisecond)
+		broker.Publish(structs.TopicPool, "default", structs.StreamEventScaling, nil)
+	}()
+
+	reply := &structs.EventIndexResponse{}
+	err = follower.RPC("Event.Index", &structs.EventIndexRequest{
+		Topics:        pool,
+		MinQueryIndex: index,
+		MaxQueryTime:  10 * time.Second,
+	}, reply)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if reply.Index != index+2 {
+		t.Fatalf("expected index %v but got %v", index+2, reply.Index)
+	}
+}
+
+func TestEvent_List(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping raft cluster test in short mode")
+	}
+
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	servers := testRaftCluster(t, dir, 3)
+	defer func() {
+		for _, s := range servers {
+			s.Shutdown()
+		}
+	}()
+
+	leader := waitForLeader(t, servers)
+
+	var follower *Server
+	for _, s := range servers {
+		if s != leader {
+			follower = s
+			break
+		}
+	}
+
+	// A request without a stream ID returns the position of the event stream
+	// of the leader.
+	args := &structs.EventListRequest{
+		Topics:       []structs.EventTopic{{Topic: structs.TopicPool}},
+		MaxQueryTime: 10 * time.Second,
+	}
+	reply := &structs.EventListResponse{}
+	if err := follower.RPC("Event.List", args, reply); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	broker := leader.EventBroker()
+	if reply.StreamID != broker.ID() || reply.Index != broker.Index(nil) {
+		t.Fatalf("expected the position of the leader's event stream but got %v "+
+			"at %v", reply.StreamID, reply.Index)
+	}
+	args.StreamID, args.MinQueryIndex = reply.StreamID, reply.Index
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		broker.Publish(structs.TopicJob, "web/frontend", structs.StreamEventScaling, nil)
+		broker.Publish(structs.TopicPool, "default", structs.StreamEventScaling,
+			&structs.ScalingEvent{ResourceID: "default"})
+	}()
+
+	// The follower relays the scaling event published by the leader.
+	reply = &structs.EventListResponse{}
+	if err := follower.RPC("Event.List", args, reply); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(reply.Events) != 1 || reply.Index != args.MinQueryIndex+2 {
+		t.Fatalf("expected 1 event up to index %v but got %v up to %v",
+			args.MinQueryIndex+2, len(reply.Events), reply.Index)
+	}
+
+	var event struct {
+		Key     string
+		Payload *structs.ScalingEvent
+	}
+	if err := json.Unmarshal(reply.Events[0], &event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Key != "default" || event.Payload.ResourceID != "default" {
+		t.Fatalf("expected the scaling event of the default pool but got %s",
+			reply.Events[0])
+	}
+
+	// A request made against another event stream is refused.
+	args.StreamID = "unknown"
+	if err := follower.RPC("Event.List", args, &structs.EventListResponse{}); err == nil {
+		t.Fatalf("expected an error listing events of an unknown event stream")
+	}
+
+	// Each poll reused a single connection to the leader.
+	follower.connPool.lock.Lock()
+	idle := len(follower.connPool.idle[leader.rpcAdvertise.String()])
+	follower.connPool.lock.Unlock()
+
+	if idle != 1 {
+		t.Fatalf("expected 1 idle connection to the leader but got %v", idle)
+	}
+}
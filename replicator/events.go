@@ -0,0 +1,47 @@
+package replicator
+
+import (
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// publishEvaluation publishes the result of a scaling evaluation of a worker
+// pool or job group to the event stream.
+func publishEvaluation(config *structs.Config, topic, key, direction string,
+	metrics map[string]float64, err error) {
+
+	event := &structs.EvaluationEvent{
+		Direction: direction,
+		Metrics:   metrics,
+	}
+
+	// The metrics of a failed evaluation are incomplete.
+	if err != nil {
+		event.Error = err.Error()
+		event.Metrics = nil
+	}
+
+	config.EventBroker.Publish(topic, key, structs.StreamEventEvaluation, event)
+}
+
+// publishScalingEvent publishes an event recorded in the scaling event
+// history at the specified path, which includes the outcome of scaling
+// operations and failsafe mode transitions, to the event stream.
+func publishScalingEvent(config *structs.Config, path string,
+	event *structs.ScalingEvent) {
+
+	topic := structs.TopicPool
+	if strings.Contains(path, "/history/jobs/") {
+		topic = structs.TopicJob
+	}
+
+	eventType := structs.StreamEventScaling
+	if event.Type == structs.EventTypeFailsafe {
+		eventType = structs.StreamEventFailsafe
+	}
+
+	// Subscribers receive a copy as the event may be modified once recorded.
+	published := *event
+	config.EventBroker.Publish(topic, event.ResourceID, eventType, &published)
+}
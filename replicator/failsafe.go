@@ -46,6 +46,10 @@ func FailsafeCheck(state *structs.ScalingState, config *structs.Config,
 			"scaling operations should be permitted", message.ResourceType,
 			message.ResourceID)
 	case false:
+		// The circuit breaker is tripped automatically rather than by an
+		// operator.
+		state.FailsafeAdmin = false
+		state.FailsafeChangedBy = ""
 		SetFailsafeMode(state, config, true, message)
 	}
 
@@ -56,6 +60,16 @@ func FailsafeCheck(state *structs.ScalingState, config *structs.Config,
 func SetFailsafeMode(state *structs.ScalingState, config *structs.Config,
 	enabled bool, message *notifier.FailureMessage) (err error) {
 
+	// Build the scaling history event recording the failsafe transition
+	// before the failure count is reset.
+	var event *structs.ScalingEvent
+	if state.FailsafeMode != enabled {
+		event = failsafeEvent(state, enabled)
+
+		state.FailsafeReason = event.Reason
+		state.FailsafeUpdated = time.Now()
+	}
+
 	switch enabled {
 	case true:
 		if !state.FailsafeMode {
@@ -86,16 +100,47 @@ func SetFailsafeMode(state *structs.ScalingState, config *structs.Config,
 	state.FailsafeMode = enabled
 
 	// Attempt to update the persistent state tracking information.
-	err = config.ConsulClient.PersistState(state)
+	err = config.StateStore.PersistState(state)
 
 	if err != nil {
 		return fmt.Errorf("core/failsafe: an attempt to update the persistent "+
 			"state tracking information failed: %v", err)
 	}
 
+	if event != nil {
+		recordScalingEvent(config, state, event)
+	}
+
 	return nil
 }
 
+// failsafeEvent builds the scaling history event recording a change to the
+// failsafe mode of a resource.
+func failsafeEvent(state *structs.ScalingState, enabled bool) *structs.ScalingEvent {
+	event := &structs.ScalingEvent{
+		Metrics: map[string]float64{
+			"failure_count": float64(state.FailureCount),
+		},
+		Outcome: structs.EventOutcomeFailsafeDisabled,
+		Type:    structs.EventTypeFailsafe,
+	}
+
+	if enabled {
+		event.Outcome = structs.EventOutcomeFailsafeEnabled
+		event.Reason = "the failure threshold has been reached"
+	}
+
+	if state.FailsafeAdmin {
+		event.Reason = "failsafe mode was administratively " + event.Outcome
+
+		if state.FailsafeChangedBy != "" {
+			event.Reason = event.Reason + " by " + state.FailsafeChangedBy
+		}
+	}
+
+	return event
+}
+
 // sendFailsafeNotification is used to setup a notification for either
 // jobscaling or clusterscaling failure and send this to all configured backends.
 func sendFailsafeNotification(message *notifier.FailureMessage,
@@ -122,7 +167,7 @@ func sendFailsafeNotification(message *notifier.FailureMessage,
 		state.LastNotificationEvent = time.Now()
 
 		// Write state tracking information to persistent storage.
-		if err := config.ConsulClient.PersistState(state); err != nil {
+		if err := config.StateStore.PersistState(state); err != nil {
 			logging.Error("core/failsafe: %v", err)
 		}
 	}
@@ -0,0 +1,157 @@
+package replicator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/notifier"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Failsafe endpoint is used to query and administratively control the
+// failsafe mode of worker pools and job groups.
+type Failsafe struct {
+	srv *Server
+}
+
+// List returns every worker pool and job group currently in failsafe mode,
+// sorted by resource type and ID. Requests are forwarded to the leader.
+func (f *Failsafe) List(args *structs.QueryOptions, reply *structs.FailsafeListResponse) error {
+	if done, err := f.srv.forward("Failsafe.List", args, reply); done {
+		return err
+	}
+
+	if _, err := f.srv.authorize(args.AuthToken, structs.ACLResourceFailsafe,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	states, err := f.srv.Config().StateStore.ListState(f.srv.Config().ConsulKeyRoot +
+		"/state/")
+	if err != nil {
+		return err
+	}
+
+	reply.Resources = make([]*structs.FailsafeStatus, 0)
+	for _, state := range states {
+		if state.FailsafeMode {
+			reply.Resources = append(reply.Resources, failsafeStatus(state))
+		}
+	}
+
+	sort.Slice(reply.Resources, func(i, j int) bool {
+		if reply.Resources[i].ResourceType != reply.Resources[j].ResourceType {
+			return reply.Resources[i].ResourceType < reply.Resources[j].ResourceType
+		}
+		return reply.Resources[i].ResourceID < reply.Resources[j].ResourceID
+	})
+
+	return nil
+}
+
+// Set enables or disables failsafe mode for a worker pool or job group,
+// recording the operator who made the change. No status is returned if no
+// state exists for the resource. Requests are forwarded to the leader so the
+// change is not overwritten by a scaling evaluation in progress.
+func (f *Failsafe) Set(args *structs.FailsafeRequest, reply *structs.FailsafeResponse) error {
+	if done, err := f.srv.forward("Failsafe.Set", args, reply); done {
+		return err
+	}
+
+	token, err := f.srv.authorize(args.AuthToken, structs.ACLResourceFailsafe,
+		structs.ACLCapabilityWrite)
+	if err != nil {
+		return err
+	}
+	args.Actor = f.srv.aclActor(token, args.Actor)
+
+	path, err := failsafeStatePath(f.srv.Config().ConsulKeyRoot, args.ResourceType,
+		args.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	state := &structs.ScalingState{StatePath: path}
+	f.srv.Config().StateStore.ReadState(state, false)
+
+	if state.LastUpdated.IsZero() {
+		return nil
+	}
+
+	// Failsafe mode is already in the desired state.
+	if state.FailsafeMode == args.Enable {
+		reply.Status = failsafeStatus(state)
+		return nil
+	}
+
+	verb := "disabled"
+	if args.Enable {
+		verb = "enabled"
+	}
+
+	logging.Info("core/failsafe: failsafe mode for %v %v is being "+
+		"administratively %v by %v", args.ResourceType, args.ResourceID, verb,
+		args.Actor)
+
+	state.FailsafeAdmin = true
+	state.FailsafeChangedBy = args.Actor
+
+	message := &notifier.FailureMessage{
+		AlertUID:     "replicator-failsafe-admin-api",
+		ResourceID:   args.ResourceID,
+		ResourceType: args.ResourceType,
+	}
+
+	if err := SetFailsafeMode(state, f.srv.Config(), args.Enable, message); err != nil {
+		return err
+	}
+
+	reply.Status = failsafeStatus(state)
+	return nil
+}
+
+// failsafeStatePath returns the location of the state object of a worker
+// pool or job group.
+func failsafeStatePath(keyRoot, resourceType, resourceID string) (string, error) {
+	switch resourceType {
+	case ClusterType:
+		if resourceID == "" || strings.Contains(resourceID, "/") {
+			return "", fmt.Errorf("invalid worker pool name %q", resourceID)
+		}
+		return keyRoot + "/state/nodes/" + resourceID, nil
+
+	case JobType:
+		parts := strings.Split(resourceID, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return "", fmt.Errorf("invalid job group %q, the job and group must "+
+				"be specified as job/group", resourceID)
+		}
+		return keyRoot + "/state/jobs/" + resourceID, nil
+	}
+
+	return "", fmt.Errorf("invalid resource type %q", resourceType)
+}
+
+// failsafeStatus describes the failsafe mode of the resource tracked by the
+// state object.
+func failsafeStatus(state *structs.ScalingState) *structs.FailsafeStatus {
+	_, resourceID := structs.HistoryPath(state.StatePath)
+
+	resourceType := ClusterType
+	if strings.Contains(state.StatePath, "/state/jobs/") {
+		resourceType = JobType
+	}
+
+	return &structs.FailsafeStatus{
+		ChangedBy:        state.FailsafeChangedBy,
+		FailsafeMode:     state.FailsafeMode,
+		FailureCount:     state.FailureCount,
+		LastScalingEvent: state.LastScalingEvent,
+		Reason:           state.FailsafeReason,
+		ResourceID:       resourceID,
+		ResourceType:     resourceType,
+		Updated:          state.FailsafeUpdated,
+	}
+}
@@ -0,0 +1,122 @@
+package replicator
+
+import (
+	"io/ioutil"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/notifier"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestFailsafe_ListAndSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	s := &Server{
+		candidate: &LeaderCandidate{leader: true},
+		config: &structs.Config{
+			ConsulKeyRoot: "replicator/config",
+			History:       &structs.History{MaxEvents: 10},
+			Notification:  &structs.Notification{},
+			StateStore:    store,
+		},
+		rpcServer: rpc.NewServer(),
+	}
+	s.rpcServer.Register(&Failsafe{s})
+
+	for _, path := range []string{"state/nodes/example", "state/jobs/example/cache"} {
+		store.ReadState(&structs.ScalingState{
+			StatePath: s.config.ConsulKeyRoot + "/" + path,
+		}, true)
+	}
+
+	// Trip the circuit breaker of the worker pool automatically.
+	state := &structs.ScalingState{
+		ResourceType: ClusterType,
+		StatePath:    "replicator/config/state/nodes/example",
+	}
+	store.ReadState(state, false)
+	state.FailureCount = 3
+	FailsafeCheck(state, s.config, 3, &notifier.FailureMessage{
+		ResourceID:   "example",
+		ResourceType: ClusterType,
+	})
+
+	// Enable failsafe mode for the job group administratively.
+	var set structs.FailsafeResponse
+	args := &structs.FailsafeRequest{
+		Actor:        "api:127.0.0.1",
+		Enable:       true,
+		ResourceID:   "example/cache",
+		ResourceType: JobType,
+	}
+	if err := s.RPC("Failsafe.Set", args, &set); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if set.Status == nil || !set.Status.FailsafeMode ||
+		set.Status.ChangedBy != "api:127.0.0.1" || set.Status.Updated.IsZero() ||
+		!strings.Contains(set.Status.Reason, "by api:127.0.0.1") {
+		t.Fatalf("expected failsafe mode to be enabled by the operator but got %#v",
+			set.Status)
+	}
+
+	var out structs.FailsafeListResponse
+	if err := s.RPC("Failsafe.List", nil, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Resources) != 2 {
+		t.Fatalf("expected two resources in failsafe mode but got %#v",
+			out.Resources)
+	}
+	if pool := out.Resources[1]; pool.ResourceType != ClusterType ||
+		pool.ResourceID != "example" || pool.ChangedBy != "" ||
+		pool.Reason != "the failure threshold has been reached" {
+		t.Fatalf("unexpected failsafe status for worker pool example: %#v", pool)
+	}
+
+	// Disabling failsafe mode removes the resource from the list.
+	args.Enable = false
+	set = structs.FailsafeResponse{}
+	if err := s.RPC("Failsafe.Set", args, &set); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if set.Status == nil || set.Status.FailsafeMode {
+		t.Fatalf("expected failsafe mode to be disabled but got %#v", set.Status)
+	}
+
+	out = structs.FailsafeListResponse{}
+	if err := s.RPC("Failsafe.List", nil, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Resources) != 1 || out.Resources[0].ResourceID != "example" {
+		t.Fatalf("expected only worker pool example in failsafe mode but got %#v",
+			out.Resources)
+	}
+
+	// Resources without state and invalid resources are rejected.
+	set = structs.FailsafeResponse{}
+	args = &structs.FailsafeRequest{ResourceID: "missing", ResourceType: ClusterType}
+	if err := s.RPC("Failsafe.Set", args, &set); err != nil || set.Status != nil {
+		t.Fatalf("expected no status for a missing resource but got %#v, %v",
+			set.Status, err)
+	}
+
+	args = &structs.FailsafeRequest{ResourceID: "example", ResourceType: JobType}
+	if err := s.RPC("Failsafe.Set", args, &set); err == nil {
+		t.Fatalf("expected an error for a job group without a group name")
+	}
+}
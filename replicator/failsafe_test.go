@@ -15,8 +15,8 @@ func TestFailsafe_FaileSafeCheck(t *testing.T) {
 	c, s := testutil.MakeClientWithConfig(t)
 	defer s.Stop()
 
-	consul, _ := client.NewConsulClient(s.HTTPAddr, "")
-	c.ConsulClient = consul
+	store, _ := client.NewConsulStateStore(s.HTTPAddr, "")
+	c.StateStore = store
 
 	// Setup a worker pool object.
 	workerPool := &structs.WorkerPool{
@@ -73,8 +73,8 @@ func TestFailsafe_SetFailsafeMode(t *testing.T) {
 	c, s := testutil.MakeClientWithConfig(t)
 	defer s.Stop()
 
-	consul, _ := client.NewConsulClient(s.HTTPAddr, "")
-	c.ConsulClient = consul
+	store, _ := client.NewConsulStateStore(s.HTTPAddr, "")
+	c.StateStore = store
 
 	// Setup a worker pool object.
 	workerPool := &structs.WorkerPool{
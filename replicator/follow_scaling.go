@@ -0,0 +1,125 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// followerScaling sets the count of a job group which follows another job
+// group. The returned value indicates whether a scaling operation was
+// requested.
+func (s *Server) followerScaling(ctx context.Context, jobName string,
+	group *structs.GroupScalingPolicy, policies map[string][]*structs.GroupScalingPolicy,
+	state *structs.ScalingState) bool {
+
+	if cycle := followCycle(policies, jobName, group.GroupName); cycle != nil {
+		logging.Error("core/follow_scaling: job \"%v\" and group \"%v\" will not be "+
+			"scaled as a follow cycle was detected: %v", jobName, group.GroupName,
+			strings.Join(cycle, " -> "))
+		return false
+	}
+
+	followJob, followGroup, err := parseFollow(group.Follow)
+	if err != nil {
+		logging.Error("core/follow_scaling: job \"%v\" and group \"%v\" has an "+
+			"invalid follow policy: %v", jobName, group.GroupName, err)
+		return false
+	}
+
+	count, err := s.Config().NomadClient.GetJobGroupCount(followJob, followGroup)
+	if err != nil {
+		logging.Error("core/follow_scaling: unable to determine the count of "+
+			"followed job \"%v\" and group \"%v\": %v", followJob, followGroup, err)
+		return false
+	}
+
+	desired := followerCount(count, group)
+
+	if !group.Enabled {
+		logging.Debug("core/follow_scaling: job scaling has been disabled; a count "+
+			"of %v would have been requested for job \"%v\" and group \"%v\"",
+			desired, jobName, group.GroupName)
+		return false
+	}
+
+	logging.Debug("core/follow_scaling: job \"%v\" and group \"%v\" follows %v "+
+		"with a count of %v; a count of %v will be requested", jobName,
+		group.GroupName, group.Follow, count, desired)
+
+	event := s.Config().NomadClient.JobGroupScaleTo(ctx, jobName, group, desired, state)
+	if event != nil {
+		event.Metrics = map[string]float64{"followed_count": float64(count)}
+		recordJobGroupEvent(s.Config(), state, event)
+	}
+
+	return true
+}
+
+// followerCount calculates the desired count of a follower group from the
+// count of the group it follows, bounded by the follower's min and max.
+func followerCount(count int, group *structs.GroupScalingPolicy) int {
+	desired := int(math.Ceil(float64(count)*group.FollowRatio)) + group.FollowOffset
+
+	if desired < group.Min {
+		return group.Min
+	}
+	if desired > group.Max {
+		return group.Max
+	}
+	return desired
+}
+
+// parseFollow splits a follow reference of the form job/group into its job
+// and group names.
+func parseFollow(follow string) (job, group string, err error) {
+	i := strings.LastIndex(follow, "/")
+	if i <= 0 || i == len(follow)-1 {
+		return "", "", fmt.Errorf("follow reference %q must be of the form "+
+			"job/group", follow)
+	}
+
+	return follow[:i], follow[i+1:], nil
+}
+
+// followCycle walks the chain of follow policies starting at the specified
+// job group. If the chain loops back on itself the path of the cycle is
+// returned, otherwise nil is returned.
+func followCycle(policies map[string][]*structs.GroupScalingPolicy,
+	jobName, groupName string) []string {
+
+	key := jobName + "/" + groupName
+	path := []string{key}
+	visited := map[string]bool{key: true}
+
+	for {
+		job, group, err := parseFollow(key)
+		if err != nil {
+			return nil
+		}
+
+		var policy *structs.GroupScalingPolicy
+		for _, p := range policies[job] {
+			if p.GroupName == group {
+				policy = p
+				break
+			}
+		}
+
+		if policy == nil || policy.Follow == "" {
+			return nil
+		}
+
+		key = policy.Follow
+		path = append(path, key)
+
+		if visited[key] {
+			return path
+		}
+		visited[key] = true
+	}
+}
@@ -0,0 +1,84 @@
+package replicator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestFollowScaling_followerCount(t *testing.T) {
+	group := &structs.GroupScalingPolicy{
+		FollowRatio: 0.5,
+		Min:         2,
+		Max:         6,
+	}
+
+	cases := map[int]int{
+		1:  2, // Bounded by min.
+		5:  3, // Rounded up.
+		8:  4,
+		20: 6, // Bounded by max.
+	}
+
+	for count, expected := range cases {
+		if desired := followerCount(count, group); desired != expected {
+			t.Fatalf("expected count of %v to return %v but got %v", count,
+				expected, desired)
+		}
+	}
+
+	group.FollowOffset = 1
+	if desired := followerCount(8, group); desired != 5 {
+		t.Fatalf("expected offset count of 5 but got %v", desired)
+	}
+}
+
+func TestFollowScaling_parseFollow(t *testing.T) {
+	job, group, err := parseFollow("api/web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job != "api" || group != "web" {
+		t.Fatalf("expected api and web but got %v and %v", job, group)
+	}
+
+	for _, follow := range []string{"api", "/web", "api/", ""} {
+		if _, _, err := parseFollow(follow); err == nil {
+			t.Fatalf("expected error parsing %q", follow)
+		}
+	}
+}
+
+func TestFollowScaling_followCycle(t *testing.T) {
+	policies := map[string][]*structs.GroupScalingPolicy{
+		"api": {
+			{GroupName: "web"},
+		},
+		"worker": {
+			{GroupName: "consumer", Follow: "api/web"},
+			{GroupName: "reporter", Follow: "worker/consumer"},
+		},
+	}
+
+	if cycle := followCycle(policies, "worker", "reporter"); cycle != nil {
+		t.Fatalf("expected no cycle but got %v", cycle)
+	}
+
+	policies["api"][0].Follow = "worker/reporter"
+
+	expected := []string{"worker/consumer", "api/web", "worker/reporter",
+		"worker/consumer"}
+
+	cycle := followCycle(policies, "worker", "consumer")
+	if !reflect.DeepEqual(cycle, expected) {
+		t.Fatalf("expected cycle %v but got %v", expected, cycle)
+	}
+
+	policies["self"] = []*structs.GroupScalingPolicy{
+		{GroupName: "group", Follow: "self/group"},
+	}
+	if cycle := followCycle(policies, "self", "group"); cycle == nil {
+		t.Fatalf("expected a group following itself to be detected as a cycle")
+	}
+}
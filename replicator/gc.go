@@ -0,0 +1,156 @@
+package replicator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// gcTicker periodically garbage collects the state of worker pools and job
+// groups which no longer exist while running as the leader. The first run
+// occurs after a full interval so that the worker pool and job watchers have
+// synchronized.
+func (s *Server) gcTicker(ctx context.Context) {
+	ticker := time.NewTicker(
+		time.Second * time.Duration(s.Config().State.GCInterval),
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if s.candidate.isLeader() {
+				s.stateGC(time.Now())
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// stateGC tombstones the state of worker pools and job groups which are no
+// longer tracked and deletes tombstoned state once the resource has been
+// absent beyond the grace period. Resources are only collected when the
+// watcher responsible for discovering them is running.
+func (s *Server) stateGC(now time.Time) {
+	root := s.Config().ConsulKeyRoot + "/state/"
+
+	if !s.Config().ClusterScalingDisable {
+		s.gcResources(root+"nodes/", s.workerPoolExists, now)
+	}
+
+	if !s.Config().ClusterScalingDisable || !s.Config().JobScalingDisable {
+		s.gcResources(root+"jobs/", s.jobGroupExists, now)
+	}
+}
+
+// gcResources performs garbage collection of the state objects stored under
+// the provided prefix. The exists function reports whether the resource
+// identified by the remainder of the state path is still tracked.
+func (s *Server) gcResources(prefix string, exists func(string) bool, now time.Time) {
+	stateStore := s.Config().StateStore
+	grace := time.Duration(s.Config().State.GCGracePeriod) * time.Second
+
+	states, err := stateStore.ListState(prefix)
+	if err != nil {
+		logging.Error("core/gc: unable to list state for garbage collection: %v", err)
+		return
+	}
+
+	for _, state := range states {
+		resource := strings.TrimPrefix(state.StatePath, prefix)
+
+		switch {
+		case exists(resource):
+			// Resources which reappear after being tombstoned start afresh.
+			if reviveState(state) {
+				if err := stateStore.PersistState(state); err != nil {
+					logging.Error("core/gc: %v", err)
+				}
+			}
+
+		case state.Pinned:
+			logging.Debug("core/gc: state at location %v is pinned and will not be "+
+				"garbage collected", state.StatePath)
+
+		case state.Tombstone.IsZero():
+			logging.Info("core/gc: resource %v no longer exists, its state at "+
+				"location %v will be deleted after %v", resource, state.StatePath, grace)
+
+			state.Tombstone = now
+			if err := stateStore.PersistState(state); err != nil {
+				logging.Error("core/gc: %v", err)
+				continue
+			}
+			metrics.IncrCounter([]string{"state", "gc", "tombstoned"}, 1)
+
+		case now.Sub(state.Tombstone) >= grace:
+			logging.Info("core/gc: resource %v has been absent since %v, deleting "+
+				"its state at location %v", resource, state.Tombstone, state.StatePath)
+
+			if err := stateStore.DeleteState(state); err != nil {
+				logging.Error("core/gc: %v", err)
+				continue
+			}
+			metrics.IncrCounter([]string{"state", "gc", "deleted"}, 1)
+		}
+	}
+}
+
+// reviveState prepares the state of a resource which has reappeared after
+// being tombstoned by garbage collection. Unless the state has been pinned it
+// is reset so the resource does not inherit stale failsafe or scaling
+// state. The returned value indicates whether the state was modified.
+func reviveState(state *structs.ScalingState) bool {
+	if state.Tombstone.IsZero() {
+		return false
+	}
+
+	if state.Pinned {
+		logging.Info("core/gc: resource at location %v has reappeared and its "+
+			"state is pinned, existing state will be retained", state.StatePath)
+		state.Tombstone = time.Time{}
+		return true
+	}
+
+	logging.Info("core/gc: resource at location %v has reappeared, its state "+
+		"will be reset", state.StatePath)
+	state.Reset()
+
+	return true
+}
+
+// workerPoolExists reports whether a worker pool is present in the node
+// registry.
+func (s *Server) workerPoolExists(pool string) bool {
+	s.nodeRegistry.Lock.RLock()
+	defer s.nodeRegistry.Lock.RUnlock()
+
+	_, ok := s.nodeRegistry.WorkerPools[pool]
+	return ok
+}
+
+// jobGroupExists reports whether a job group, identified by the job and group
+// names joined by a slash, has a tracked scaling policy.
+func (s *Server) jobGroupExists(resource string) bool {
+	parts := strings.SplitN(resource, "/", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	s.jobScalingPolicies.Lock.RLock()
+	defer s.jobScalingPolicies.Lock.RUnlock()
+
+	for _, group := range s.jobScalingPolicies.Policies[parts[0]] {
+		if group.GroupName == parts[1] {
+			return true
+		}
+	}
+
+	return false
+}
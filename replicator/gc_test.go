@@ -0,0 +1,86 @@
+package replicator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestGC_StateGC(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	s := &Server{
+		config: &structs.Config{
+			ConsulKeyRoot: "replicator/config",
+			State:         &structs.State{GCGracePeriod: 3600},
+			StateStore:    store,
+		},
+		jobScalingPolicies: newJobScalingPolicy(),
+		nodeRegistry:       structs.NewNodeRegistry(),
+	}
+
+	s.nodeRegistry.WorkerPools["present"] = &structs.WorkerPool{Name: "present"}
+	s.jobScalingPolicies.Policies["example"] = []*structs.GroupScalingPolicy{
+		{GroupName: "cache"},
+	}
+
+	root := "replicator/config/state/"
+	now := time.Now()
+
+	for _, state := range []*structs.ScalingState{
+		{StatePath: root + "nodes/present", FailureCount: 1},
+		{StatePath: root + "nodes/removed", FailsafeMode: true},
+		{StatePath: root + "jobs/example/web", Pinned: true},
+		{StatePath: root + "jobs/example/cache", FailsafeMode: true, Tombstone: now},
+	} {
+		if err := store.PersistState(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(path string) *structs.ScalingState {
+		state := &structs.ScalingState{StatePath: root + path}
+		store.ReadState(state, false)
+		return state
+	}
+
+	s.stateGC(now)
+
+	if state := read("nodes/removed"); state.Tombstone.IsZero() {
+		t.Fatalf("expected state of a removed worker pool to be tombstoned")
+	}
+	if state := read("nodes/present"); !state.Tombstone.IsZero() || state.FailureCount != 1 {
+		t.Fatalf("expected state of an existing worker pool to be unmodified: %+v", state)
+	}
+	if state := read("jobs/example/web"); !state.Tombstone.IsZero() {
+		t.Fatalf("expected pinned state not to be tombstoned")
+	}
+	if state := read("jobs/example/cache"); !state.Tombstone.IsZero() || state.FailsafeMode {
+		t.Fatalf("expected state of a reappeared job group to be reset: %+v", state)
+	}
+
+	// Once the grace period has passed the tombstoned state is deleted.
+	s.stateGC(now.Add(2 * time.Hour))
+
+	if state := read("nodes/removed"); !state.LastUpdated.IsZero() {
+		t.Fatalf("expected state of a removed worker pool to be deleted")
+	}
+	if state := read("jobs/example/web"); state.LastUpdated.IsZero() {
+		t.Fatalf("expected pinned state to be retained")
+	}
+}
@@ -0,0 +1,79 @@
+package replicator
+
+import (
+	"os"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// recordScalingEvent appends an event to the scaling event history of the
+// resource tracked by the state object. Failures to record the event are
+// logged but do not affect the outcome of the scaling operation.
+func recordScalingEvent(config *structs.Config, state *structs.ScalingState,
+	event *structs.ScalingEvent) {
+
+	path, resourceID := structs.HistoryPath(state.StatePath)
+	if path == "" {
+		return
+	}
+
+	event.ResourceID = resourceID
+	event.ResourceType = state.ResourceType
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	if event.NodeID == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			event.NodeID = hostname
+		}
+	}
+
+	publishScalingEvent(config, path, event)
+
+	if config.StateStore == nil {
+		return
+	}
+
+	if err := config.StateStore.AppendEvent(path, event, config.History); err != nil {
+		logging.Error("core/history: unable to record scaling event for %v %v: %v",
+			state.ResourceType, resourceID, err)
+	}
+}
+
+// jobGroupMetrics returns the metric values used to evaluate scaling of a job
+// group for inclusion in a scaling event.
+func jobGroupMetrics(group *structs.GroupScalingPolicy, slope float64) map[string]float64 {
+	metrics := map[string]float64{
+		"cpu_percent":    group.Tasks.Resources.CPUPercent,
+		"memory_percent": group.Tasks.Resources.MemoryPercent,
+	}
+
+	if group.EmergencySlope > 0 {
+		metrics["utilization_slope"] = slope
+	}
+
+	return metrics
+}
+
+// workerPoolMetrics returns the metric values used to evaluate scaling of a
+// worker pool for inclusion in a scaling event.
+func workerPoolMetrics(workerPool *structs.WorkerPool,
+	capacity *structs.ClusterCapacity, slope float64) map[string]float64 {
+
+	metric := capacity.ScalingMetric
+	metrics := map[string]float64{
+		metric.Type + "_capacity":    float64(metric.Capacity),
+		metric.Type + "_utilization": float64(metric.Utilization),
+		"max_allowed_utilization":    float64(capacity.MaxAllowedUtilization),
+	}
+
+	if workerPool.EmergencySlope > 0 {
+		metrics["utilization_slope"] = slope
+	}
+
+	return metrics
+}
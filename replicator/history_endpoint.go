@@ -0,0 +1,59 @@
+package replicator
+
+import (
+	"fmt"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// History endpoint is used to query the scaling event history of worker
+// pools and job groups.
+type History struct {
+	srv *Server
+}
+
+// List returns the scaling event history of a worker pool, a job group or
+// all groups of a job, sorted oldest first.
+func (h *History) List(args *structs.HistoryRequest, reply *structs.HistoryListResponse) error {
+	resource := structs.ACLResourceJob
+	if args.Pool != "" {
+		resource = structs.ACLResourcePool
+	}
+
+	if _, err := h.srv.authorize(args.AuthToken, resource,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	root := h.srv.Config().ConsulKeyRoot + "/history/"
+
+	var prefix, resourceID string
+	switch {
+	case args.Pool != "":
+		prefix = root + "nodes/" + args.Pool
+		resourceID = args.Pool
+	case args.Job != "" && args.Group != "":
+		prefix = root + "jobs/" + args.Job + "/" + args.Group
+		resourceID = args.Job + "/" + args.Group
+	case args.Job != "":
+		prefix = root + "jobs/" + args.Job + "/"
+	default:
+		return fmt.Errorf("a worker pool or job must be specified")
+	}
+
+	events, err := h.srv.Config().StateStore.ListEvents(prefix)
+	if err != nil {
+		return err
+	}
+
+	// Prefix matching may include resources whose names begin with the name
+	// requested, so only return events for the exact resource.
+	reply.Events = make([]*structs.ScalingEvent, 0, len(events))
+	for _, event := range events {
+		if resourceID == "" || event.ResourceID == resourceID {
+			reply.Events = append(reply.Events, event)
+		}
+	}
+
+	return nil
+}
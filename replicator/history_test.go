@@ -0,0 +1,86 @@
+package replicator
+
+import (
+	"io/ioutil"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestHistory_RecordAndList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+
+	config := &structs.Config{
+		ConsulKeyRoot: "replicator/config",
+		EventBroker:   structs.NewEventBroker(),
+		History:       &structs.History{MaxEvents: 10},
+		StateStore:    store,
+	}
+
+	// Recorded events are published to the event stream.
+	sub := config.EventBroker.Subscribe([]structs.EventTopic{
+		{Topic: structs.TopicJob, Key: "example"},
+	})
+
+	s := &Server{config: config, rpcServer: rpc.NewServer()}
+	s.rpcServer.Register(&History{s})
+	defer s.config.StateStore.(interface{ Close() error }).Close()
+
+	for _, path := range []string{"state/nodes/example", "state/nodes/example-2",
+		"state/jobs/example/cache"} {
+		state := &structs.ScalingState{
+			ResourceType: ClusterType,
+			StatePath:    config.ConsulKeyRoot + "/" + path,
+		}
+
+		recordScalingEvent(config, state, &structs.ScalingEvent{
+			Outcome: structs.EventOutcomeSuccess,
+			Type:    structs.EventTypeScale,
+		})
+	}
+
+	if n := len(sub.Events()); n != 1 {
+		t.Fatalf("expected a single published event but got %v", n)
+	}
+	if event := <-sub.Events(); event.Key != "example/cache" ||
+		event.Type != structs.StreamEventScaling {
+		t.Fatalf("unexpected published event %#v", event)
+	}
+
+	var out structs.HistoryListResponse
+	args := &structs.HistoryRequest{Pool: "example"}
+	if err := s.RPC("History.List", args, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(out.Events) != 1 || out.Events[0].ResourceID != "example" ||
+		out.Events[0].NodeID == "" || out.Events[0].Timestamp.IsZero() {
+		t.Fatalf("expected a single event for worker pool example but got %#v",
+			out.Events)
+	}
+
+	args = &structs.HistoryRequest{Job: "example"}
+	if err := s.RPC("History.List", args, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Events) != 1 || out.Events[0].ResourceID != "example/cache" {
+		t.Fatalf("expected a single event for job example but got %#v", out.Events)
+	}
+
+	if err := s.RPC("History.List", &structs.HistoryRequest{}, &out); err == nil {
+		t.Fatalf("expected an error when no resource is specified")
+	}
+}
@@ -1,12 +1,14 @@
 package replicator
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/helper"
 	"github.com/elsevier-core-engineering/replicator/logging"
 	"github.com/elsevier-core-engineering/replicator/notifier"
 	"github.com/elsevier-core-engineering/replicator/replicator/structs"
@@ -22,7 +24,11 @@ func newJobScalingPolicy() *structs.JobScalingPolicies {
 	}
 }
 
-func (s *Server) asyncJobScaling(jobScalingPolicies *structs.JobScalingPolicies) {
+// asyncJobScaling triggers concurrent scaling evaluations for each job with a
+// scaling policy. Scaling operations are interrupted when the context is
+// cancelled.
+func (s *Server) asyncJobScaling(ctx context.Context,
+	jobScalingPolicies *structs.JobScalingPolicies) {
 	// Setup our wait group to ensure we block until all worker pool scaling
 	// operations have completed.
 	var wg sync.WaitGroup
@@ -37,7 +43,7 @@ func (s *Server) asyncJobScaling(jobScalingPolicies *structs.JobScalingPolicies)
 	jobs := make(chan string, jobCount)
 
 	// Calculate the number of worker threads to initiate.
-	maxConcurrency := s.config.ScalingConcurrency
+	maxConcurrency := s.Config().ScalingConcurrency
 
 	if jobCount < maxConcurrency {
 		maxConcurrency = jobCount
@@ -48,12 +54,12 @@ func (s *Server) asyncJobScaling(jobScalingPolicies *structs.JobScalingPolicies)
 
 	// Initiate workers to implement job scaling.
 	for w := 1; w <= maxConcurrency; w++ {
-		go s.jobScaling(w, jobs, jobScalingPolicies, &wg)
+		go s.jobScaling(ctx, w, jobs, jobScalingPolicies, &wg)
 	}
 
 	// Add jobs to the worker channel.
 	for job := range jobScalingPolicies.Policies {
-		if s.config.NomadClient.IsJobInDeployment(job) {
+		if s.Config().NomadClient.IsJobInDeployment(job) {
 			logging.Debug("core/job_scaling: job %s is in deployment, no scaling "+
 				"evaluation will be triggered", job)
 			continue
@@ -63,12 +69,12 @@ func (s *Server) asyncJobScaling(jobScalingPolicies *structs.JobScalingPolicies)
 	}
 }
 
-func (s *Server) jobScaling(id int, jobs <-chan string,
+func (s *Server) jobScaling(ctx context.Context, id int, jobs <-chan string,
 	jobScalingPolicies *structs.JobScalingPolicies, wg *sync.WaitGroup) {
 
-	// Setup references to clients for Nomad and Consul.
-	nomadClient := s.config.NomadClient
-	consulClient := s.config.ConsulClient
+	// Setup references to the Nomad client and state store.
+	nomadClient := s.Config().NomadClient
+	stateStore := s.Config().StateStore
 
 	for jobName := range jobs {
 		func() {
@@ -82,7 +88,7 @@ func (s *Server) jobScaling(id int, jobs <-chan string,
 			// in a read/write lock and remove this as soon as possible as the
 			// remaining functions only need a read lock.
 			jobScalingPolicies.Lock.Lock()
-			err := nomadClient.EvaluateJobScaling(jobName, g)
+			err := nomadClient.EvaluateJobScaling(jobName, g, s.utilization)
 			jobScalingPolicies.Lock.Unlock()
 
 			// Horrible but required for jobs that have been purged as the policy
@@ -91,6 +97,7 @@ func (s *Server) jobScaling(id int, jobs <-chan string,
 			// github.com/hashicorp/nomad/issues/1849
 			if err != nil && strings.Contains(err.Error(), "404") {
 				client.RemoveJobScalingPolicy(jobName, jobScalingPolicies)
+				s.utilization.Remove(jobName)
 
 				return
 			} else if err != nil {
@@ -103,54 +110,132 @@ func (s *Server) jobScaling(id int, jobs <-chan string,
 			jobScalingPolicies.Lock.RLock()
 
 			for _, group := range g {
-				// Setup a failure message to pass to the failsafe check.
-				message := &notifier.FailureMessage{
-					AlertUID:     group.UID,
-					ResourceID:   fmt.Sprintf("%s/%s", jobName, group.GroupName),
-					ResourceType: JobType,
-				}
+				publishEvaluation(s.Config(), structs.TopicJob,
+					jobName+"/"+group.GroupName, group.ScaleDirection,
+					map[string]float64{
+						"cpu_percent":    group.Tasks.Resources.CPUPercent,
+						"memory_percent": group.Tasks.Resources.MemoryPercent,
+					}, nil)
+			}
 
-				// Read our JobGroup state and check failsafe.
-				state := &structs.ScalingState{
-					ResourceName: group.GroupName,
-					ResourceType: JobType,
-					StatePath: s.config.ConsulKeyRoot + "/state/jobs/" + jobName +
-						"/" + group.GroupName,
+			for _, group := range g {
+				// Stop evaluating groups once leadership has been lost.
+				if ctx.Err() != nil {
+					break
 				}
-				consulClient.ReadState(state, true)
 
-				if !FailsafeCheck(state, s.config, group.RetryThreshold, message) {
-					logging.Error("core/job_scaling: job \"%v\" and group \"%v\" is in "+
-						"failsafe mode", jobName, group.GroupName)
+				// Skip groups with a manual scaling operation in progress.
+				resourceID := jobName + "/" + group.GroupName
+				if !s.lockResource(JobType + "/" + resourceID) {
+					logging.Debug("core/job_scaling: job \"%v\" and group \"%v\" has "+
+						"a scaling operation in progress and will not be evaluated",
+						jobName, group.GroupName)
 					continue
 				}
 
-				// Check the JobGroup scaling cooldown.
-				cd := time.Duration(group.Cooldown) * time.Second
+				func() {
+					defer s.unlockResource(JobType + "/" + resourceID)
 
-				if !state.LastScalingEvent.Before(time.Now().Add(-cd)) {
-					logging.Debug("core/job_scaling: job \"%v\" and group \"%v\" has not reached scaling cooldown threshold of %s",
-						jobName, group.GroupName, cd)
-					continue
-				}
+					// Setup a failure message to pass to the failsafe check.
+					message := &notifier.FailureMessage{
+						AlertUID:     group.UID,
+						ResourceID:   fmt.Sprintf("%s/%s", jobName, group.GroupName),
+						ResourceType: JobType,
+					}
 
-				if group.ScaleDirection == client.ScalingDirectionOut || group.ScaleDirection == client.ScalingDirectionIn {
-					if group.Enabled {
-						logging.Debug("core/job_scaling: scaling for job \"%v\" and group \"%v\" is enabled; a "+
-							"scaling operation (%v) will be requested", jobName, group.GroupName, group.ScaleDirection)
+					// Read our JobGroup state and check failsafe.
+					state := &structs.ScalingState{
+						ResourceName: group.GroupName,
+						ResourceType: JobType,
+						StatePath: s.Config().ConsulKeyRoot + "/state/jobs/" + jobName +
+							"/" + group.GroupName,
+					}
+					stateStore.ReadState(state, true)
 
-						// Submit the job and group for scaling.
-						nomadClient.JobGroupScale(jobName, group, state)
+					// Reset state left behind by a removed job of the same name.
+					if reviveState(state) {
+						stateStore.PersistState(state)
+					}
 
-					} else {
-						logging.Debug("core/job_scaling: job scaling has been disabled; a "+
-							"scaling operation (%v) would have been requested for \"%v\" "+
-							"and group \"%v\"", group.ScaleDirection, jobName, group.GroupName)
+					// Recover any operation interrupted by a previous leader.
+					if s.recoverOperation(state) {
+						stateStore.PersistState(state)
+					}
+
+					if !FailsafeCheck(state, s.Config(), group.RetryThreshold, message) {
+						logging.Error("core/job_scaling: job \"%v\" and group \"%v\" is in "+
+							"failsafe mode", jobName, group.GroupName)
+						return
+					}
+
+					// Track the rate of change of the group utilization when emergency
+					// scaling is configured.
+					var slope float64
+					if group.EmergencySlope > 0 {
+						slope = state.UtilizationSlope(helper.Max(
+							group.Tasks.Resources.CPUPercent,
+							group.Tasks.Resources.MemoryPercent), time.Now())
+					}
+
+					// Check the JobGroup scaling cooldown for the requested direction
+					// unless utilization is climbing fast enough to warrant an
+					// emergency scale-out.
+					if emergencyScaleOut(group.ScaleDirection, slope, group.EmergencySlope) {
+						logging.Info("core/job_scaling: utilization of job \"%v\" and group "+
+							"\"%v\" is increasing at %.2f%% per minute, exceeding the "+
+							"emergency slope of %v%%; cooldown will be bypassed",
+							jobName, group.GroupName, slope, group.EmergencySlope)
+
+					} else if expiry := cooldownExpiry(state, group.ScaleDirection,
+						time.Duration(group.Cooldown)*time.Second,
+						time.Duration(group.ScaleInCooldown)*time.Second,
+						time.Duration(group.ScaleOutCooldown)*time.Second); !time.Now().After(expiry) {
+						logging.Debug("core/job_scaling: job \"%v\" and group \"%v\" has not "+
+							"reached scaling cooldown threshold %v", jobName, group.GroupName, expiry)
+
+						if group.EmergencySlope > 0 {
+							stateStore.PersistState(state)
+						}
+						return
+					}
+
+					// Horizontal scaling takes precedence; vertical scaling is only
+					// evaluated when no horizontal scaling operation is requested during
+					// this cycle so the two never act on a group at the same time.
+					var scaled bool
+
+					if group.Follow != "" {
+						scaled = s.followerScaling(ctx, jobName, group,
+							jobScalingPolicies.Policies, state)
+
+					} else if group.ScaleDirection == client.ScalingDirectionOut ||
+						group.ScaleDirection == client.ScalingDirectionIn {
+						if group.Enabled {
+							scaled = true
+							logging.Debug("core/job_scaling: scaling for job \"%v\" and group \"%v\" is enabled; a "+
+								"scaling operation (%v) will be requested", jobName, group.GroupName, group.ScaleDirection)
+
+							// Submit the job and group for scaling and record the outcome in
+							// the scaling event history.
+							if event := nomadClient.JobGroupScale(ctx, jobName, group, state); event != nil {
+								event.Metrics = jobGroupMetrics(group, slope)
+								recordJobGroupEvent(s.Config(), state, event)
+							}
+
+						} else {
+							logging.Debug("core/job_scaling: job scaling has been disabled; a "+
+								"scaling operation (%v) would have been requested for \"%v\" "+
+								"and group \"%v\"", group.ScaleDirection, jobName, group.GroupName)
+						}
+					}
+
+					if !scaled && group.VerticalEnabled {
+						s.verticalScaling(ctx, jobName, group, state)
 					}
-				}
 
-				// Persist our state to Consul.
-				consulClient.PersistState(state)
+					// Persist our state to the state store.
+					stateStore.PersistState(state)
+				}()
 			}
 
 			// Release our read-only lock.
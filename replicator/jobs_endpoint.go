@@ -0,0 +1,101 @@
+package replicator
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Jobs endpoint is used to query the scaling policies and status of the job
+// groups tracked by Replicator.
+type Jobs struct {
+	srv *Server
+}
+
+// List returns the scaling status of every job group with a scaling policy,
+// sorted by job and group. Scaling evaluations are only performed by the
+// leader so requests are forwarded to it.
+func (j *Jobs) List(args *structs.QueryOptions, reply *structs.JobListResponse) error {
+	if done, err := j.srv.forward("Jobs.List", args, reply); done {
+		return err
+	}
+
+	if _, err := j.srv.authorize(args.AuthToken, structs.ACLResourceJob,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	reply.Groups = j.srv.jobGroupStatus("")
+	return nil
+}
+
+// Get returns the scaling status of every group of a job. No groups are
+// returned if the job does not have a scaling policy.
+func (j *Jobs) Get(args *structs.JobRequest, reply *structs.JobListResponse) error {
+	if done, err := j.srv.forward("Jobs.Get", args, reply); done {
+		return err
+	}
+
+	if _, err := j.srv.authorize(args.AuthToken, structs.ACLResourceJob,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	reply.Groups = j.srv.jobGroupStatus(args.JobID)
+	return nil
+}
+
+// jobGroupStatus builds the scaling status of the groups of the specified
+// job, or of all jobs if no job is specified.
+func (s *Server) jobGroupStatus(jobID string) []*structs.JobGroupStatus {
+	policies := s.jobScalingPolicies
+
+	policies.Lock.RLock()
+	groups := make([]*structs.JobGroupStatus, 0)
+	for job, groupPolicies := range policies.Policies {
+		if jobID != "" && job != jobID {
+			continue
+		}
+
+		for _, group := range groupPolicies {
+			groups = append(groups, &structs.JobGroupStatus{
+				JobID:  job,
+				Policy: *group,
+			})
+		}
+	}
+	policies.Lock.RUnlock()
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].JobID != groups[j].JobID {
+			return groups[i].JobID < groups[j].JobID
+		}
+		return groups[i].Policy.GroupName < groups[j].Policy.GroupName
+	})
+
+	now := time.Now()
+	for _, status := range groups {
+		group := &status.Policy
+
+		state := &structs.ScalingState{
+			StatePath: s.Config().ConsulKeyRoot + "/state/jobs/" + status.JobID +
+				"/" + group.GroupName,
+		}
+		s.Config().StateStore.ReadState(state, false)
+
+		status.FailsafeMode = state.FailsafeMode
+		status.FailureCount = state.FailureCount
+
+		expiry := cooldownExpiry(state, group.ScaleDirection,
+			time.Duration(group.Cooldown)*time.Second,
+			time.Duration(group.ScaleInCooldown)*time.Second,
+			time.Duration(group.ScaleOutCooldown)*time.Second)
+		if remaining := expiry.Sub(now); remaining > 0 {
+			status.CooldownRemaining = int(math.Ceil(remaining.Seconds()))
+		}
+	}
+
+	return groups
+}
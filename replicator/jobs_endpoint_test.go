@@ -0,0 +1,97 @@
+package replicator
+
+import (
+	"io/ioutil"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestJobs_ListAndGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	s := &Server{
+		candidate: &LeaderCandidate{leader: true},
+		config: &structs.Config{
+			ConsulKeyRoot: "replicator/config",
+			StateStore:    store,
+		},
+		jobScalingPolicies: newJobScalingPolicy(),
+		rpcServer:          rpc.NewServer(),
+	}
+	s.rpcServer.Register(&Jobs{s})
+
+	cache := structs.NewGroupScalingPolicy()
+	cache.GroupName = "cache"
+	cache.ScaleDirection = structs.ScalingDirectionOut
+	cache.Tasks.Resources.CPUPercent = 85
+
+	web := structs.NewGroupScalingPolicy()
+	web.GroupName = "web"
+
+	s.jobScalingPolicies.Policies["example"] = []*structs.GroupScalingPolicy{web, cache}
+	s.jobScalingPolicies.Policies["other"] = []*structs.GroupScalingPolicy{
+		structs.NewGroupScalingPolicy(),
+	}
+
+	// The cache group has recently scaled out and is in failsafe mode.
+	state := &structs.ScalingState{
+		FailsafeMode: true,
+		FailureCount: 2,
+		StatePath:    "replicator/config/state/jobs/example/cache",
+	}
+	state.RecordScalingEvent(structs.ScalingDirectionOut)
+	if err := store.PersistState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	var out structs.JobListResponse
+	if err := s.RPC("Jobs.List", nil, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Groups) != 3 || out.Groups[2].JobID != "other" {
+		t.Fatalf("expected three groups sorted by job but got %#v", out.Groups)
+	}
+
+	out = structs.JobListResponse{}
+	if err := s.RPC("Jobs.Get", &structs.JobRequest{JobID: "example"}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Groups) != 2 {
+		t.Fatalf("expected two groups for job example but got %#v", out.Groups)
+	}
+
+	status := out.Groups[0]
+	if status.Policy.GroupName != "cache" || status.Policy.Tasks.Resources.CPUPercent != 85 ||
+		!status.FailsafeMode || status.FailureCount != 2 ||
+		status.CooldownRemaining <= 0 || status.CooldownRemaining > 60 {
+		t.Fatalf("unexpected status for group cache: %#v", status)
+	}
+
+	if out.Groups[1].CooldownRemaining != 0 || out.Groups[1].FailsafeMode {
+		t.Fatalf("expected group web to have no cooldown or failsafe but got %#v",
+			out.Groups[1])
+	}
+
+	out = structs.JobListResponse{}
+	if err := s.RPC("Jobs.Get", &structs.JobRequest{JobID: "missing"}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Groups) != 0 {
+		t.Fatalf("expected no groups for an unknown job but got %#v", out.Groups)
+	}
+}
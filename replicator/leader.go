@@ -1,6 +1,11 @@
 package replicator
 
 import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/raft"
+
 	"github.com/elsevier-core-engineering/replicator/logging"
 	"github.com/elsevier-core-engineering/replicator/replicator/structs"
 )
@@ -8,16 +13,27 @@ import (
 const (
 	leaderElectionInterval = 10
 	leaderLockTimeout      = 12
+
+	// leadershipMonitorInterval is the interval at which the server checks
+	// for changes in leadership in order to cancel in-flight scaling
+	// operations once leadership is lost.
+	leadershipMonitorInterval = time.Second
 )
 
 // LeaderCandidate runs the leader election.
 type LeaderCandidate struct {
+	// advertise is the advertised RPC address published by the candidate when
+	// it acquires leadership.
+	advertise string
+
 	consulClient structs.ConsulClient
 
-	leader  bool
-	key     string
-	session string
-	ttl     int
+	leader     bool
+	key        string
+	raft       *raft.Raft
+	session    string
+	standalone bool
+	ttl        int
 
 	renewChan chan struct{}
 }
@@ -34,6 +50,9 @@ func newLeaderCandidate(consulClient structs.ConsulClient, key string, ttl int)
 
 // isLeader returns true if the candidate is currently a leader.
 func (l *LeaderCandidate) isLeader() bool {
+	if l.raft != nil {
+		return l.raft.State() == raft.Leader
+	}
 	return l.leader
 }
 
@@ -51,6 +70,7 @@ func (l *LeaderCandidate) leaderElection() (isLeader bool) {
 		id, err := l.consulClient.CreateSession(l.ttl, l.renewChan)
 		if err != nil {
 			logging.Error("core/leader: unable to obtain Consul session: %v", err)
+			l.leader = false
 			return
 		}
 
@@ -59,13 +79,14 @@ func (l *LeaderCandidate) leaderElection() (isLeader bool) {
 	}
 
 	// Attempt to acquire the leadership lock.
-	if isLeader = l.consulClient.AcquireLeadership(l.key, &l.session); isLeader {
+	if isLeader = l.consulClient.AcquireLeadership(l.key, &l.session, l.advertise); isLeader {
 		logging.Debug("core/leader: currently running as Replicator leader")
 		l.leader = true
 		return true
 	}
 
 	logging.Debug("core/leader: failed to acquire leadership lock")
+	l.leader = false
 	return
 }
 
@@ -73,6 +94,10 @@ func (l *LeaderCandidate) leaderElection() (isLeader bool) {
 // the replicator leadership locking, allowing other daemons to pick up the lock
 // without having to wait for the TTL to expire.
 func (l *LeaderCandidate) endCampaign() {
+	if l.standalone || l.raft != nil {
+		return
+	}
+
 	logging.Info("core/leader: gracefully cleaning up Consul sessions and locks")
 
 	l.releaseSession()
@@ -89,3 +114,62 @@ func (l *LeaderCandidate) releaseSession() {
 		close(l.renewChan)
 	}
 }
+
+// monitorLeadership watches for changes in the leadership of the server and
+// maintains the context passed to scaling operations so that any operations
+// in progress are cancelled as soon as leadership is lost.
+func (s *Server) monitorLeadership() {
+	ticker := time.NewTicker(leadershipMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		leader := s.candidate.isLeader()
+		if s.updateLeadership(leader) {
+			s.Config().EventBroker.Publish(structs.TopicLeader, s.candidate.advertise,
+				structs.StreamEventLeadership, &structs.LeadershipEvent{
+					Leader:  leader,
+					RPCAddr: s.candidate.advertise,
+				})
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.shutdownChan:
+			return
+		}
+	}
+}
+
+// updateLeadership creates a new leader context when the server gains
+// leadership and cancels it when leadership is lost. It returns true if the
+// leadership of the server has changed.
+func (s *Server) updateLeadership(leader bool) bool {
+	s.leaderLock.Lock()
+	defer s.leaderLock.Unlock()
+
+	active := s.leaderCtx.Err() == nil
+
+	switch {
+	case leader && !active:
+		s.leaderCtx, s.leaderCancel = context.WithCancel(s.shutdownCtx)
+
+	case !leader && active:
+		logging.Warning("core/leader: leadership has been lost, cancelling any " +
+			"scaling operations in progress")
+		s.leaderCancel()
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// leaderContext returns the context under which scaling operations should be
+// performed. The context is cancelled if the server is not the leader.
+func (s *Server) leaderContext() context.Context {
+	s.leaderLock.Lock()
+	defer s.leaderLock.Unlock()
+
+	return s.leaderCtx
+}
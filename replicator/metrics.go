@@ -0,0 +1,63 @@
+package replicator
+
+import (
+	metrics "github.com/armon/go-metrics"
+)
+
+// emitPoolMetrics publishes gauges describing the capacity, utilization and
+// scaling status of each worker pool.
+func (s *Server) emitPoolMetrics() {
+	for _, pool := range s.poolStatus("") {
+		gauge := func(name string, val float32) {
+			metrics.SetGauge([]string{"pool", pool.Name, name}, val)
+		}
+
+		gauge("node_count", float32(len(pool.Nodes)))
+		gauge("failsafe_mode", boolGauge(pool.FailsafeMode))
+		gauge("failure_count", float32(pool.FailureCount))
+		gauge("cooldown_remaining_seconds", float32(pool.CooldownRemaining))
+
+		// Capacity is only known once the worker pool has been evaluated.
+		if c := pool.Capacity; c != nil {
+			gauge("capacity_cpu_mhz", float32(c.TotalCapacity.CPUMHz))
+			gauge("capacity_memory_mb", float32(c.TotalCapacity.MemoryMB))
+			gauge("allocated_cpu_mhz", float32(c.UsedCapacity.CPUMHz))
+			gauge("allocated_memory_mb", float32(c.UsedCapacity.MemoryMB))
+			gauge("cpu_utilization_percent", float32(c.UsedCapacity.CPUPercent))
+			gauge("memory_utilization_percent", float32(c.UsedCapacity.MemoryPercent))
+			gauge("max_allowed_utilization", float32(c.MaxAllowedUtilization))
+		}
+	}
+}
+
+// emitJobGroupMetrics publishes gauges describing the utilization, count and
+// scaling status of each job group with a scaling policy.
+func (s *Server) emitJobGroupMetrics() {
+	for _, group := range s.jobGroupStatus("") {
+		gauge := func(name string, val float32) {
+			metrics.SetGauge([]string{"job", group.JobID, group.Policy.GroupName,
+				name}, val)
+		}
+
+		policy := &group.Policy
+
+		gauge("desired_count", float32(policy.DesiredCount))
+		gauge("running_count", float32(policy.RunningCount))
+		gauge("min_count", float32(policy.Min))
+		gauge("max_count", float32(policy.Max))
+		gauge("cpu_utilization_percent", float32(policy.Tasks.Resources.CPUPercent))
+		gauge("memory_utilization_percent",
+			float32(policy.Tasks.Resources.MemoryPercent))
+		gauge("failsafe_mode", boolGauge(group.FailsafeMode))
+		gauge("failure_count", float32(group.FailureCount))
+		gauge("cooldown_remaining_seconds", float32(group.CooldownRemaining))
+	}
+}
+
+// boolGauge converts a boolean into a gauge value.
+func boolGauge(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -18,7 +18,7 @@ func (r *Server) nodeProtectionCheck(nodeReg *structs.NodeRegistry) error {
 	}
 
 	// Perform a reverse lookup to get the Nomad node hosting our allocation.
-	host, err := r.config.NomadClient.NodeReverseLookup(allocID)
+	host, err := r.Config().NomadClient.NodeReverseLookup(allocID)
 	if err != nil || len(host) == 0 {
 		return fmt.Errorf("Replicator is running as a Nomad job but we are "+
 			"unable to determine the node hosting our allocation %v: %v",
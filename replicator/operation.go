@@ -0,0 +1,244 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// beginOperation records a scaling operation as pending on the state object
+// and persists it before the operation starts, allowing the operation to be
+// recovered by the next leader if it is interrupted.
+func beginOperation(config *structs.Config, state *structs.ScalingState,
+	event *structs.ScalingEvent) {
+
+	state.PendingOperation = &structs.PendingOperation{
+		Direction: event.Direction,
+		Started:   time.Now(),
+		Type:      event.Type,
+	}
+
+	if err := config.StateStore.PersistState(state); err != nil {
+		logging.Error("core/operation: unable to record pending operation for "+
+			"%v: %v", state.StatePath, err)
+	}
+}
+
+// endOperation clears the pending operation from the state object once the
+// operation has run to completion. If the context was cancelled the operation
+// was interrupted and is left in place to be recovered by the next leader.
+func endOperation(config *structs.Config, state *structs.ScalingState,
+	ctxErr error) {
+
+	if ctxErr != nil || state.PendingOperation == nil {
+		return
+	}
+
+	state.PendingOperation = nil
+
+	if err := config.StateStore.PersistState(state); err != nil {
+		logging.Error("core/operation: unable to clear pending operation for "+
+			"%v: %v", state.StatePath, err)
+	}
+}
+
+// recordJobGroupEvent records the outcome of a job group operation in the
+// scaling event history. Operations interrupted before their outcome could be
+// confirmed are instead recorded as pending on the state object so that they
+// are recovered by the next leader.
+func recordJobGroupEvent(config *structs.Config, state *structs.ScalingState,
+	event *structs.ScalingEvent) {
+
+	if event.Outcome != structs.EventOutcomeInterrupted {
+		recordScalingEvent(config, state, event)
+		return
+	}
+
+	logging.Warning("core/operation: %v operation against %v was interrupted "+
+		"and will be recovered by the next leader", event.Type, state.StatePath)
+
+	state.PendingOperation = &structs.PendingOperation{
+		Direction: event.Direction,
+		Started:   time.Now(),
+		Type:      event.Type,
+	}
+}
+
+// advanceOperation records the step of a worker pool scaling workflow which
+// is about to be performed and persists it before the step is started.
+func advanceOperation(config *structs.Config, state *structs.ScalingState,
+	step string) {
+
+	state.AdvanceOperation(step)
+
+	if err := config.StateStore.PersistState(state); err != nil {
+		logging.Error("core/operation: unable to record step %v of the pending "+
+			"operation for %v: %v", step, state.StatePath, err)
+	}
+}
+
+// recoverOperation handles a job group operation left pending on the state
+// object by a leader which lost leadership or shut down before the operation
+// was confirmed. Operations submitted to Nomad continue without Replicator so
+// are resumed by honouring the scaling cooldown as though they had completed.
+// The recovery is recorded in the scaling event history and true is returned
+// if the state object was modified.
+func (s *Server) recoverOperation(state *structs.ScalingState) bool {
+	op := state.PendingOperation
+	if op == nil {
+		return false
+	}
+
+	logging.Warning("core/operation: %v operation against %v started at %v was "+
+		"interrupted and will be resumed", op.Type, state.StatePath, op.Started)
+
+	state.RecordScalingEvent(op.Direction)
+	state.PendingOperation = nil
+
+	recordScalingEvent(s.Config(), state, &structs.ScalingEvent{
+		Direction: op.Direction,
+		Outcome:   structs.EventOutcomeInterrupted,
+		Reason: "the operation was interrupted and resumed, its outcome will be " +
+			"evaluated by the next scaling evaluation",
+		Type: op.Type,
+	})
+
+	return true
+}
+
+// recoverPoolOperation handles a worker pool scaling workflow left pending by
+// a leader which lost leadership or shut down before the workflow completed.
+// The action taken depends on the step which was interrupted:
+//
+//   - launch: the additional capacity has been requested from the scaling
+//     provider, the operation is resumed by honouring the scaling cooldown.
+//   - drain: the target node may still be running allocations, the operation
+//     is rolled back by removing the node from drain mode.
+//   - detach and terminate: the target node has been drained, the operation
+//     is completed by asking the scaling provider to remove the node.
+//
+// The recovery is recorded in the scaling event history and true is returned
+// if the state object was modified.
+func (s *Server) recoverPoolOperation(ctx context.Context,
+	workerPool *structs.WorkerPool, nodeRegistry *structs.NodeRegistry) bool {
+
+	state := workerPool.State
+	op := state.PendingOperation
+	if op == nil {
+		return false
+	}
+
+	// No step of the workflow had started, so there is nothing to recover.
+	if op.Step == "" {
+		logging.Debug("core/operation: discarding %v operation against worker "+
+			"pool %v which was interrupted before it started", op.Type,
+			workerPool.Name)
+		state.PendingOperation = nil
+		return true
+	}
+
+	logging.Warning("core/operation: %v operation against worker pool %v "+
+		"started at %v was interrupted at step %v and will be recovered", op.Type,
+		workerPool.Name, op.Started, op.Step)
+
+	event := &structs.ScalingEvent{
+		Direction: op.Direction,
+		Outcome:   structs.EventOutcomeInterrupted,
+		Type:      op.Type,
+	}
+
+	switch op.Step {
+	case structs.OperationStepDrain:
+		// Nodes marked eligible by the interrupted operation must not be
+		// targeted by a later scale-in operation without being drained.
+		state.EligibleNodes = nil
+
+		if err := s.Config().NomadClient.CancelDrain(op.NodeID); err != nil {
+			logging.Error("core/operation: unable to remove node %v from drain "+
+				"mode: %v", op.NodeID, err)
+
+			event.Reason = fmt.Sprintf("the operation was interrupted and node %v "+
+				"could not be removed from drain mode: %v", op.NodeID, err)
+			break
+		}
+
+		logging.Info("core/operation: rolled back the interrupted scale-in "+
+			"operation by removing node %v from drain mode", op.NodeID)
+
+		event.Reason = fmt.Sprintf("the operation was interrupted and rolled "+
+			"back by removing node %v from drain mode", op.NodeID)
+
+	case structs.OperationStepDetach, structs.OperationStepTerminate:
+		// Target the drained node with the scaling provider.
+		state.EligibleNodes = []string{op.NodeAddress}
+		state.ScalingDirection = structs.ScalingDirectionIn
+
+		if err := workerPool.ScalingProvider.Scale(ctx, workerPool, s.Config(),
+			nodeRegistry); err != nil {
+			if ctx.Err() != nil {
+				logging.Warning("core/operation: completion of the interrupted "+
+					"scale-in operation against worker pool %v was interrupted: %v",
+					workerPool.Name, err)
+				return false
+			}
+
+			logging.Error("core/operation: unable to complete the interrupted "+
+				"scale-in operation against worker pool %v: %v", workerPool.Name, err)
+
+			event.Outcome = structs.EventOutcomeFailure
+			event.Reason = fmt.Sprintf("the operation was interrupted and node %v "+
+				"could not be removed: %v", op.NodeID, err)
+			break
+		}
+
+		logging.Info("core/operation: completed the interrupted scale-in "+
+			"operation by removing node %v from worker pool %v", op.NodeID,
+			workerPool.Name)
+
+		event.Outcome = structs.EventOutcomeSuccess
+		event.Reason = fmt.Sprintf("the operation was interrupted and completed "+
+			"by removing node %v", op.NodeID)
+
+	default:
+		state.RecordScalingEvent(op.Direction)
+
+		event.Reason = "the operation was interrupted and resumed, its outcome " +
+			"will be evaluated by the next scaling evaluation"
+	}
+
+	state.PendingOperation = nil
+	recordScalingEvent(s.Config(), state, event)
+
+	return true
+}
+
+// lockResource marks a worker pool or job group as having a scaling
+// operation in progress. False is returned if an operation is already in
+// progress against the resource.
+func (s *Server) lockResource(resourceID string) bool {
+	s.operationLock.Lock()
+	defer s.operationLock.Unlock()
+
+	if s.operations == nil {
+		s.operations = make(map[string]bool)
+	}
+
+	if s.operations[resourceID] {
+		return false
+	}
+
+	s.operations[resourceID] = true
+	return true
+}
+
+// unlockResource marks the scaling operation against a worker pool or job
+// group as complete.
+func (s *Server) unlockResource(resourceID string) {
+	s.operationLock.Lock()
+	defer s.operationLock.Unlock()
+
+	delete(s.operations, resourceID)
+}
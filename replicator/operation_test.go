@@ -0,0 +1,267 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// testDrainClient is a NomadClient which records nodes removed from drain
+// mode.
+type testDrainClient struct {
+	structs.NomadClient
+	cancelled []string
+	err       error
+}
+
+func (c *testDrainClient) CancelDrain(nodeID string) error {
+	c.cancelled = append(c.cancelled, nodeID)
+	return c.err
+}
+
+func TestOperation_recoverOperation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	nomad := &testDrainClient{}
+	config := &structs.Config{
+		ConsulKeyRoot: "replicator/config",
+		History:       &structs.History{MaxEvents: 10},
+		NomadClient:   nomad,
+		StateStore:    store,
+	}
+	s := &Server{config: config}
+
+	// State without a pending operation is left untouched.
+	state := &structs.ScalingState{
+		ResourceType: JobType,
+		StatePath:    "replicator/config/state/jobs/example/cache",
+	}
+	if s.recoverOperation(state) {
+		t.Fatalf("expected no recovery without a pending operation")
+	}
+
+	// An interrupted job group operation is resumed and honours the cooldown.
+	recordJobGroupEvent(config, state, &structs.ScalingEvent{
+		Direction: structs.ScalingDirectionOut,
+		Outcome:   structs.EventOutcomeInterrupted,
+		Type:      structs.EventTypeScale,
+	})
+	if state.PendingOperation == nil {
+		t.Fatalf("expected interrupted operation to be recorded as pending")
+	}
+	if err = store.PersistState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	state = &structs.ScalingState{StatePath: state.StatePath}
+	store.ReadState(state, false)
+	if !s.recoverOperation(state) {
+		t.Fatalf("expected interrupted job group operation to be recovered")
+	}
+	if state.PendingOperation != nil || state.LastScaleOutEvent.IsZero() {
+		t.Fatalf("expected pending operation to be cleared and cooldown recorded "+
+			"but got %#v", state)
+	}
+
+	events, err := store.ListEvents("replicator/config/history/jobs/example/cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Outcome != structs.EventOutcomeInterrupted {
+		t.Fatalf("expected a single interrupted event but got %#v", events)
+	}
+
+	// Completed operations are cleared.
+	state = &structs.ScalingState{
+		ResourceType: ClusterType,
+		StatePath:    "replicator/config/state/nodes/example-pool",
+	}
+	beginOperation(config, state, &structs.ScalingEvent{
+		Direction: structs.ScalingDirectionOut,
+		Type:      structs.EventTypeScale,
+	})
+	endOperation(config, state, nil)
+
+	state = &structs.ScalingState{StatePath: state.StatePath}
+	store.ReadState(state, false)
+	if state.PendingOperation != nil {
+		t.Fatalf("expected completed operation to be cleared but got %#v",
+			state.PendingOperation)
+	}
+}
+
+// testScalingProvider is a ScalingProvider which records the nodes targeted
+// by scaling operations.
+type testScalingProvider struct {
+	targets []string
+}
+
+func (p *testScalingProvider) SafetyCheck(*structs.WorkerPool) bool { return true }
+
+func (p *testScalingProvider) Scale(ctx context.Context, workerPool *structs.WorkerPool,
+	config *structs.Config, nodeRegistry *structs.NodeRegistry) error {
+	p.targets = append(p.targets, workerPool.State.EligibleNodes...)
+	return nil
+}
+
+func TestOperation_recoverPoolOperation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	nomad := &testDrainClient{err: fmt.Errorf("node not found")}
+	provider := &testScalingProvider{}
+	config := &structs.Config{
+		ConsulKeyRoot: "replicator/config",
+		History:       &structs.History{MaxEvents: 10},
+		NomadClient:   nomad,
+		StateStore:    store,
+	}
+	s := &Server{config: config}
+
+	workerPool := &structs.WorkerPool{
+		Name:            "example-pool",
+		ScalingProvider: provider,
+		State: &structs.ScalingState{
+			ResourceType: ClusterType,
+			StatePath:    "replicator/config/state/nodes/example-pool",
+		},
+	}
+
+	// beginScaleIn records a scale-in workflow interrupted at the step.
+	beginScaleIn := func(step string) {
+		workerPool.State.EligibleNodes = []string{"10.0.0.1"}
+		beginOperation(config, workerPool.State, &structs.ScalingEvent{
+			Direction: structs.ScalingDirectionIn,
+			Type:      structs.EventTypeScale,
+		})
+		workerPool.State.PendingOperation.NodeAddress = "10.0.0.1"
+		workerPool.State.PendingOperation.NodeID = "node-1"
+		advanceOperation(config, workerPool.State, step)
+
+		// The operation is retained as it was interrupted.
+		endOperation(config, workerPool.State, context.Canceled)
+
+		workerPool.State = &structs.ScalingState{
+			StatePath: workerPool.State.StatePath,
+		}
+		store.ReadState(workerPool.State, false)
+	}
+
+	// A workflow interrupted before any step started is discarded.
+	beginScaleIn("")
+	if !s.recoverPoolOperation(context.Background(), workerPool, nil) ||
+		workerPool.State.PendingOperation != nil {
+		t.Fatalf("expected operation to be discarded but got %#v",
+			workerPool.State.PendingOperation)
+	}
+	if len(nomad.cancelled) != 0 || len(provider.targets) != 0 {
+		t.Fatalf("expected no recovery action to be taken")
+	}
+
+	// A workflow interrupted while draining is rolled back.
+	beginScaleIn(structs.OperationStepDrain)
+	if workerPool.State.PendingOperation.Step != structs.OperationStepDrain {
+		t.Fatalf("expected the drain step to be persisted but got %#v",
+			workerPool.State.PendingOperation)
+	}
+	if !s.recoverPoolOperation(context.Background(), workerPool, nil) {
+		t.Fatalf("expected interrupted drain to be recovered")
+	}
+	if len(nomad.cancelled) != 1 || nomad.cancelled[0] != "node-1" {
+		t.Fatalf("expected drain of node-1 to be cancelled but got %v",
+			nomad.cancelled)
+	}
+	if workerPool.State.PendingOperation != nil ||
+		len(workerPool.State.EligibleNodes) != 0 {
+		t.Fatalf("expected scale-in to be rolled back but got %#v", workerPool.State)
+	}
+
+	// A workflow interrupted once the node was drained is completed.
+	beginScaleIn(structs.OperationStepTerminate)
+	if !s.recoverPoolOperation(context.Background(), workerPool, nil) {
+		t.Fatalf("expected interrupted termination to be recovered")
+	}
+	if len(provider.targets) != 1 || provider.targets[0] != "10.0.0.1" {
+		t.Fatalf("expected the provider to remove node 10.0.0.1 but got %v",
+			provider.targets)
+	}
+	if workerPool.State.PendingOperation != nil ||
+		workerPool.State.ScalingDirection != structs.ScalingDirectionIn {
+		t.Fatalf("expected scale-in to be completed but got %#v", workerPool.State)
+	}
+
+	events, err := store.ListEvents("replicator/config/history/nodes/example-pool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	structs.SortEvents(events)
+	if len(events) != 2 || events[0].Outcome != structs.EventOutcomeInterrupted ||
+		events[1].Outcome != structs.EventOutcomeSuccess {
+		t.Fatalf("expected rollback and completion events but got %#v", events)
+	}
+}
+
+func TestOperation_updateLeadership(t *testing.T) {
+	s := &Server{}
+	s.shutdownCtx, s.shutdownCancel = context.WithCancel(context.Background())
+	s.leaderCtx, s.leaderCancel = context.WithCancel(s.shutdownCtx)
+	s.leaderCancel()
+
+	if s.leaderContext().Err() == nil {
+		t.Fatalf("expected the leader context to be cancelled before leadership " +
+			"is acquired")
+	}
+
+	s.updateLeadership(true)
+	ctx := s.leaderContext()
+	if ctx.Err() != nil {
+		t.Fatalf("expected an active leader context once leadership is acquired")
+	}
+
+	// Leadership is retained across checks without replacing the context.
+	s.updateLeadership(true)
+	if s.leaderContext() != ctx {
+		t.Fatalf("expected the leader context to be retained")
+	}
+
+	s.updateLeadership(false)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("expected the leader context to be cancelled on leadership loss")
+	}
+
+	// Shutdown cancels the context of the current leader.
+	s.updateLeadership(true)
+	ctx = s.leaderContext()
+	s.shutdownCancel()
+	if ctx.Err() == nil {
+		t.Fatalf("expected the leader context to be cancelled on shutdown")
+	}
+}
@@ -0,0 +1,157 @@
+package replicator
+
+import (
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
+)
+
+// rpcIdleTimeout is the time an idle connection to another server is kept
+// open for reuse before it is closed.
+const rpcIdleTimeout = 2 * time.Minute
+
+// rpcConn is a client connection to the RPC listener of another server.
+type rpcConn struct {
+	client   *rpc.Client
+	conn     net.Conn
+	lastUsed time.Time
+}
+
+// close closes the underlying connection. The rpc.Client is not closed as
+// closing its codec races with the reader of the client, which instead exits
+// when the connection is closed.
+func (c *rpcConn) close() {
+	c.conn.Close()
+}
+
+// connPool maintains idle connections to the RPC listeners of other servers
+// so that forwarded requests reuse a connection rather than connecting and
+// performing a TLS handshake for each request. Requests on a connection are
+// served one at a time, so a connection is only used by one request at a
+// time and a new connection is made when no idle connection is available.
+type connPool struct {
+	dial func(addr string) (net.Conn, error)
+
+	idle     map[string][]*rpcConn
+	lock     sync.Mutex
+	shutdown bool
+}
+
+// newConnPool returns a connection pool which makes new connections with the
+// dial function provided.
+func newConnPool(dial func(addr string) (net.Conn, error)) *connPool {
+	return &connPool{
+		dial: dial,
+		idle: make(map[string][]*rpcConn),
+	}
+}
+
+// Call performs an RPC call against the server listening at the address
+// provided, using an idle connection if one is available. A request which
+// fails because the idle connection was already closed by the server is
+// retried once on a new connection; it was never sent so it is safe to retry.
+func (p *connPool) Call(addr, method string, args interface{}, reply interface{}) error {
+	conn, err := p.acquire(addr, true)
+	if err != nil {
+		return err
+	}
+
+	err = conn.client.Call(method, args, reply)
+	if err == rpc.ErrShutdown {
+		conn.close()
+		if conn, err = p.acquire(addr, false); err != nil {
+			return err
+		}
+		err = conn.client.Call(method, args, reply)
+	}
+
+	p.release(addr, conn, err)
+	return err
+}
+
+// acquire returns an idle connection to the address, or a new connection if
+// none are idle or reuse is not permitted.
+func (p *connPool) acquire(addr string, reuse bool) (*rpcConn, error) {
+	if reuse {
+		p.lock.Lock()
+		p.reap(time.Now())
+		if conns := p.idle[addr]; len(conns) > 0 {
+			conn := conns[len(conns)-1]
+			p.idle[addr] = conns[:len(conns)-1]
+			p.lock.Unlock()
+			return conn, nil
+		}
+		p.lock.Unlock()
+	}
+
+	conn, err := p.dial(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpcConn{
+		client: rpc.NewClientWithCodec(
+			msgpackrpc.NewCodecFromHandle(true, true, conn, HashiMsgpackHandle)),
+		conn: conn,
+	}, nil
+}
+
+// release returns a connection to the pool once a request has completed. A
+// connection is only reused if the request succeeded or failed with an error
+// returned by the server, as other errors leave the connection unusable.
+func (p *connPool) release(addr string, conn *rpcConn, err error) {
+	if _, ok := err.(rpc.ServerError); err != nil && !ok {
+		conn.close()
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.shutdown {
+		conn.close()
+		return
+	}
+
+	conn.lastUsed = time.Now()
+	p.idle[addr] = append(p.idle[addr], conn)
+}
+
+// reap closes the connections which have been idle for longer than the idle
+// timeout. The lock must be held by the caller.
+func (p *connPool) reap(now time.Time) {
+	for addr, conns := range p.idle {
+		active := conns[:0]
+		for _, conn := range conns {
+			if now.Sub(conn.lastUsed) > rpcIdleTimeout {
+				conn.close()
+				continue
+			}
+			active = append(active, conn)
+		}
+
+		if len(active) == 0 {
+			delete(p.idle, addr)
+			continue
+		}
+		p.idle[addr] = active
+	}
+}
+
+// Shutdown closes every idle connection. Connections in use are closed once
+// their request completes.
+func (p *connPool) Shutdown() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.shutdown = true
+	for addr, conns := range p.idle {
+		for _, conn := range conns {
+			conn.close()
+		}
+		delete(p.idle, addr)
+	}
+}
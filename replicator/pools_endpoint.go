@@ -0,0 +1,123 @@
+package replicator
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Pools endpoint is used to query the worker pools discovered by Replicator.
+type Pools struct {
+	srv *Server
+}
+
+// List returns the status of every worker pool in the node registry, sorted
+// by name. Scaling evaluations are only performed by the leader so requests
+// are forwarded to it.
+func (p *Pools) List(args *structs.QueryOptions, reply *structs.PoolListResponse) error {
+	if done, err := p.srv.forward("Pools.List", args, reply); done {
+		return err
+	}
+
+	if _, err := p.srv.authorize(args.AuthToken, structs.ACLResourcePool,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	reply.Pools = p.srv.poolStatus("")
+	return nil
+}
+
+// Get returns the status of a single worker pool. No pools are returned if
+// the worker pool has not been discovered.
+func (p *Pools) Get(args *structs.PoolRequest, reply *structs.PoolListResponse) error {
+	if done, err := p.srv.forward("Pools.Get", args, reply); done {
+		return err
+	}
+
+	if _, err := p.srv.authorize(args.AuthToken, structs.ACLResourcePool,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	reply.Pools = p.srv.poolStatus(args.Name)
+	return nil
+}
+
+// poolStatus builds the status of the named worker pool, or of all worker
+// pools if no name is specified.
+func (s *Server) poolStatus(name string) []*structs.PoolStatus {
+	registry := s.nodeRegistry
+
+	registry.Lock.RLock()
+	pools := make([]*structs.PoolStatus, 0)
+	for poolName, workerPool := range registry.WorkerPools {
+		if name != "" && poolName != name {
+			continue
+		}
+
+		status := &structs.PoolStatus{
+			Capacity:         workerPool.Capacity,
+			Cooldown:         workerPool.Cooldown,
+			Name:             workerPool.Name,
+			Nodes:            make([]*structs.PoolNode, 0, len(workerPool.Nodes)),
+			ProtectedNode:    workerPool.ProtectedNode,
+			ProviderName:     workerPool.ProviderName,
+			Region:           workerPool.Region,
+			ScaleInCooldown:  workerPool.ScaleInCooldown,
+			ScaleOutCooldown: workerPool.ScaleOutCooldown,
+			ScalingEnabled:   workerPool.ScalingEnabled,
+			ScalingThreshold: workerPool.ScalingThreshold,
+		}
+
+		for id, node := range workerPool.Nodes {
+			status.Nodes = append(status.Nodes, &structs.PoolNode{
+				Address:    node.HTTPAddr,
+				ID:         id,
+				Name:       node.Name,
+				Registered: workerPool.NodeRegistrations[id],
+			})
+		}
+
+		pools = append(pools, status)
+	}
+	registry.Lock.RUnlock()
+
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Name < pools[j].Name
+	})
+
+	now := time.Now()
+	for _, status := range pools {
+		sort.Slice(status.Nodes, func(i, j int) bool {
+			return status.Nodes[i].Registered.Before(status.Nodes[j].Registered)
+		})
+
+		state := &structs.ScalingState{
+			StatePath: s.Config().ConsulKeyRoot + "/state/nodes/" + status.Name,
+		}
+		s.Config().StateStore.ReadState(state, false)
+
+		status.FailsafeMode = state.FailsafeMode
+		status.FailureCount = state.FailureCount
+		status.ScaleInRequests = state.ScaleInRequests
+		status.ScaleOutRequests = state.ScaleOutRequests
+
+		var direction string
+		if status.Capacity != nil {
+			direction = status.Capacity.ScalingDirection
+		}
+
+		expiry := cooldownExpiry(state, direction,
+			time.Duration(status.Cooldown)*time.Second,
+			time.Duration(status.ScaleInCooldown)*time.Second,
+			time.Duration(status.ScaleOutCooldown)*time.Second)
+		if remaining := expiry.Sub(now); remaining > 0 {
+			status.CooldownRemaining = int(math.Ceil(remaining.Seconds()))
+		}
+	}
+
+	return pools
+}
@@ -0,0 +1,110 @@
+package replicator
+
+import (
+	"io/ioutil"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	nomad "github.com/hashicorp/nomad/api"
+)
+
+func TestPools_ListAndGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+	defer store.(interface{ Close() error }).Close()
+
+	s := &Server{
+		candidate: &LeaderCandidate{leader: true},
+		config: &structs.Config{
+			ConsulKeyRoot: "replicator/config",
+			StateStore:    store,
+		},
+		nodeRegistry: structs.NewNodeRegistry(),
+		rpcServer:    rpc.NewServer(),
+	}
+	s.rpcServer.Register(&Pools{s})
+
+	now := time.Now()
+	example := structs.NewWorkerPool()
+	example.Name = "example"
+	example.ProtectedNode = "node-1"
+	example.Capacity = &structs.ClusterCapacity{
+		MaxAllowedUtilization: 75,
+		ScalingDirection:      structs.ScalingDirectionIn,
+	}
+	example.Nodes["node-2"] = &nomad.Node{ID: "node-2", Name: "worker-2"}
+	example.Nodes["node-1"] = &nomad.Node{ID: "node-1", Name: "worker-1"}
+	example.NodeRegistrations["node-2"] = now
+	example.NodeRegistrations["node-1"] = now.Add(-time.Minute)
+
+	other := structs.NewWorkerPool()
+	other.Name = "another"
+
+	s.nodeRegistry.WorkerPools["example"] = example
+	s.nodeRegistry.WorkerPools["another"] = other
+
+	// The example pool has recently scaled in and has a pending request.
+	state := &structs.ScalingState{
+		FailureCount:    1,
+		ScaleInRequests: 2,
+		StatePath:       "replicator/config/state/nodes/example",
+	}
+	state.RecordScalingEvent(structs.ScalingDirectionIn)
+	if err := store.PersistState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	var out structs.PoolListResponse
+	if err := s.RPC("Pools.List", nil, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Pools) != 2 || out.Pools[0].Name != "another" ||
+		out.Pools[0].Capacity != nil {
+		t.Fatalf("expected two pools sorted by name but got %#v", out.Pools)
+	}
+
+	out = structs.PoolListResponse{}
+	if err := s.RPC("Pools.Get", &structs.PoolRequest{Name: "example"}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Pools) != 1 {
+		t.Fatalf("expected a single pool but got %#v", out.Pools)
+	}
+
+	status := out.Pools[0]
+	if len(status.Nodes) != 2 || status.Nodes[0].ID != "node-1" ||
+		status.Nodes[0].Name != "worker-1" || status.ProtectedNode != "node-1" {
+		t.Fatalf("expected nodes sorted by registration time but got %#v",
+			status.Nodes)
+	}
+	if status.Capacity == nil || status.Capacity.MaxAllowedUtilization != 75 {
+		t.Fatalf("expected the last capacity computation but got %#v",
+			status.Capacity)
+	}
+	if status.ScaleInRequests != 2 || status.ScalingThreshold != 3 ||
+		status.FailureCount != 1 || status.FailsafeMode ||
+		status.CooldownRemaining <= 0 || status.CooldownRemaining > 300 {
+		t.Fatalf("unexpected scaling state for pool example: %#v", status)
+	}
+
+	out = structs.PoolListResponse{}
+	if err := s.RPC("Pools.Get", &structs.PoolRequest{Name: "missing"}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Pools) != 0 {
+		t.Fatalf("expected no pools for an unknown name but got %#v", out.Pools)
+	}
+}
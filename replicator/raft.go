@@ -0,0 +1,175 @@
+package replicator
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/raft"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/logging"
+)
+
+const (
+	// raftTransportMaxPool is the maximum number of pooled connections to
+	// each Raft peer.
+	raftTransportMaxPool = 3
+
+	// raftTransportTimeout is the I/O deadline applied to Raft connections.
+	raftTransportTimeout = 10 * time.Second
+
+	// raftSnapshotsRetained is the number of Raft snapshots kept on disk.
+	raftSnapshotsRetained = 2
+)
+
+// setupRaft configures the embedded Raft cluster used by the raft state
+// backend for leader election and state replication. Raft traffic is carried
+// over the RPC listener so setupRPC must have been called first. Each server
+// is identified by its advertised RPC address and the cluster is bootstrapped
+// from the configured peers on first start.
+func (s *Server) setupRaft() error {
+	logger := log.New(&raftLogWriter{}, "", 0)
+
+	s.raftLayer = NewRaftLayer(s.rpcAdvertise, s.outgoingTLS)
+	s.raftTransport = raft.NewNetworkTransportWithLogger(s.raftLayer,
+		raftTransportMaxPool, raftTransportTimeout, logger)
+
+	conf := raft.DefaultConfig()
+	conf.LocalID = raft.ServerID(s.rpcAdvertise.String())
+	conf.Logger = logger
+	conf.ShutdownOnRemove = false
+
+	// The state path is the location of the Raft log database; snapshots are
+	// written to a directory alongside it.
+	dir := filepath.Dir(s.Config().State.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create raft data directory %v: %v", dir, err)
+	}
+
+	store, err := client.NewRaftLogStore(s.Config().State.Path)
+	if err != nil {
+		return err
+	}
+	s.raftStore = store
+
+	snapshots, err := raft.NewFileSnapshotStoreWithLogger(dir,
+		raftSnapshotsRetained, logger)
+	if err != nil {
+		return fmt.Errorf("unable to setup raft snapshot store: %v", err)
+	}
+
+	hasState, err := raft.HasExistingState(store, store, snapshots)
+	if err != nil {
+		return fmt.Errorf("unable to check for existing raft state: %v", err)
+	}
+
+	if !hasState {
+		configuration, err := s.raftConfiguration()
+		if err != nil {
+			return err
+		}
+
+		logging.Info("core/raft: bootstrapping raft cluster with %v servers",
+			len(configuration.Servers))
+
+		err = raft.BootstrapCluster(conf, store, store, snapshots,
+			s.raftTransport, configuration)
+		if err != nil {
+			return fmt.Errorf("unable to bootstrap raft cluster: %v", err)
+		}
+	}
+
+	fsm := client.NewRaftFSM()
+	s.raft, err = raft.NewRaft(conf, fsm, store, store, snapshots, s.raftTransport)
+	if err != nil {
+		return fmt.Errorf("unable to start raft: %v", err)
+	}
+
+	s.candidate.raft = s.raft
+	s.config.StateStore = client.NewRaftStateStore(s.raft, fsm)
+
+	return nil
+}
+
+// raftConfiguration builds the initial Raft cluster configuration from the
+// configured peers, ensuring the local server is included.
+func (s *Server) raftConfiguration() (configuration raft.Configuration, err error) {
+	self := s.rpcAdvertise.String()
+	seen := map[string]bool{self: true}
+
+	configuration.Servers = append(configuration.Servers, raft.Server{
+		Address:  raft.ServerAddress(self),
+		ID:       raft.ServerID(self),
+		Suffrage: raft.Voter,
+	})
+
+	for _, peer := range s.Config().State.RaftPeers {
+		addr, err := net.ResolveTCPAddr("tcp", peer)
+		if err != nil {
+			return configuration, fmt.Errorf("unable to resolve raft peer %v: %v",
+				peer, err)
+		}
+
+		if seen[addr.String()] {
+			continue
+		}
+		seen[addr.String()] = true
+
+		configuration.Servers = append(configuration.Servers, raft.Server{
+			Address:  raft.ServerAddress(addr.String()),
+			ID:       raft.ServerID(addr.String()),
+			Suffrage: raft.Voter,
+		})
+	}
+
+	return
+}
+
+// shutdownRaft stops the embedded Raft cluster member and releases the Raft
+// log database.
+func (s *Server) shutdownRaft() {
+	if s.raft != nil {
+		if err := s.raft.Shutdown().Error(); err != nil {
+			logging.Error("core/raft: an error occurred while shutting down raft: %v",
+				err)
+		}
+	}
+
+	if s.raftLayer != nil {
+		s.raftLayer.Close()
+	}
+
+	if s.raftTransport != nil {
+		s.raftTransport.Close()
+	}
+
+	if s.raftStore != nil {
+		s.raftStore.Close()
+	}
+}
+
+// raftLogWriter adapts the log output of Raft to the Replicator logger,
+// mapping the level prefix of each line to the matching log level.
+type raftLogWriter struct{}
+
+func (w *raftLogWriter) Write(p []byte) (int, error) {
+	line := strings.TrimSpace(string(p))
+
+	switch {
+	case strings.HasPrefix(line, "[ERR]"):
+		logging.Error("core/raft: %v", strings.TrimSpace(line[len("[ERR]"):]))
+	case strings.HasPrefix(line, "[WARN]"):
+		logging.Warning("core/raft: %v", strings.TrimSpace(line[len("[WARN]"):]))
+	case strings.HasPrefix(line, "[INFO]"):
+		logging.Info("core/raft: %v", strings.TrimSpace(line[len("[INFO]"):]))
+	default:
+		logging.Debug("core/raft: %v", strings.TrimPrefix(line, "[DEBUG] "))
+	}
+
+	return len(p), nil
+}
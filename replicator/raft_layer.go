@@ -0,0 +1,93 @@
+package replicator
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// RaftLayer implements the raft.StreamLayer interface so that Raft traffic
+// is multiplexed over the Replicator RPC listener. Inbound connections are
+// handed off by the RPC server once the Raft protocol byte has been read.
+type RaftLayer struct {
+	// addr is the advertised address of the RPC listener.
+	addr net.Addr
+
+	// connCh receives inbound connections handed off by the RPC server.
+	connCh chan net.Conn
+
+	// tlsConfig returns the TLS configuration of outgoing connections, or nil
+	// if TLS is not enabled for RPC.
+	tlsConfig func() *tls.Config
+
+	closed    bool
+	closeCh   chan struct{}
+	closeLock sync.Mutex
+}
+
+// NewRaftLayer is used to construct a new Raft stream layer advertising the
+// provided address. Outgoing connections use the TLS configuration returned
+// by the function provided, if any.
+func NewRaftLayer(addr net.Addr, tlsConfig func() *tls.Config) *RaftLayer {
+	return &RaftLayer{
+		addr:      addr,
+		connCh:    make(chan net.Conn),
+		closeCh:   make(chan struct{}),
+		tlsConfig: tlsConfig,
+	}
+}
+
+// Handoff is used to hand off a connection to the Raft layer, blocking until
+// it has been accepted or the layer is closed.
+func (l *RaftLayer) Handoff(c net.Conn) error {
+	select {
+	case l.connCh <- c:
+		return nil
+	case <-l.closeCh:
+		return fmt.Errorf("raft layer closed")
+	}
+}
+
+// Accept is used to return a connection which was handed off by the RPC
+// server.
+func (l *RaftLayer) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.connCh:
+		return conn, nil
+	case <-l.closeCh:
+		return nil, fmt.Errorf("raft layer closed")
+	}
+}
+
+// Close is used to stop the Raft layer accepting connections.
+func (l *RaftLayer) Close() error {
+	l.closeLock.Lock()
+	defer l.closeLock.Unlock()
+
+	if !l.closed {
+		l.closed = true
+		close(l.closeCh)
+	}
+	return nil
+}
+
+// Addr is used to return the address of the listener.
+func (l *RaftLayer) Addr() net.Addr {
+	return l.addr
+}
+
+// Dial is used to create a new outgoing connection to the RPC listener of
+// another server, selecting the Raft protocol.
+func (l *RaftLayer) Dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	var tlsConfig *tls.Config
+	if l.tlsConfig != nil {
+		tlsConfig = l.tlsConfig()
+	}
+
+	// Select the Raft protocol so the connection is handed off on arrival.
+	return dialRPC(string(address), rpcRaft, tlsConfig, timeout)
+}
@@ -0,0 +1,173 @@
+package replicator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// testRaftCluster starts the requested number of servers forming a Raft
+// cluster on free local ports.
+func testRaftCluster(t *testing.T, dir string, count int) []*Server {
+	var addrs []*net.TCPAddr
+	var peers []string
+
+	for i := 0; i < count; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, l.Addr().(*net.TCPAddr))
+		peers = append(peers, l.Addr().String())
+		l.Close()
+	}
+
+	var servers []*Server
+	for i, addr := range addrs {
+		s, err := NewServer(&structs.Config{
+			ClusterScalingDisable: true,
+			ConsulKeyRoot:         "replicator/config",
+			JobScalingDisable:     true,
+			RPCAddr:               addr,
+			RightSizing:           &structs.RightSizing{HistorySize: 10},
+			State: &structs.State{
+				Backend:   structs.StateBackendRaft,
+				GCDisable: true,
+				Path:      filepath.Join(dir, fmt.Sprintf("server%v", i), "raft.db"),
+				RaftPeers: peers,
+			},
+		})
+		if err != nil {
+			t.Fatalf("error starting server %v: %v", i, err)
+		}
+		servers = append(servers, s)
+	}
+
+	return servers
+}
+
+// waitForLeader waits until exactly one of the servers is the Raft leader.
+func waitForLeader(t *testing.T, servers []*Server) *Server {
+	deadline := time.Now().Add(20 * time.Second)
+
+	for time.Now().Before(deadline) {
+		var leaders []*Server
+		for _, s := range servers {
+			if s.candidate.isLeader() {
+				leaders = append(leaders, s)
+			}
+		}
+
+		if len(leaders) == 1 {
+			return leaders[0]
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for a single leader to be elected")
+	return nil
+}
+
+// waitForState waits until the state stored at the path is visible on the
+// server with the expected failure count.
+func waitForState(t *testing.T, s *Server, path string, failureCount int) *structs.ScalingState {
+	deadline := time.Now().Add(10 * time.Second)
+
+	for time.Now().Before(deadline) {
+		state := &structs.ScalingState{StatePath: path}
+		s.config.StateStore.ReadState(state, false)
+
+		if state.FailureCount == failureCount {
+			return state
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for state at %v to replicate to %v", path,
+		s.rpcAdvertise)
+	return nil
+}
+
+func TestRaft_Cluster(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping raft cluster test in short mode")
+	}
+
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	servers := testRaftCluster(t, dir, 3)
+	running := map[*Server]bool{}
+	for _, s := range servers {
+		running[s] = true
+	}
+	defer func() {
+		for s := range running {
+			s.Shutdown()
+		}
+	}()
+
+	leader := waitForLeader(t, servers)
+
+	var followers []*Server
+	for _, s := range servers {
+		if s != leader {
+			followers = append(followers, s)
+		}
+	}
+
+	// Every server reports the elected leader.
+	for _, s := range servers {
+		reply := &structs.LeaderResponse{}
+		if err := s.RPC("Status.Leader", nil, reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.NodeID != leader.rpcAdvertise.String() {
+			t.Fatalf("expected leader %v but %v reported %v",
+				leader.rpcAdvertise, s.rpcAdvertise, reply.NodeID)
+		}
+	}
+
+	// State written on the leader is replicated to the followers.
+	path := "replicator/config/state/nodes/example-pool"
+	state := &structs.ScalingState{FailureCount: 2, StatePath: path}
+	if err := leader.config.StateStore.PersistState(state); err != nil {
+		t.Fatalf("error writing state on the leader: %v", err)
+	}
+
+	for _, s := range followers {
+		waitForState(t, s, path, 2)
+	}
+
+	// Writes can only be performed on the leader.
+	followerState := waitForState(t, followers[0], path, 2)
+	followerState.FailureCount = 3
+	if err := followers[0].config.StateStore.PersistState(followerState); err == nil {
+		t.Fatalf("expected write on a follower to fail")
+	}
+
+	// A new leader is elected once the leader shuts down and retains state.
+	leader.Shutdown()
+	delete(running, leader)
+
+	newLeader := waitForLeader(t, followers)
+
+	state = waitForState(t, newLeader, path, 2)
+	state.FailureCount = 4
+	if err := newLeader.config.StateStore.PersistState(state); err != nil {
+		t.Fatalf("error writing state on the new leader: %v", err)
+	}
+
+	for _, s := range followers {
+		waitForState(t, s, path, 4)
+	}
+}
@@ -0,0 +1,43 @@
+package replicator
+
+import (
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Recommendations endpoint is used to query task resource right-sizing
+// recommendations.
+type Recommendations struct {
+	srv *Server
+}
+
+// List returns right-sizing recommendations for every task of a job with a
+// scaling policy which has sufficient utilization history. Utilization is
+// only tracked by the leader so requests are forwarded to it.
+func (r *Recommendations) List(args *structs.QueryOptions, reply *structs.RecommendationListResponse) error {
+	if done, err := r.srv.forward("Recommendations.List", args, reply); done {
+		return err
+	}
+
+	if _, err := r.srv.authorize(args.AuthToken, structs.ACLResourceJob,
+		structs.ACLCapabilityRead); err != nil {
+		return err
+	}
+
+	recommendations := buildRecommendations(r.srv.utilization,
+		r.srv.Config().RightSizing)
+
+	// Only report on jobs which are still tracked by Replicator.
+	policies := r.srv.jobScalingPolicies
+
+	policies.Lock.RLock()
+	defer policies.Lock.RUnlock()
+
+	reply.Recommendations = make([]*structs.Recommendation, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		if _, ok := policies.Policies[recommendation.JobID]; ok {
+			reply.Recommendations = append(reply.Recommendations, recommendation)
+		}
+	}
+
+	return nil
+}
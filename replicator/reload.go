@@ -0,0 +1,345 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Define the background subsystems of the server which are restarted
+// independently when the configuration is reloaded.
+const (
+	subsystemClusterScaling = "cluster_scaling"
+	subsystemGC             = "gc"
+	subsystemJobScaling     = "job_scaling"
+	subsystemJobWatcher     = "job_watcher"
+	subsystemNodeWatcher    = "node_watcher"
+)
+
+// subsystem tracks a running background subsystem of the server.
+type subsystem struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started time.Time
+}
+
+// subsystemRunners returns the function used to run each subsystem which is
+// enabled by the current configuration.
+func (s *Server) subsystemRunners() map[string]func(context.Context) {
+	runners := make(map[string]func(context.Context))
+	config := s.Config()
+
+	if !config.ClusterScalingDisable || !config.JobScalingDisable {
+		runners[subsystemJobWatcher] = func(ctx context.Context) {
+			s.jobScalingPolicies.Lock.Lock()
+			s.jobScalingPolicies.LastChangeIndex = 0
+			s.jobScalingPolicies.Lock.Unlock()
+
+			config.NomadClient.JobWatcher(ctx, s.jobScalingPolicies)
+		}
+	}
+
+	if !config.ClusterScalingDisable {
+		runners[subsystemNodeWatcher] = func(ctx context.Context) {
+			s.nodeRegistry.Lock.Lock()
+			s.nodeRegistry.LastChangeIndex = 0
+			s.nodeRegistry.Lock.Unlock()
+
+			config.NomadClient.NodeWatcher(ctx, s.nodeRegistry, config)
+		}
+		runners[subsystemClusterScaling] = s.clusterScalingTicker
+	}
+
+	if !config.JobScalingDisable {
+		runners[subsystemJobScaling] = s.jobScalingTicker
+	}
+
+	if config.State != nil && !config.State.GCDisable {
+		runners[subsystemGC] = s.gcTicker
+	}
+
+	return runners
+}
+
+// startSubsystems starts the named subsystems which are enabled by the
+// current configuration.
+func (s *Server) startSubsystems(names []string) {
+	runners := s.subsystemRunners()
+
+	s.subsystemLock.Lock()
+	defer s.subsystemLock.Unlock()
+
+	for _, name := range names {
+		run, ok := runners[name]
+		if !ok {
+			continue
+		}
+
+		if _, ok := s.subsystems[name]; ok {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(s.shutdownCtx)
+		sub := &subsystem{cancel: cancel, done: make(chan struct{}),
+			started: time.Now()}
+		s.subsystems[name] = sub
+
+		go func() {
+			defer close(sub.done)
+			run(ctx)
+		}()
+	}
+}
+
+// stopSubsystems stops the named subsystems and waits for them to exit. A
+// scaling subsystem exits once any evaluation in progress has completed.
+func (s *Server) stopSubsystems(names []string) {
+	s.subsystemLock.Lock()
+	var stopping []*subsystem
+	for _, name := range names {
+		if sub, ok := s.subsystems[name]; ok {
+			sub.cancel()
+			stopping = append(stopping, sub)
+			delete(s.subsystems, name)
+		}
+	}
+	s.subsystemLock.Unlock()
+
+	for _, sub := range stopping {
+		<-sub.done
+	}
+}
+
+// allSubsystems returns the names of all subsystems of the server.
+func allSubsystems() []string {
+	return []string{subsystemClusterScaling, subsystemGC, subsystemJobScaling,
+		subsystemJobWatcher, subsystemNodeWatcher}
+}
+
+// Reload applies a new configuration to the running server. Settings which
+// can be changed at runtime are applied to a copy of the configuration which
+// then replaces the current one, and only the subsystems which depend on a
+// changed setting are restarted. Changes to settings which require the agent
+// to be restarted are logged and ignored.
+func (s *Server) Reload(newConfig *structs.Config) error {
+	s.reloadLock.Lock()
+	defer s.reloadLock.Unlock()
+
+	config := s.Config()
+
+	for _, setting := range restartRequired(config, newConfig) {
+		logging.Warning("core/reload: a change to %v requires the agent to be "+
+			"restarted and will not take effect", setting)
+	}
+
+	restart := make(map[string]bool)
+	mark := func(names ...string) {
+		for _, name := range names {
+			restart[name] = true
+		}
+	}
+
+	// New certificates are loaded before any subsystem is stopped so that an
+	// invalid certificate leaves the server untouched. Connections established
+	// after the reload are served with the new certificates.
+	if tls := newConfig.TLS; s.tls != nil && tls != nil &&
+		(tls.EnableHTTP || tls.EnableRPC) {
+		if err := s.tls.Load(newConfig.TLS); err != nil {
+			return fmt.Errorf("unable to reload the TLS certificates: %v", err)
+		}
+	}
+
+	// A new Nomad client is setup before any subsystem is stopped so that an
+	// invalid configuration leaves the server untouched.
+	var nomadClient structs.NomadClient
+	if config.Nomad != newConfig.Nomad || config.NomadToken != newConfig.NomadToken ||
+		config.NomadTLSServerName != newConfig.NomadTLSServerName {
+		var err error
+		nomadClient, err = client.NewNomadClient(newConfig.Nomad,
+			newConfig.NomadToken, newConfig.NomadTLSServerName)
+		if err != nil {
+			return fmt.Errorf("unable to setup the Nomad client: %v", err)
+		}
+		mark(subsystemJobWatcher, subsystemNodeWatcher, subsystemJobScaling,
+			subsystemClusterScaling)
+	}
+
+	if config.ScalingConcurrency != newConfig.ScalingConcurrency {
+		mark(subsystemJobScaling, subsystemClusterScaling)
+	}
+
+	if config.JobScalingInterval != newConfig.JobScalingInterval ||
+		config.JobScalingDisable != newConfig.JobScalingDisable {
+		mark(subsystemJobScaling)
+	}
+
+	if config.ClusterScalingInterval != newConfig.ClusterScalingInterval {
+		mark(subsystemClusterScaling)
+	}
+
+	// Garbage collection depends on which watchers are running.
+	if config.ClusterScalingDisable != newConfig.ClusterScalingDisable {
+		mark(subsystemClusterScaling, subsystemNodeWatcher, subsystemGC)
+	}
+
+	if (config.ClusterScalingDisable && config.JobScalingDisable) !=
+		(newConfig.ClusterScalingDisable && newConfig.JobScalingDisable) {
+		mark(subsystemJobWatcher, subsystemGC)
+	}
+
+	if gcChanged(config.State, newConfig.State) {
+		mark(subsystemGC)
+	}
+
+	var names []string
+	for name := range restart {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s.stopSubsystems(names)
+
+	// The settings are applied to a copy of the configuration which replaces
+	// the current one once complete; the current configuration may still be
+	// read concurrently by the RPC and HTTP endpoints.
+	updated := *config
+
+	// Apply the settings of stopped subsystems.
+	if nomadClient != nil {
+		updated.Nomad = newConfig.Nomad
+		updated.NomadClient = nomadClient
+		updated.NomadToken = newConfig.NomadToken
+		updated.NomadTLSServerName = newConfig.NomadTLSServerName
+	}
+
+	updated.ClusterScalingDisable = newConfig.ClusterScalingDisable
+	updated.ClusterScalingInterval = newConfig.ClusterScalingInterval
+	updated.JobScalingDisable = newConfig.JobScalingDisable
+	updated.JobScalingInterval = newConfig.JobScalingInterval
+	updated.ScalingConcurrency = newConfig.ScalingConcurrency
+
+	if gcChanged(config.State, newConfig.State) && newConfig.State != nil {
+		state := *config.State
+		state.GCDisable = newConfig.State.GCDisable
+		state.GCGracePeriod = newConfig.State.GCGracePeriod
+		state.GCInterval = newConfig.State.GCInterval
+		updated.State = &state
+	}
+
+	// Apply the settings which take effect immediately. The HTTP server is
+	// restarted by the agent when its port changes.
+	updated.ACL = newConfig.ACL
+	updated.History = newConfig.History
+	updated.HTTPPort = newConfig.HTTPPort
+	updated.LogLevel = newConfig.LogLevel
+	updated.Notification = newConfig.Notification
+	updated.Telemetry = newConfig.Telemetry
+
+	if config.TLS != nil && newConfig.TLS != nil {
+		tls := *newConfig.TLS
+		tls.EnableHTTP = config.TLS.EnableHTTP
+		tls.EnableRPC = config.TLS.EnableRPC
+		updated.TLS = &tls
+	}
+
+	if config.RightSizing != nil && newConfig.RightSizing != nil {
+		rightSizing := *newConfig.RightSizing
+		rightSizing.HistorySize = config.RightSizing.HistorySize
+		updated.RightSizing = &rightSizing
+	}
+
+	s.configLock.Lock()
+	s.config = &updated
+	s.configLock.Unlock()
+
+	s.startSubsystems(names)
+
+	if len(names) > 0 {
+		logging.Info("core/reload: configuration reloaded, restarted subsystems: "+
+			"%v", names)
+	} else {
+		logging.Info("core/reload: configuration reloaded, no subsystems required " +
+			"a restart")
+	}
+
+	return nil
+}
+
+// restartRequired returns the settings which differ between the
+// configurations but cannot be changed without restarting the agent.
+func restartRequired(old, updated *structs.Config) (settings []string) {
+	if old.BindAddress != updated.BindAddress {
+		settings = append(settings, "bind_address")
+	}
+
+	if old.Consul != updated.Consul {
+		settings = append(settings, "consul")
+	}
+
+	if old.ConsulKeyRoot != updated.ConsulKeyRoot {
+		settings = append(settings, "consul_key_root")
+	}
+
+	if old.ConsulToken != updated.ConsulToken {
+		settings = append(settings, "consul_token")
+	}
+
+	if old.RPCPort != updated.RPCPort {
+		settings = append(settings, "rpc_port")
+	}
+
+	if old.RightSizing != nil && updated.RightSizing != nil &&
+		old.RightSizing.HistorySize != updated.RightSizing.HistorySize {
+		settings = append(settings, "right_sizing.history_size")
+	}
+
+	var oldTLS, updatedTLS structs.TLS
+	if old.TLS != nil {
+		oldTLS = *old.TLS
+	}
+	if updated.TLS != nil {
+		updatedTLS = *updated.TLS
+	}
+
+	if oldTLS.EnableHTTP != updatedTLS.EnableHTTP {
+		settings = append(settings, "tls.http")
+	}
+
+	if oldTLS.EnableRPC != updatedTLS.EnableRPC {
+		settings = append(settings, "tls.rpc")
+	}
+
+	if old.State != nil && updated.State != nil {
+		if old.State.Backend != updated.State.Backend {
+			settings = append(settings, "state.backend")
+		}
+
+		if old.State.Path != updated.State.Path {
+			settings = append(settings, "state.path")
+		}
+
+		if !reflect.DeepEqual(old.State.RaftPeers, updated.State.RaftPeers) {
+			settings = append(settings, "state.raft_peers")
+		}
+	}
+
+	return
+}
+
+// gcChanged reports whether the garbage collection settings differ between
+// the state configurations.
+func gcChanged(old, updated *structs.State) bool {
+	if old == nil || updated == nil {
+		return old != updated
+	}
+
+	return old.GCDisable != updated.GCDisable ||
+		old.GCGracePeriod != updated.GCGracePeriod ||
+		old.GCInterval != updated.GCInterval
+}
@@ -0,0 +1,135 @@
+package replicator
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// testWatcherClient is a NomadClient whose watchers block until cancelled.
+type testWatcherClient struct {
+	structs.NomadClient
+}
+
+func (c *testWatcherClient) JobWatcher(ctx context.Context, _ *structs.JobScalingPolicies) {
+	<-ctx.Done()
+}
+
+func (c *testWatcherClient) NodeWatcher(ctx context.Context, _ *structs.NodeRegistry,
+	_ *structs.Config) {
+	<-ctx.Done()
+}
+
+// testReloadConfig returns a configuration with scaling disabled.
+func testReloadConfig() *structs.Config {
+	return &structs.Config{
+		ClusterScalingDisable:  true,
+		ClusterScalingInterval: 10,
+		JobScalingDisable:      true,
+		JobScalingInterval:     10,
+		LogLevel:               "INFO",
+		NomadClient:            &testWatcherClient{},
+		ScalingConcurrency:     10,
+		State:                  &structs.State{GCGracePeriod: 60, GCInterval: 300},
+	}
+}
+
+// runningSubsystems returns the sorted names of the running subsystems.
+func runningSubsystems(s *Server) (names []string) {
+	s.subsystemLock.Lock()
+	defer s.subsystemLock.Unlock()
+
+	for name := range s.subsystems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func TestReload_Reload(t *testing.T) {
+	s := &Server{
+		candidate:          &LeaderCandidate{},
+		config:             testReloadConfig(),
+		jobScalingPolicies: newJobScalingPolicy(),
+		nodeRegistry:       structs.NewNodeRegistry(),
+		subsystems:         make(map[string]*subsystem),
+	}
+	s.shutdownCtx, s.shutdownCancel = context.WithCancel(context.Background())
+	defer s.shutdownCancel()
+
+	s.startSubsystems(allSubsystems())
+	if names := runningSubsystems(s); !reflect.DeepEqual(names, []string{subsystemGC}) {
+		t.Fatalf("expected only garbage collection to be running but got %v", names)
+	}
+	gc := s.subsystems[subsystemGC]
+
+	// Settings applied in place do not restart any subsystem.
+	updated := testReloadConfig()
+	updated.LogLevel = "DEBUG"
+	updated.History = &structs.History{MaxEvents: 5}
+	if err := s.Reload(updated); err != nil {
+		t.Fatal(err)
+	}
+	if s.config.LogLevel != "DEBUG" || s.config.History.MaxEvents != 5 {
+		t.Fatalf("expected settings to be applied but got %#v", s.config)
+	}
+	if s.subsystems[subsystemGC] != gc {
+		t.Fatalf("expected garbage collection not to be restarted")
+	}
+
+	// Changing the garbage collection interval only restarts the collector.
+	updated = testReloadConfig()
+	updated.State.GCInterval = 600
+	if err := s.Reload(updated); err != nil {
+		t.Fatal(err)
+	}
+	if s.config.State.GCInterval != 600 || s.subsystems[subsystemGC] == gc {
+		t.Fatalf("expected garbage collection to be restarted with the new interval")
+	}
+	select {
+	case <-gc.done:
+	default:
+		t.Fatalf("expected the previous garbage collector to have exited")
+	}
+
+	// Enabling job scaling starts the job watcher and scaling ticker.
+	updated = testReloadConfig()
+	updated.JobScalingDisable = false
+	updated.State.GCInterval = 600
+	if err := s.Reload(updated); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{subsystemGC, subsystemJobScaling, subsystemJobWatcher}
+	if names := runningSubsystems(s); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v to be running but got %v", expected, names)
+	}
+
+	// Disabling job scaling and garbage collection stops their subsystems.
+	updated = testReloadConfig()
+	updated.State.GCDisable = true
+	if err := s.Reload(updated); err != nil {
+		t.Fatal(err)
+	}
+	if names := runningSubsystems(s); len(names) != 0 {
+		t.Fatalf("expected no subsystems to be running but got %v", names)
+	}
+}
+
+func TestReload_restartRequired(t *testing.T) {
+	old := testReloadConfig()
+	old.State.Backend = structs.StateBackendConsul
+
+	updated := testReloadConfig()
+	updated.ConsulKeyRoot = "replicator/other"
+	updated.RPCPort = 1400
+	updated.State.Backend = structs.StateBackendBolt
+	updated.ScalingConcurrency = 5
+
+	expected := []string{"consul_key_root", "rpc_port", "state.backend"}
+	if settings := restartRequired(old, updated); !reflect.DeepEqual(settings, expected) {
+		t.Fatalf("expected %v but got %v", expected, settings)
+	}
+}
@@ -0,0 +1,87 @@
+package replicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/elsevier-core-engineering/replicator/helper"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Define the minimum resources Nomad permits a task to be configured with,
+// recommendations will never fall below these values.
+const (
+	minimumCPUMHz   = 20
+	minimumMemoryMB = 10
+)
+
+// buildRecommendations calculates right-sizing recommendations for every task
+// in the utilization history which has collected the minimum number of
+// samples. Results are sorted by job, group and task name.
+func buildRecommendations(history *structs.UtilizationHistory,
+	config *structs.RightSizing) (recommendations []*structs.Recommendation) {
+
+	history.Lock.RLock()
+	defer history.Lock.RUnlock()
+
+	for _, task := range history.Tasks {
+		if len(task.Samples) == 0 || len(task.Samples) < config.MinSamples {
+			continue
+		}
+
+		recommendations = append(recommendations, taskRecommendation(task, config))
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.JobID != b.JobID {
+			return a.JobID < b.JobID
+		}
+		if a.GroupName != b.GroupName {
+			return a.GroupName < b.GroupName
+		}
+		return a.TaskName < b.TaskName
+	})
+
+	return
+}
+
+// taskRecommendation calculates the suggested CPU and memory resources for a
+// task using the configured percentile of observed utilization plus headroom.
+func taskRecommendation(task *structs.TaskUtilization,
+	config *structs.RightSizing) *structs.Recommendation {
+
+	cpu := make([]float64, len(task.Samples))
+	memory := make([]float64, len(task.Samples))
+
+	for i, sample := range task.Samples {
+		cpu[i] = sample.CPUMHz
+		memory[i] = sample.MemoryMB
+	}
+
+	observedCPU := helper.Percentile(cpu, config.CPUPercentile)
+	observedMemory := helper.Percentile(memory, config.MemoryPercentile)
+
+	return &structs.Recommendation{
+		JobID:               task.JobID,
+		GroupName:           task.GroupName,
+		TaskName:            task.TaskName,
+		Samples:             len(task.Samples),
+		CPUMHz:              task.CPUMHz,
+		MemoryMB:            task.MemoryMB,
+		ObservedCPUMHz:      observedCPU,
+		ObservedMemoryMB:    observedMemory,
+		RecommendedCPUMHz:   withHeadroom(observedCPU, config.HeadroomPercent, minimumCPUMHz),
+		RecommendedMemoryMB: withHeadroom(observedMemory, config.HeadroomPercent, minimumMemoryMB),
+	}
+}
+
+// withHeadroom applies the headroom percentage to an observed value, rounding
+// up to the nearest whole unit and enforcing the supplied minimum.
+func withHeadroom(observed, headroom float64, minimum int) int {
+	value := int(math.Ceil(observed * (100 + headroom) / 100))
+	if value < minimum {
+		return minimum
+	}
+	return value
+}
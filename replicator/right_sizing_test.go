@@ -0,0 +1,72 @@
+package replicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestRightSizing_buildRecommendations(t *testing.T) {
+	config := &structs.RightSizing{
+		CPUPercentile:    90,
+		HeadroomPercent:  10,
+		MemoryPercentile: 100,
+		MinSamples:       5,
+	}
+
+	history := structs.NewUtilizationHistory(10)
+
+	for i := 1; i <= 12; i++ {
+		history.Record("example", "cache", "redis", 500, 256,
+			structs.UtilizationSample{
+				Timestamp: time.Now(),
+				CPUMHz:    float64(i * 10),
+				MemoryMB:  float64(i * 8),
+			})
+	}
+
+	// Record a task which has not yet collected the minimum samples.
+	history.Record("example", "cache", "sidecar", 100, 64,
+		structs.UtilizationSample{Timestamp: time.Now(), CPUMHz: 1, MemoryMB: 1})
+
+	if samples := len(history.Tasks["example/cache/redis"].Samples); samples != 10 {
+		t.Fatalf("expected history to retain 10 samples but got %v", samples)
+	}
+
+	recommendations := buildRecommendations(history, config)
+	if len(recommendations) != 1 {
+		t.Fatalf("expected 1 recommendation but got %v", len(recommendations))
+	}
+
+	r := recommendations[0]
+
+	// The retained samples range from 30-120 MHz and 24-96 MB.
+	if r.ObservedCPUMHz != 110 {
+		t.Fatalf("expected observed CPU of 110 but got %v", r.ObservedCPUMHz)
+	}
+	if r.RecommendedCPUMHz != 121 {
+		t.Fatalf("expected recommended CPU of 121 but got %v", r.RecommendedCPUMHz)
+	}
+	if r.ObservedMemoryMB != 96 {
+		t.Fatalf("expected observed memory of 96 but got %v", r.ObservedMemoryMB)
+	}
+	if r.RecommendedMemoryMB != 106 {
+		t.Fatalf("expected recommended memory of 106 but got %v", r.RecommendedMemoryMB)
+	}
+
+	history.Remove("example")
+	if len(history.Tasks) != 0 {
+		t.Fatalf("expected empty history but got %v tasks", len(history.Tasks))
+	}
+}
+
+func TestRightSizing_withHeadroom(t *testing.T) {
+	if value := withHeadroom(1, 15, minimumCPUMHz); value != minimumCPUMHz {
+		t.Fatalf("expected minimum value %v but got %v", minimumCPUMHz, value)
+	}
+
+	if value := withHeadroom(200, 15, minimumCPUMHz); value != 230 {
+		t.Fatalf("expected 230 but got %v", value)
+	}
+}
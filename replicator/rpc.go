@@ -1,16 +1,38 @@
 package replicator
 
 import (
+	"crypto/tls"
+	"fmt"
 	"io"
 	"net"
 	"net/rpc"
 	"reflect"
 	"strings"
+	"time"
 
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
 	hcodec "github.com/hashicorp/go-msgpack/codec"
 	msgpackrpc "github.com/hashicorp/net-rpc-msgpackrpc"
 )
 
+// RPCType is the first byte written on a connection to the RPC listener and
+// selects the protocol used for the remainder of the connection.
+type RPCType byte
+
+const (
+	rpcReplicator RPCType = 0x01
+	rpcRaft       RPCType = 0x02
+
+	// rpcTLS upgrades the connection to TLS, after which the protocol byte of
+	// the remainder of the connection is written.
+	rpcTLS RPCType = 0x03
+)
+
+// rpcDialTimeout is the maximum time to wait when connecting to the leader to
+// forward a request.
+const rpcDialTimeout = 10 * time.Second
+
 // HashiMsgpackHandle is some magic.
 var HashiMsgpackHandle = func() *hcodec.MsgpackHandle {
 	h := &hcodec.MsgpackHandle{RawToString: true}
@@ -37,7 +59,64 @@ func (s *Server) listen() {
 	}
 }
 
+// handleConn reads the protocol byte of a new connection and either serves
+// msgpack RPC requests or hands the connection off to the Raft layer.
 func (s *Server) handleConn(conn net.Conn) {
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err != nil {
+		if err != io.EOF {
+			logging.Error("core/rpc: failed to read protocol byte from %v: %v",
+				conn.RemoteAddr(), err)
+		}
+		conn.Close()
+		return
+	}
+
+	protocol := RPCType(buf[0])
+
+	// Plaintext connections are rejected once TLS is enabled for the RPC
+	// listener.
+	if _, ok := conn.(*tls.Conn); !ok && protocol != rpcTLS && s.rpcTLSEnabled() {
+		logging.Error("core/rpc: plaintext connection from %v rejected, TLS is "+
+			"required", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
+	switch protocol {
+	case rpcReplicator:
+		s.handleReplicatorConn(conn)
+
+	case rpcTLS:
+		if !s.rpcTLSEnabled() {
+			logging.Error("core/rpc: TLS connection from %v rejected, TLS is not "+
+				"enabled for the RPC listener", conn.RemoteAddr())
+			conn.Close()
+			return
+		}
+		s.handleConn(tls.Server(conn, s.tls.IncomingConfig()))
+
+	case rpcRaft:
+		if s.raftLayer == nil {
+			logging.Error("core/rpc: raft connection from %v rejected, the raft "+
+				"state backend is not enabled", conn.RemoteAddr())
+			conn.Close()
+			return
+		}
+		if err := s.raftLayer.Handoff(conn); err != nil {
+			conn.Close()
+		}
+
+	default:
+		logging.Error("core/rpc: unrecognized protocol byte %v from %v",
+			buf[0], conn.RemoteAddr())
+		conn.Close()
+	}
+}
+
+// handleReplicatorConn serves msgpack RPC requests on a connection until it
+// is closed.
+func (s *Server) handleReplicatorConn(conn net.Conn) {
 	defer conn.Close()
 	rpcCodec := NewServerCodec(conn)
 	for {
@@ -54,3 +133,96 @@ func (s *Server) handleConn(conn net.Conn) {
 		}
 	}
 }
+
+// forward is used to forward a request to the leader when this server is not
+// the leader. If the request was forwarded, true is returned along with the
+// error of the forwarded call and reply holds the response of the leader.
+func (s *Server) forward(method string, args interface{}, reply interface{}) (bool, error) {
+	if s.candidate.isLeader() {
+		return false, nil
+	}
+
+	leader := &structs.LeaderResponse{}
+	if err := s.endpoints.Status.Leader(nil, leader); err != nil {
+		return true, fmt.Errorf("unable to determine the Replicator leader: %v", err)
+	}
+
+	// Refuse to forward to ourselves which may occur while leadership is
+	// changing.
+	if leader.RPCAddr == "" || leader.RPCAddr == s.rpcAdvertise.String() {
+		return true, fmt.Errorf("no Replicator leader is currently available")
+	}
+
+	logging.Debug("core/rpc: forwarding %v request to the leader at %v",
+		method, leader.RPCAddr)
+
+	return true, s.forwardRPC(leader.RPCAddr, method, args, reply)
+}
+
+// forwardRPC performs an RPC call against the server listening at the address
+// provided using the msgpack codec, reusing a pooled connection if one is
+// idle.
+func (s *Server) forwardRPC(addr, method string, args interface{}, reply interface{}) error {
+	return s.connPool.Call(addr, method, args, reply)
+}
+
+// dialLeader connects to the RPC listener of the leader at the address
+// provided to forward requests.
+func (s *Server) dialLeader(addr string) (net.Conn, error) {
+	conn, err := dialRPC(addr, rpcReplicator, s.outgoingTLS(), rpcDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the Replicator leader at %v: %v",
+			addr, err)
+	}
+	return conn, nil
+}
+
+// dialRPC connects to the RPC listener at the address provided and selects
+// the protocol of the connection. If a TLS configuration is provided the
+// connection is upgraded to TLS before the protocol is selected.
+func dialRPC(addr string, protocol RPCType, tlsConfig *tls.Config,
+	timeout time.Duration) (net.Conn, error) {
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		if _, err = conn.Write([]byte{byte(rpcTLS)}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		tlsConn := tls.Client(conn, tlsConfig)
+		tlsConn.SetDeadline(time.Now().Add(timeout))
+		if err = tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake failed: %v", err)
+		}
+		tlsConn.SetDeadline(time.Time{})
+
+		conn = tlsConn
+	}
+
+	if _, err = conn.Write([]byte{byte(protocol)}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// rpcTLSEnabled indicates whether TLS is enabled for the RPC listener.
+func (s *Server) rpcTLSEnabled() bool {
+	return s.tls != nil && s.Config().TLS != nil && s.Config().TLS.EnableRPC
+}
+
+// outgoingTLS returns the TLS configuration used to connect to the RPC
+// listener of other servers, or nil if TLS is not enabled for RPC.
+func (s *Server) outgoingTLS() *tls.Config {
+	if !s.rpcTLSEnabled() {
+		return nil
+	}
+	return s.tls.OutgoingRPCConfig()
+}
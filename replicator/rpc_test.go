@@ -0,0 +1,80 @@
+package replicator
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestRPC_forward(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping raft cluster test in short mode")
+	}
+
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	servers := testRaftCluster(t, dir, 3)
+	defer func() {
+		for _, s := range servers {
+			s.Shutdown()
+		}
+	}()
+
+	leader := waitForLeader(t, servers)
+
+	// Utilization history is only held by the leader.
+	leader.jobScalingPolicies.Lock.Lock()
+	leader.jobScalingPolicies.Policies["example"] = []*structs.GroupScalingPolicy{
+		{GroupName: "cache"},
+	}
+	leader.jobScalingPolicies.Lock.Unlock()
+
+	for i := 1; i <= 5; i++ {
+		leader.utilization.Record("example", "cache", "redis", 500, 256,
+			structs.UtilizationSample{
+				Timestamp: time.Now(),
+				CPUMHz:    float64(i * 10),
+				MemoryMB:  float64(i * 8),
+			})
+	}
+
+	for _, s := range servers {
+		if forwarded, _ := s.forward("Status.Leader", nil, &structs.LeaderResponse{}); forwarded != (s != leader) {
+			t.Fatalf("expected only followers to forward requests but %v returned %v",
+				s.rpcAdvertise, forwarded)
+		}
+
+		reply := &structs.RecommendationListResponse{}
+		if err := s.RPC("Recommendations.List", nil, reply); err != nil {
+			t.Fatalf("error listing recommendations on %v: %v", s.rpcAdvertise, err)
+		}
+
+		if len(reply.Recommendations) != 1 || reply.Recommendations[0].JobID != "example" {
+			t.Fatalf("expected the leader's recommendation from %v but got %#v",
+				s.rpcAdvertise, reply.Recommendations)
+		}
+	}
+
+	// Forwarded requests reuse a single idle connection to the leader.
+	for _, s := range servers {
+		if s == leader {
+			continue
+		}
+
+		s.connPool.lock.Lock()
+		idle := len(s.connPool.idle[leader.rpcAdvertise.String()])
+		s.connPool.lock.Unlock()
+
+		if idle != 1 {
+			t.Fatalf("expected 1 idle connection to the leader from %v but got %v",
+				s.rpcAdvertise, idle)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// Scaling endpoint is used to perform scaling operations requested by an
+// operator.
+type Scaling struct {
+	srv *Server
+}
+
+// Job scales a job group to the requested count or one step in the
+// requested direction. Scaling operations are only performed by the leader
+// so requests are forwarded to it.
+func (sc *Scaling) Job(args *structs.ScaleRequest, reply *structs.ScaleResponse) error {
+	if done, err := sc.srv.forward("Scaling.Job", args, reply); done {
+		return err
+	}
+
+	token, err := sc.srv.authorize(args.AuthToken, structs.ACLResourceJob,
+		structs.ACLCapabilityWrite)
+	if err != nil {
+		return err
+	}
+	args.Actor = sc.srv.aclActor(token, args.Actor)
+
+	return sc.srv.manualJobScaling(sc.srv.leaderContext(), args, reply)
+}
+
+// Pool scales a worker pool to the requested count or one step in the
+// requested direction. Scaling operations are only performed by the leader
+// so requests are forwarded to it.
+func (sc *Scaling) Pool(args *structs.ScaleRequest, reply *structs.ScaleResponse) error {
+	if done, err := sc.srv.forward("Scaling.Pool", args, reply); done {
+		return err
+	}
+
+	token, err := sc.srv.authorize(args.AuthToken, structs.ACLResourcePool,
+		structs.ACLCapabilityWrite)
+	if err != nil {
+		return err
+	}
+	args.Actor = sc.srv.aclActor(token, args.Actor)
+
+	return sc.srv.manualPoolScaling(sc.srv.leaderContext(), args, reply)
+}
+
+// manualJobScaling performs an operator requested scaling operation against
+// a job group using the same path as follower scaling, waiting for the
+// operation to be confirmed.
+func (s *Server) manualJobScaling(ctx context.Context, args *structs.ScaleRequest,
+	reply *structs.ScaleResponse) error {
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("leadership was lost, no scaling operation was performed")
+	}
+
+	var group *structs.GroupScalingPolicy
+	s.jobScalingPolicies.Lock.RLock()
+	for _, policy := range s.jobScalingPolicies.Policies[args.JobID] {
+		if policy.GroupName == args.Group {
+			group = policy
+		}
+	}
+	s.jobScalingPolicies.Lock.RUnlock()
+
+	resourceID := args.JobID + "/" + args.Group
+	if group == nil {
+		return fmt.Errorf("job group %v does not have a scaling policy", resourceID)
+	}
+
+	if !s.lockResource(JobType + "/" + resourceID) {
+		return fmt.Errorf("a scaling operation is already in progress against "+
+			"job group %v", resourceID)
+	}
+	defer s.unlockResource(JobType + "/" + resourceID)
+
+	state := &structs.ScalingState{
+		ResourceName: group.GroupName,
+		ResourceType: JobType,
+		StatePath:    s.Config().ConsulKeyRoot + "/state/jobs/" + resourceID,
+	}
+	s.Config().StateStore.ReadState(state, true)
+
+	if state.FailsafeMode && !args.Force {
+		return fmt.Errorf("job group %v is in failsafe mode, force is required to "+
+			"override", resourceID)
+	}
+
+	current, err := s.Config().NomadClient.GetJobGroupCount(args.JobID, args.Group)
+	if err != nil {
+		return fmt.Errorf("unable to determine the count of job group %v: %v",
+			resourceID, err)
+	}
+
+	desired, err := manualCount(current, args)
+	if err != nil {
+		return err
+	}
+
+	if desired == current {
+		return nil
+	}
+
+	if !args.Force && (desired < group.Min || desired > group.Max) {
+		return fmt.Errorf("a count of %v for job group %v is outside of the "+
+			"scaling policy bounds (min: %v, max: %v), force is required to override",
+			desired, resourceID, group.Min, group.Max)
+	}
+
+	logging.Info("core/scaling: scaling job group %v from %v to %v as requested "+
+		"by %v", resourceID, current, desired, args.Actor)
+
+	event := s.Config().NomadClient.JobGroupScaleTo(ctx, args.JobID, group,
+		desired, state)
+	if event == nil {
+		return fmt.Errorf("unable to scale job group %v, see the agent logs for "+
+			"details", resourceID)
+	}
+
+	event.Type = structs.EventTypeManual
+	event.Reason = manualReason(args.Actor, event.Reason)
+	recordJobGroupEvent(s.Config(), state, event)
+
+	if err := s.Config().StateStore.PersistState(state); err != nil {
+		logging.Error("core/scaling: %v", err)
+	}
+
+	reply.Events = []*structs.ScalingEvent{event}
+	return nil
+}
+
+// manualPoolScaling performs an operator requested scaling operation against
+// a worker pool one node at a time using the same path as cluster scaling,
+// stopping at the first operation which does not succeed.
+func (s *Server) manualPoolScaling(ctx context.Context, args *structs.ScaleRequest,
+	reply *structs.ScaleResponse) error {
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("leadership was lost, no scaling operation was performed")
+	}
+
+	s.nodeRegistry.Lock.RLock()
+	workerPool := s.nodeRegistry.WorkerPools[args.Pool]
+	s.nodeRegistry.Lock.RUnlock()
+
+	if workerPool == nil {
+		return fmt.Errorf("worker pool %v was not found", args.Pool)
+	}
+
+	if workerPool.ScalingProvider == nil {
+		return fmt.Errorf("worker pool %v does not have a scaling provider",
+			args.Pool)
+	}
+
+	if !s.lockResource(ClusterType + "/" + args.Pool) {
+		return fmt.Errorf("a scaling operation is already in progress against "+
+			"worker pool %v", args.Pool)
+	}
+	defer s.unlockResource(ClusterType + "/" + args.Pool)
+
+	workerPool.State = &structs.ScalingState{
+		ResourceName: workerPool.Name,
+		ResourceType: ClusterType,
+		StatePath:    s.Config().ConsulKeyRoot + "/state/nodes/" + workerPool.Name,
+	}
+	s.Config().StateStore.ReadState(workerPool.State, true)
+
+	// Interrupted operations are recovered by the next cluster scaling
+	// evaluation.
+	if workerPool.State.PendingOperation != nil {
+		return fmt.Errorf("worker pool %v has an interrupted scaling operation "+
+			"which has not yet been recovered", args.Pool)
+	}
+
+	if workerPool.State.FailsafeMode && !args.Force {
+		return fmt.Errorf("worker pool %v is in failsafe mode, force is required "+
+			"to override", args.Pool)
+	}
+
+	s.nodeRegistry.Lock.RLock()
+	current := len(workerPool.Nodes)
+	s.nodeRegistry.Lock.RUnlock()
+
+	desired, err := manualCount(current, args)
+	if err != nil {
+		return err
+	}
+
+	reply.Events = make([]*structs.ScalingEvent, 0)
+
+	for current != desired {
+		direction := structs.ScalingDirectionOut
+		if desired < current {
+			direction = structs.ScalingDirectionIn
+		}
+		workerPool.State.ScalingDirection = direction
+
+		if !args.Force && !workerPool.ScalingProvider.SafetyCheck(workerPool) {
+			return fmt.Errorf("scaling %v worker pool %v is not permitted by the "+
+				"scaling provider, force is required to override",
+				strings.ToLower(direction), args.Pool)
+		}
+
+		logging.Info("core/scaling: scaling %v worker pool %v with %v nodes as "+
+			"requested by %v", strings.ToLower(direction), args.Pool, current,
+			args.Actor)
+
+		event := &structs.ScalingEvent{
+			CountAfter:  current,
+			CountBefore: current,
+			Direction:   direction,
+			Outcome:     structs.EventOutcomeFailure,
+			Type:        structs.EventTypeManual,
+		}
+
+		beginOperation(s.Config(), workerPool.State, event)
+
+		if direction == structs.ScalingDirectionOut {
+			err = s.scalePoolOut(ctx, workerPool, s.nodeRegistry)
+		} else {
+			poolCapacity := &structs.ClusterCapacity{}
+			_, err = s.Config().NomadClient.EvaluatePoolScaling(poolCapacity,
+				workerPool, s.jobScalingPolicies)
+			if err == nil {
+				err = s.scalePoolIn(ctx, workerPool, poolCapacity, s.nodeRegistry)
+			}
+		}
+
+		endOperation(s.Config(), workerPool.State, ctx.Err())
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("scaling operation against worker pool %v was "+
+					"interrupted: %v", args.Pool, err)
+			}
+
+			event.Reason = manualReason(args.Actor, err.Error())
+			recordScalingEvent(s.Config(), workerPool.State, event)
+			reply.Events = append(reply.Events, event)
+			return nil
+		}
+
+		if direction == structs.ScalingDirectionOut {
+			event.CountAfter++
+		} else {
+			event.CountAfter--
+		}
+
+		event.Outcome = structs.EventOutcomeSuccess
+		event.Reason = manualReason(args.Actor, "")
+		recordScalingEvent(s.Config(), workerPool.State, event)
+		reply.Events = append(reply.Events, event)
+
+		current = event.CountAfter
+	}
+
+	return nil
+}
+
+// manualCount calculates the desired count of a resource from a scaling
+// request.
+func manualCount(current int, args *structs.ScaleRequest) (int, error) {
+	switch strings.ToLower(args.Direction) {
+	case "":
+		if args.Count == nil {
+			return 0, fmt.Errorf("either a count or direction must be specified")
+		}
+		if *args.Count < 0 {
+			return 0, fmt.Errorf("invalid count %v", *args.Count)
+		}
+		return *args.Count, nil
+	case strings.ToLower(structs.ScalingDirectionIn):
+		if current == 0 {
+			return 0, fmt.Errorf("unable to scale in from a count of 0")
+		}
+		return current - 1, nil
+	case strings.ToLower(structs.ScalingDirectionOut):
+		return current + 1, nil
+	}
+
+	return 0, fmt.Errorf("invalid scaling direction %q, must be either in or out",
+		args.Direction)
+}
+
+// manualReason describes a manual scaling operation for the scaling event
+// history, including the outcome detail if any.
+func manualReason(actor, detail string) string {
+	reason := "manually requested by " + actor
+	if detail != "" {
+		reason = reason + ": " + detail
+	}
+	return reason
+}
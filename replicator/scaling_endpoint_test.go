@@ -0,0 +1,254 @@
+package replicator
+
+import (
+	"context"
+	"io/ioutil"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+	nomad "github.com/hashicorp/nomad/api"
+)
+
+// testManualClient is a NomadClient which records manual scaling operations.
+type testManualClient struct {
+	structs.NomadClient
+	count   int
+	drained []string
+}
+
+func (c *testManualClient) GetJobGroupCount(string, string) (int, error) {
+	return c.count, nil
+}
+
+func (c *testManualClient) JobGroupScaleTo(ctx context.Context, jobName string,
+	group *structs.GroupScalingPolicy, count int, state *structs.ScalingState) *structs.ScalingEvent {
+
+	event := &structs.ScalingEvent{
+		CountAfter:  count,
+		CountBefore: c.count,
+		Outcome:     structs.EventOutcomeSuccess,
+		Type:        structs.EventTypeScale,
+	}
+	c.count = count
+	return event
+}
+
+func (c *testManualClient) EvaluatePoolScaling(*structs.ClusterCapacity,
+	*structs.WorkerPool, *structs.JobScalingPolicies) (bool, error) {
+	return false, nil
+}
+
+func (c *testManualClient) LeastAllocatedNode(*structs.ClusterCapacity,
+	string) (string, string) {
+	return "node-1", "10.0.0.1"
+}
+
+func (c *testManualClient) DrainNode(ctx context.Context, nodeID string) error {
+	c.drained = append(c.drained, nodeID)
+	return nil
+}
+
+// testManualServer returns a leader with the scaling endpoint registered and
+// a bolt state store.
+func testManualServer(t *testing.T, dir string) (*Server, *testManualClient) {
+	store, err := client.NewBoltStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("error opening state database: %v", err)
+	}
+
+	nomadClient := &testManualClient{}
+	s := &Server{
+		candidate: &LeaderCandidate{leader: true},
+		config: &structs.Config{
+			ConsulKeyRoot: "replicator/config",
+			History:       &structs.History{MaxEvents: 10},
+			NomadClient:   nomadClient,
+			StateStore:    store,
+		},
+		jobScalingPolicies: newJobScalingPolicy(),
+		nodeRegistry:       structs.NewNodeRegistry(),
+		rpcServer:          rpc.NewServer(),
+	}
+	s.leaderCtx, s.leaderCancel = context.WithCancel(context.Background())
+	s.rpcServer.Register(&Scaling{s})
+
+	return s, nomadClient
+}
+
+func TestScaling_Job(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, nomadClient := testManualServer(t, dir)
+	defer s.leaderCancel()
+	defer s.config.StateStore.(interface{ Close() error }).Close()
+
+	group := structs.NewGroupScalingPolicy()
+	group.GroupName = "cache"
+	group.Min = 1
+	group.Max = 3
+	s.jobScalingPolicies.Policies["example"] = []*structs.GroupScalingPolicy{group}
+	nomadClient.count = 3
+
+	// Scaling beyond the policy bounds requires force.
+	var out structs.ScaleResponse
+	args := &structs.ScaleRequest{
+		Actor:     "api:127.0.0.1",
+		Direction: "out",
+		Group:     "cache",
+		JobID:     "example",
+	}
+	if err := s.RPC("Scaling.Job", args, &out); err == nil ||
+		!strings.Contains(err.Error(), "outside of the scaling policy bounds") {
+		t.Fatalf("expected a bounds error but got %v", err)
+	}
+
+	args.Force = true
+	if err := s.RPC("Scaling.Job", args, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Events) != 1 || out.Events[0].CountAfter != 4 ||
+		out.Events[0].Type != structs.EventTypeManual ||
+		out.Events[0].Reason != "manually requested by api:127.0.0.1" {
+		t.Fatalf("expected a manual scale-out event but got %#v", out.Events)
+	}
+
+	events, err := s.config.StateStore.ListEvents(
+		"replicator/config/history/jobs/example/cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != structs.EventTypeManual {
+		t.Fatalf("expected the manual event to be recorded but got %#v", events)
+	}
+
+	// A group already at the desired count is not scaled.
+	count := 4
+	out = structs.ScaleResponse{}
+	args = &structs.ScaleRequest{Count: &count, Group: "cache", JobID: "example"}
+	if err := s.RPC("Scaling.Job", args, &out); err != nil || len(out.Events) != 0 {
+		t.Fatalf("expected no scaling operation but got %#v, %v", out.Events, err)
+	}
+
+	// Groups with an operation in progress are rejected.
+	s.lockResource(JobType + "/example/cache")
+	if err := s.RPC("Scaling.Job", args, &out); err == nil {
+		t.Fatalf("expected an error for a group with an operation in progress")
+	}
+	s.unlockResource(JobType + "/example/cache")
+
+	// Groups without a scaling policy are rejected.
+	args.Group = "missing"
+	if err := s.RPC("Scaling.Job", args, &out); err == nil {
+		t.Fatalf("expected an error for a group without a scaling policy")
+	}
+}
+
+func TestScaling_Pool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, nomadClient := testManualServer(t, dir)
+	defer s.leaderCancel()
+	defer s.config.StateStore.(interface{ Close() error }).Close()
+
+	provider := &testScalingProvider{}
+	workerPool := structs.NewWorkerPool()
+	workerPool.Name = "example-pool"
+	workerPool.ScalingProvider = provider
+	workerPool.Nodes["node-1"] = &nomad.Node{ID: "node-1"}
+	workerPool.Nodes["node-2"] = &nomad.Node{ID: "node-2"}
+	s.nodeRegistry.WorkerPools["example-pool"] = workerPool
+
+	// Failsafe mode is honoured unless forced.
+	state := &structs.ScalingState{
+		FailsafeMode: true,
+		StatePath:    "replicator/config/state/nodes/example-pool",
+	}
+	if err := s.config.StateStore.PersistState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	var out structs.ScaleResponse
+	count := 1
+	args := &structs.ScaleRequest{
+		Actor: "api:127.0.0.1",
+		Count: &count,
+		Pool:  "example-pool",
+	}
+	if err := s.RPC("Scaling.Pool", args, &out); err == nil ||
+		!strings.Contains(err.Error(), "failsafe mode") {
+		t.Fatalf("expected a failsafe error but got %v", err)
+	}
+
+	// The least allocated node is drained before being removed.
+	args.Force = true
+	if err := s.RPC("Scaling.Pool", args, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Events) != 1 || out.Events[0].CountAfter != 1 ||
+		out.Events[0].Outcome != structs.EventOutcomeSuccess ||
+		out.Events[0].Type != structs.EventTypeManual {
+		t.Fatalf("expected a single manual scale-in event but got %#v", out.Events)
+	}
+	if len(nomadClient.drained) != 1 || len(provider.targets) != 1 ||
+		provider.targets[0] != "10.0.0.1" {
+		t.Fatalf("expected node-1 to be drained and removed but got %v, %v",
+			nomadClient.drained, provider.targets)
+	}
+	if workerPool.State.PendingOperation != nil {
+		t.Fatalf("expected the operation to be completed but got %#v",
+			workerPool.State.PendingOperation)
+	}
+
+	// Scaling out adds a node at a time until the count is reached.
+	count = 4
+	out = structs.ScaleResponse{}
+	if err := s.RPC("Scaling.Pool", args, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Events) != 2 || out.Events[1].CountBefore != 3 ||
+		out.Events[1].CountAfter != 4 {
+		t.Fatalf("expected two scale-out events but got %#v", out.Events)
+	}
+
+	args.Pool = "missing"
+	if err := s.RPC("Scaling.Pool", args, &out); err == nil {
+		t.Fatalf("expected an error for an unknown worker pool")
+	}
+}
+
+func TestScaling_manualCount(t *testing.T) {
+	zero := 0
+
+	cases := []struct {
+		args     *structs.ScaleRequest
+		expected int
+		err      bool
+	}{
+		{&structs.ScaleRequest{Direction: "out"}, 4, false},
+		{&structs.ScaleRequest{Direction: "In"}, 2, false},
+		{&structs.ScaleRequest{Count: &zero}, 0, false},
+		{&structs.ScaleRequest{}, 0, true},
+		{&structs.ScaleRequest{Direction: "up"}, 0, true},
+	}
+
+	for _, c := range cases {
+		count, err := manualCount(3, c.args)
+		if (err != nil) != c.err || count != c.expected {
+			t.Fatalf("expected %v (error: %v) for %#v but got %v (%v)", c.expected,
+				c.err, c.args, count, err)
+		}
+	}
+}
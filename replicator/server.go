@@ -1,13 +1,20 @@
 package replicator
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/rpc"
 	"reflect"
+	"sync"
 	"time"
 
+	"github.com/hashicorp/raft"
+
+	"github.com/elsevier-core-engineering/replicator/client"
+	"github.com/elsevier-core-engineering/replicator/helper"
 	"github.com/elsevier-core-engineering/replicator/logging"
 	"github.com/elsevier-core-engineering/replicator/replicator/structs"
 )
@@ -15,27 +22,89 @@ import (
 // Server is the Replicator server that is responsible for running the API and
 // all scaling tasks.
 type Server struct {
+	// aclLock serializes updates to the ACL tokens and policies.
+	aclLock sync.Mutex
+
 	// candaidate is our LeaderCandidate for the runner instance.
 	candidate *LeaderCandidate
 
 	// config is the Config that created this Runner. It is used internally to
-	// construct other objects and pass data.
-	config *structs.Config
+	// construct other objects and pass data. A reload replaces the config with
+	// an updated copy under configLock rather than modifying it, so it must be
+	// read with Config once the server is running.
+	config     *structs.Config
+	configLock sync.RWMutex
 
 	// endpoints represents the Replicator API endpoints.
 	endpoints endpoints
 
+	// leaderCtx is the context passed to scaling operations, it is cancelled
+	// when the server loses leadership or shuts down.
+	leaderCtx    context.Context
+	leaderCancel context.CancelFunc
+	leaderLock   sync.Mutex
+
+	// jobScalingPolicies is Replicator's view of Nomad job scaling policies.
+	jobScalingPolicies *structs.JobScalingPolicies
+
+	// nodeRegistry is Replicator's view of the worker pools and nodes of the
+	// Nomad cluster and is only populated when cluster scaling is enabled.
+	nodeRegistry *structs.NodeRegistry
+
+	// raft is the embedded Raft cluster member used for leader election and
+	// state replication when the raft state backend is enabled.
+	raft          *raft.Raft
+	raftLayer     *RaftLayer
+	raftStore     *client.RaftLogStore
+	raftTransport *raft.NetworkTransport
+
+	// reloadLock serializes configuration reloads.
+	reloadLock sync.Mutex
+
+	// connPool holds the connections used to forward requests to the leader.
+	connPool *connPool
+
 	rpcAdvertise net.Addr
 	rpcListener  net.Listener
 	rpcServer    *rpc.Server
 
-	shutdown     bool
-	shutdownChan chan struct{}
+	shutdown       bool
+	shutdownChan   chan struct{}
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
+
+	// operations tracks the worker pools and job groups with a scaling
+	// operation in progress so that manual and automatic scaling operations do
+	// not act on the same resource concurrently.
+	operations    map[string]bool
+	operationLock sync.Mutex
+
+	// subsystems tracks the running background subsystems of the server which
+	// can be restarted independently when the configuration is reloaded.
+	subsystems    map[string]*subsystem
+	subsystemLock sync.Mutex
+
+	// tls provides the certificates used to serve the HTTP API and RPC
+	// listener when TLS is enabled.
+	tls *helper.TLSConfigurator
+
+	// utilization tracks the rolling resource utilization history of each
+	// task evaluated during job scaling.
+	utilization *structs.UtilizationHistory
 }
 
 // endpoints represents the Replicator API endpoints.
 type endpoints struct {
-	Status *Status
+	ACL             *ACL
+	Agent           *Agent
+	Event           *Event
+	Failsafe        *Failsafe
+	History         *History
+	Jobs            *Jobs
+	Pools           *Pools
+	Recommendations *Recommendations
+	Scaling         *Scaling
+	Status          *Status
 }
 
 type inmemCodec struct {
@@ -53,33 +122,32 @@ func NewServer(config *structs.Config) (*Server, error) {
 		config:       config,
 		rpcServer:    rpc.NewServer(),
 		shutdownChan: make(chan struct{}),
+		subsystems:   make(map[string]*subsystem),
 	}
+	s.shutdownCtx, s.shutdownCancel = context.WithCancel(context.Background())
+	s.connPool = newConnPool(s.dialLeader)
 
-	// Setup our LeaderCandidate object for leader elections and session renewal.
-	leaderKey := s.config.ConsulKeyRoot + "/" + "leader"
-	s.candidate = newLeaderCandidate(s.config.ConsulClient, leaderKey,
-		leaderLockTimeout)
-	go s.leaderTicker()
-
-	jobScalingPolicy := newJobScalingPolicy()
-
-	if !s.config.ClusterScalingDisable || !s.config.JobScalingDisable {
-		// Setup our JobScalingPolicy Watcher and start running this.
-		go s.config.NomadClient.JobWatcher(jobScalingPolicy)
+	// Setup the broker used to publish events to the event stream.
+	if config.EventBroker == nil {
+		config.EventBroker = structs.NewEventBroker()
 	}
 
-	if !s.config.ClusterScalingDisable {
-		// Setup the node registry and initiate worker pool and node discovery.
-		nodeRegistry := structs.NewNodeRegistry()
-		go s.config.NomadClient.NodeWatcher(nodeRegistry, s.config)
+	// Scaling operations are not permitted until leadership is confirmed.
+	s.leaderCtx, s.leaderCancel = context.WithCancel(s.shutdownCtx)
+	s.leaderCancel()
 
-		// Launch our cluster scaling main ticker function
-		go s.clusterScalingTicker(nodeRegistry, jobScalingPolicy)
-	}
+	// Setup our LeaderCandidate object for leader elections and session renewal.
+	leaderKey := config.ConsulKeyRoot + "/" + "leader"
+	s.candidate = newLeaderCandidate(config.ConsulClient, leaderKey,
+		leaderLockTimeout)
 
-	// Launch our job scaling main ticker function
-	if !s.config.JobScalingDisable {
-		go s.jobScalingTicker(jobScalingPolicy)
+	// Load the certificates used to serve the HTTP API and RPC listener.
+	if tls := config.TLS; tls != nil && (tls.EnableHTTP || tls.EnableRPC) {
+		configurator, err := helper.NewTLSConfigurator(tls)
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup TLS: %v", err)
+		}
+		s.tls = configurator
 	}
 
 	if err := s.setupRPC(); err != nil {
@@ -87,16 +155,73 @@ func NewServer(config *structs.Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to start RPC layer: %v", err)
 	}
 
+	// Publish the advertised RPC address when acquiring leadership so that
+	// followers are able to forward requests to the leader.
+	s.candidate.advertise = s.rpcAdvertise.String()
+
 	// Start the RPC listeners
 	go s.listen()
-	logging.Info("core/server: the RPC server has started and is listening at %v", s.config.RPCAddr)
+	logging.Info("core/server: the RPC server has started and is listening at %v", s.rpcAdvertise)
+
+	backend := ""
+	if config.State != nil {
+		backend = config.State.Backend
+	}
+
+	switch backend {
+	case structs.StateBackendBolt:
+		// The bolt state backend holds an exclusive lock on a local database so
+		// only a single instance may run, which therefore always acts as leader
+		// without requiring Consul for leader election.
+		logging.Info("core/server: running in standalone mode using the bolt " +
+			"state backend, leader election is disabled")
+		s.candidate.standalone = true
+		s.candidate.leader = true
+
+	case structs.StateBackendRaft:
+		// The raft state backend forms a Raft cluster with the configured peers
+		// which elects the leader and replicates scaling state without Consul.
+		if err := s.setupRaft(); err != nil {
+			s.Shutdown()
+			return nil, fmt.Errorf("failed to start raft: %v", err)
+		}
+		logging.Info("core/server: running in raft mode, leader election is " +
+			"performed by the embedded raft cluster")
+
+	default:
+		go s.leaderTicker()
+	}
+
+	// Monitor leadership so in-flight scaling operations are cancelled when
+	// leadership is lost.
+	go s.monitorLeadership()
+
+	s.jobScalingPolicies = newJobScalingPolicy()
+
+	// Setup the node registry which is populated by worker pool and node
+	// discovery when cluster scaling is enabled.
+	s.nodeRegistry = structs.NewNodeRegistry()
+
+	// Setup the utilization history used for right-sizing recommendations.
+	s.utilization = structs.NewUtilizationHistory(config.RightSizing.HistorySize)
+
+	// Launch the watchers, scaling tickers and garbage collection enabled by
+	// the configuration.
+	s.startSubsystems(allSubsystems())
 
 	return s, nil
 }
 
 // Shutdown halts the execution of the server.
 func (s *Server) Shutdown() {
+	// Cancel any scaling operations in progress before resigning leadership.
+	s.shutdownCancel()
+
 	s.candidate.endCampaign()
+	s.shutdown = true
+
+	// Shutdown the embedded Raft cluster member, if running.
+	s.shutdownRaft()
 
 	// Shutdown the RPC listener.
 	if s.rpcListener != nil {
@@ -105,6 +230,15 @@ func (s *Server) Shutdown() {
 	}
 
 	close(s.shutdownChan)
+
+	// Close the idle connections used to forward requests to the leader.
+	s.connPool.Shutdown()
+
+	// Release any resources held by the state store, such as the lock on a
+	// local state database.
+	if closer, ok := s.Config().StateStore.(io.Closer); ok {
+		closer.Close()
+	}
 }
 
 func (s *Server) leaderTicker() {
@@ -125,57 +259,112 @@ func (s *Server) leaderTicker() {
 	}
 }
 
-func (s *Server) jobScalingTicker(jobPol *structs.JobScalingPolicies) {
+func (s *Server) jobScalingTicker(ctx context.Context) {
+	jobPol := s.jobScalingPolicies
+
 	ticker := time.NewTicker(
-		time.Second * time.Duration(s.config.JobScalingInterval),
+		time.Second * time.Duration(s.Config().JobScalingInterval),
 	)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			if s.candidate.isLeader() && len(jobPol.Policies) > 0 {
-				s.asyncJobScaling(jobPol)
+			leaderCtx := s.leaderContext()
+			if leaderCtx.Err() == nil && len(jobPol.Policies) > 0 {
+				// Job group metrics reflect the previous evaluation as job scaling
+				// does not wait for the evaluation to complete.
+				s.emitJobGroupMetrics()
+				s.asyncJobScaling(leaderCtx, jobPol)
 			}
-		case <-s.shutdownChan:
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (s *Server) clusterScalingTicker(nodeReg *structs.NodeRegistry, jobPol *structs.JobScalingPolicies) {
+func (s *Server) clusterScalingTicker(ctx context.Context) {
+	nodeReg := s.nodeRegistry
+	jobPol := s.jobScalingPolicies
+
 	ticker := time.NewTicker(
-		time.Second * time.Duration(s.config.ClusterScalingInterval),
+		time.Second * time.Duration(s.Config().ClusterScalingInterval),
 	)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			if s.candidate.isLeader() && len(nodeReg.WorkerPools) > 0 {
+			leaderCtx := s.leaderContext()
+			if leaderCtx.Err() == nil && len(nodeReg.WorkerPools) > 0 {
 				err := s.nodeProtectionCheck(nodeReg)
 				if err != nil {
 					logging.Error("core/runner: an error occurred while trying to "+
 						"protect the node running the Replicator leader: %v", err)
 				}
 
-				s.asyncClusterScaling(nodeReg, jobPol)
-
+				s.asyncClusterScaling(leaderCtx, nodeReg, jobPol)
+				s.emitPoolMetrics()
 			}
-		case <-s.shutdownChan:
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// Config returns the running configuration of the server. Reload replaces
+// the configuration rather than modifying it, so callers must call Config
+// again rather than holding on to the value across a reload, and must never
+// modify the value returned.
+func (s *Server) Config() *structs.Config {
+	s.configLock.RLock()
+	defer s.configLock.RUnlock()
+	return s.config
+}
+
+// IsLeader indicates whether the server currently holds leadership.
+func (s *Server) IsLeader() bool {
+	return s.candidate.isLeader()
+}
+
+// TLSConfigurator returns the provider of the certificates used to serve the
+// HTTP API and RPC listener, or nil if TLS is not enabled.
+func (s *Server) TLSConfigurator() *helper.TLSConfigurator {
+	return s.tls
+}
+
+// EventBroker returns the broker used to publish events to the event stream.
+func (s *Server) EventBroker() *structs.EventBroker {
+	return s.Config().EventBroker
+}
+
 // setupRPC is used to setup our endpoints and register the handlers as well as
 // setup the RPC listener.
 func (s *Server) setupRPC() error {
 
+	s.endpoints.ACL = &ACL{s}
+	s.endpoints.Agent = &Agent{s}
+	s.endpoints.Event = &Event{s}
+	s.endpoints.Failsafe = &Failsafe{s}
+	s.endpoints.History = &History{s}
+	s.endpoints.Jobs = &Jobs{s}
+	s.endpoints.Pools = &Pools{s}
+	s.endpoints.Recommendations = &Recommendations{s}
+	s.endpoints.Scaling = &Scaling{s}
 	s.endpoints.Status = &Status{s}
+
+	s.rpcServer.Register(s.endpoints.ACL)
+	s.rpcServer.Register(s.endpoints.Agent)
+	s.rpcServer.Register(s.endpoints.Event)
+	s.rpcServer.Register(s.endpoints.Failsafe)
+	s.rpcServer.Register(s.endpoints.History)
+	s.rpcServer.Register(s.endpoints.Jobs)
+	s.rpcServer.Register(s.endpoints.Pools)
+	s.rpcServer.Register(s.endpoints.Recommendations)
+	s.rpcServer.Register(s.endpoints.Scaling)
 	s.rpcServer.Register(s.endpoints.Status)
 
-	list, err := net.ListenTCP("tcp", s.config.RPCAddr)
+	list, err := net.ListenTCP("tcp", s.Config().RPCAddr)
 	if err != nil {
 		return err
 	}
@@ -203,6 +392,12 @@ func (i *inmemCodec) ReadRequestHeader(req *rpc.Request) error {
 }
 
 func (i *inmemCodec) ReadRequestBody(args interface{}) error {
+	if i.args == nil || args == nil {
+		return nil
+	}
+	sourceValue := reflect.Indirect(reflect.Indirect(reflect.ValueOf(i.args)))
+	dst := reflect.Indirect(reflect.Indirect(reflect.ValueOf(args)))
+	dst.Set(sourceValue)
 	return nil
 }
 
@@ -222,9 +417,10 @@ func (i *inmemCodec) Close() error {
 }
 
 // RPC is used to make an RPC call.
-func (s *Server) RPC(method string, reply interface{}) error {
+func (s *Server) RPC(method string, args interface{}, reply interface{}) error {
 	codec := &inmemCodec{
 		method: method,
+		args:   args,
 		reply:  reply,
 	}
 	if err := s.rpcServer.ServeRequest(codec); err != nil {
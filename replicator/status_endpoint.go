@@ -1,6 +1,8 @@
 package replicator
 
 import (
+	"os"
+
 	"github.com/elsevier-core-engineering/replicator/replicator/structs"
 )
 
@@ -15,13 +17,32 @@ func (s *Status) Leader(args interface{}, reply *structs.LeaderResponse) error {
 
 	var session string
 
+	// A standalone instance is always the leader and holds no session.
+	if s.srv.candidate.standalone {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+
+		reply.NodeID = hostname
+		reply.RPCAddr = s.srv.rpcAdvertise.String()
+		return nil
+	}
+
+	// The leader of a raft cluster is identified by its RPC address.
+	if s.srv.candidate.raft != nil {
+		reply.NodeID = string(s.srv.candidate.raft.Leader())
+		reply.RPCAddr = reply.NodeID
+		return nil
+	}
+
 	if s.srv.candidate.leader {
 		session = s.srv.candidate.session
 	} else {
 		session = ""
 	}
 
-	err := s.srv.config.ConsulClient.GetLeaderInfo(reply, &s.srv.candidate.key, session)
+	err := s.srv.Config().ConsulClient.GetLeaderInfo(reply, &s.srv.candidate.key, session)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,329 @@
+package structs
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Define the types of ACL token. Management tokens are granted every
+// capability while client tokens are granted the capabilities of their
+// policies.
+const (
+	ACLTokenManagement = "management"
+	ACLTokenClient     = "client"
+)
+
+// Define the resources ACL policies grant capabilities on.
+const (
+	ACLResourceFailsafe = "failsafe"
+	ACLResourceJob      = "job"
+	ACLResourceOperator = "operator"
+	ACLResourcePool     = "pool"
+)
+
+// Define the capabilities ACL policies grant on a resource. The write
+// capability implies the read capability and deny takes precedence over
+// capabilities granted by any other policy of the token.
+const (
+	ACLCapabilityDeny  = "deny"
+	ACLCapabilityRead  = "read"
+	ACLCapabilityWrite = "write"
+)
+
+// ACLTokenHeader is the HTTP header in which the secret ID of an ACL token is
+// presented to the HTTP API.
+const ACLTokenHeader = "X-Replicator-Token"
+
+// ACLAnonymousPolicy is the name of the policy applied to requests made
+// without a token.
+const ACLAnonymousPolicy = "anonymous"
+
+// Define the errors returned by RPC endpoints when a request is not
+// authorized by its ACL token.
+var (
+	ErrPermissionDenied = errors.New("Permission denied")
+	ErrTokenNotFound    = errors.New("ACL token not found")
+)
+
+// QueryOptions carries the ACL token of an RPC request which reads state. It
+// is sent along with the request when it is forwarded to the leader so that
+// the server answering the request can authorize it.
+type QueryOptions struct {
+	// AuthToken is the secret ID of the ACL token of the request.
+	AuthToken string
+}
+
+// WriteRequest carries the ACL token of an RPC request which modifies state.
+// It is sent along with the request when it is forwarded to the leader so
+// that the server performing the request can authorize it.
+type WriteRequest struct {
+	// AuthToken is the secret ID of the ACL token of the request.
+	AuthToken string
+}
+
+// ACL is the configuration struct that controls whether requests to the HTTP
+// API must present an ACL token.
+type ACL struct {
+	// Enabled requires requests to the HTTP API to be authorized by an ACL
+	// token.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ACLState is the document in which the ACL tokens and policies of the
+// cluster are persisted.
+type ACLState struct {
+	// Bootstrapped indicates whether the initial management token has been
+	// created.
+	Bootstrapped bool
+
+	// Policies are keyed by name and Tokens by accessor ID.
+	Policies map[string]*ACLPolicy
+	Tokens   map[string]*ACLToken
+
+	// ModifyIndex is the index of the ACL state in the state backend, used for
+	// check-and-set writes.
+	ModifyIndex uint64 `json:"-"`
+
+	// Path is the location of the ACL state in the state backend.
+	Path string `json:"-"`
+}
+
+// ACLPolicy grants capabilities on the resources of the HTTP API.
+type ACLPolicy struct {
+	Name        string
+	Description string
+
+	// Rules maps each resource to the capability granted on it.
+	Rules map[string]string
+
+	ModifyTime time.Time
+}
+
+// ACLToken authorizes requests to the HTTP API. The secret ID is presented
+// in the X-Replicator-Token header while the accessor ID is used to manage
+// the token without revealing the secret.
+type ACLToken struct {
+	AccessorID string
+	SecretID   string
+	Name       string
+
+	// Type is either management or client.
+	Type string
+
+	// Policies are the names of the policies of a client token.
+	Policies []string
+
+	CreateTime time.Time
+}
+
+// ACLBootstrapResponse is used for the ACL.Bootstrap response. Token is nil
+// if the ACL system has already been bootstrapped.
+type ACLBootstrapResponse struct {
+	Token *ACLToken
+}
+
+// ACLResolveRequest is used for the ACL.Resolve request.
+type ACLResolveRequest struct {
+	SecretID string
+}
+
+// ACLResolveResponse is used for the ACL.Resolve response. Token is nil if
+// no secret was provided, in which case the anonymous policy is returned if
+// it exists, or if the secret does not match a token.
+type ACLResolveResponse struct {
+	Token    *ACLToken
+	Policies []*ACLPolicy
+}
+
+// ACLTokenRequest is used for the ACL.GetToken, ACL.UpsertToken and
+// ACL.DeleteToken requests.
+type ACLTokenRequest struct {
+	AccessorID string
+	Token      *ACLToken
+
+	WriteRequest
+}
+
+// ACLTokenResponse is used for the ACL.GetToken and ACL.UpsertToken
+// responses. Token is nil if the token does not exist.
+type ACLTokenResponse struct {
+	Token *ACLToken
+}
+
+// ACLTokenListResponse is used for the ACL.ListTokens response. The secret
+// IDs of the tokens are omitted.
+type ACLTokenListResponse struct {
+	Tokens []*ACLToken
+}
+
+// ACLPolicyRequest is used for the ACL.GetPolicy, ACL.UpsertPolicy and
+// ACL.DeletePolicy requests.
+type ACLPolicyRequest struct {
+	Name   string
+	Policy *ACLPolicy
+
+	WriteRequest
+}
+
+// ACLPolicyResponse is used for the ACL.GetPolicy and ACL.UpsertPolicy
+// responses. Policy is nil if the policy does not exist.
+type ACLPolicyResponse struct {
+	Policy *ACLPolicy
+}
+
+// ACLPolicyListResponse is used for the ACL.ListPolicies response.
+type ACLPolicyListResponse struct {
+	Policies []*ACLPolicy
+}
+
+// Validate checks the name and rules of the policy.
+func (p *ACLPolicy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("policy name must not be empty")
+	}
+
+	for resource, capability := range p.Rules {
+		switch resource {
+		case ACLResourceFailsafe, ACLResourceJob, ACLResourceOperator,
+			ACLResourcePool:
+		default:
+			return fmt.Errorf("invalid policy resource %q", resource)
+		}
+
+		switch capability {
+		case ACLCapabilityDeny, ACLCapabilityRead, ACLCapabilityWrite:
+		default:
+			return fmt.Errorf("invalid capability %q for resource %v",
+				capability, resource)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the type and policies of the token against the ACL state.
+func (t *ACLToken) Validate(state *ACLState) error {
+	switch t.Type {
+	case ACLTokenManagement:
+		if len(t.Policies) > 0 {
+			return fmt.Errorf("management tokens must not have policies")
+		}
+	case ACLTokenClient:
+		if len(t.Policies) == 0 {
+			return fmt.Errorf("client tokens must have at least one policy")
+		}
+	default:
+		return fmt.Errorf("invalid token type %q", t.Type)
+	}
+
+	for _, name := range t.Policies {
+		if _, ok := state.Policies[name]; !ok {
+			return fmt.Errorf("policy %q does not exist", name)
+		}
+	}
+
+	return nil
+}
+
+// Redacted returns a copy of the token without the secret ID.
+func (t *ACLToken) Redacted() *ACLToken {
+	token := *t
+	token.SecretID = ""
+	return &token
+}
+
+// Actor identifies the token as the operator of an administrative change
+// without revealing its secret ID. A nil token is the anonymous token.
+func (t *ACLToken) Actor() string {
+	if t == nil {
+		return "token:anonymous"
+	}
+	if t.Name == "" {
+		return "token:" + t.AccessorID
+	}
+	return fmt.Sprintf("token:%v (%v)", t.Name, t.AccessorID)
+}
+
+// Allowed indicates whether the token is granted the capability on the
+// resource by the policies provided. A nil token is the anonymous token and
+// is granted the capabilities of the policies alone.
+func (t *ACLToken) Allowed(policies []*ACLPolicy, resource, capability string) bool {
+	if t != nil && t.Type == ACLTokenManagement {
+		return true
+	}
+
+	allowed := false
+	for _, policy := range policies {
+		switch policy.Rules[resource] {
+		case ACLCapabilityDeny:
+			return false
+		case ACLCapabilityWrite:
+			allowed = true
+		case ACLCapabilityRead:
+			if capability == ACLCapabilityRead {
+				allowed = true
+			}
+		}
+	}
+
+	return allowed
+}
+
+// Resolve returns the token matching the secret ID and its policies. A nil
+// token is returned if no token matches. An empty secret ID resolves to the
+// anonymous policy if it exists.
+func (s *ACLState) Resolve(secretID string) (*ACLToken, []*ACLPolicy) {
+	if secretID != "" {
+		for _, token := range s.Tokens {
+			if subtle.ConstantTimeCompare([]byte(token.SecretID), []byte(secretID)) != 1 {
+				continue
+			}
+
+			var policies []*ACLPolicy
+			for _, name := range token.Policies {
+				if policy, ok := s.Policies[name]; ok {
+					policies = append(policies, policy)
+				}
+			}
+			return token, policies
+		}
+		return nil, nil
+	}
+
+	if policy, ok := s.Policies[ACLAnonymousPolicy]; ok {
+		return nil, []*ACLPolicy{policy}
+	}
+	return nil, nil
+}
+
+// SortedTokens returns the tokens sorted by name and accessor ID.
+func (s *ACLState) SortedTokens() []*ACLToken {
+	tokens := make([]*ACLToken, 0, len(s.Tokens))
+	for _, token := range s.Tokens {
+		tokens = append(tokens, token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].Name != tokens[j].Name {
+			return tokens[i].Name < tokens[j].Name
+		}
+		return tokens[i].AccessorID < tokens[j].AccessorID
+	})
+	return tokens
+}
+
+// SortedPolicies returns the policies sorted by name.
+func (s *ACLState) SortedPolicies() []*ACLPolicy {
+	policies := make([]*ACLPolicy, 0, len(s.Policies))
+	for _, policy := range s.Policies {
+		policies = append(policies, policy)
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+	return policies
+}
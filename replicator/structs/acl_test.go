@@ -0,0 +1,68 @@
+package structs
+
+import (
+	"testing"
+)
+
+func TestACLToken_Allowed(t *testing.T) {
+	readonly := &ACLPolicy{
+		Name: "readonly",
+		Rules: map[string]string{
+			ACLResourceJob:  ACLCapabilityRead,
+			ACLResourcePool: ACLCapabilityRead,
+		},
+	}
+	operator := &ACLPolicy{
+		Name: "operator",
+		Rules: map[string]string{
+			ACLResourceJob:      ACLCapabilityWrite,
+			ACLResourceFailsafe: ACLCapabilityDeny,
+			ACLResourcePool:     ACLCapabilityWrite,
+		},
+	}
+
+	state := &ACLState{
+		Policies: map[string]*ACLPolicy{
+			ACLAnonymousPolicy: {
+				Name:  ACLAnonymousPolicy,
+				Rules: map[string]string{ACLResourceOperator: ACLCapabilityRead},
+			},
+			"operator": operator,
+			"readonly": readonly,
+		},
+		Tokens: map[string]*ACLToken{
+			"a": {AccessorID: "a", SecretID: "root", Type: ACLTokenManagement},
+			"b": {AccessorID: "b", SecretID: "ops", Type: ACLTokenClient,
+				Policies: []string{"readonly", "operator"}},
+		},
+	}
+
+	cases := []struct {
+		secret     string
+		resource   string
+		capability string
+		expected   bool
+	}{
+		{"root", ACLResourceFailsafe, ACLCapabilityWrite, true},
+		{"ops", ACLResourceJob, ACLCapabilityWrite, true},
+		{"ops", ACLResourcePool, ACLCapabilityRead, true},
+		{"ops", ACLResourceFailsafe, ACLCapabilityRead, false},
+		{"ops", ACLResourceOperator, ACLCapabilityRead, false},
+		{"", ACLResourceOperator, ACLCapabilityRead, true},
+		{"", ACLResourceOperator, ACLCapabilityWrite, false},
+		{"unknown", ACLResourceOperator, ACLCapabilityRead, false},
+	}
+
+	for _, c := range cases {
+		token, policies := state.Resolve(c.secret)
+		if allowed := token.Allowed(policies, c.resource, c.capability); allowed != c.expected {
+			t.Fatalf("expected token %q %v access to %v to be %v", c.secret,
+				c.capability, c.resource, c.expected)
+		}
+	}
+
+	invalid := &ACLPolicy{Name: "invalid", Rules: map[string]string{"node": ACLCapabilityRead}}
+	if err := invalid.Validate(); err == nil {
+		t.Fatalf("expected an error validating a policy with an invalid resource")
+	}
+}
@@ -0,0 +1,76 @@
+package structs
+
+import "time"
+
+// Define the statuses reported by agent health checks. An agent is only
+// considered unhealthy when a check is critical.
+const (
+	HealthPassing  = "passing"
+	HealthWarning  = "warning"
+	HealthCritical = "critical"
+)
+
+// AgentHealthResponse is used for the Agent.Health response.
+type AgentHealthResponse struct {
+	// Status is the most severe status of the health checks.
+	Status string
+
+	// Checks are the individual health checks of the agent.
+	Checks []*HealthCheck
+}
+
+// HealthCheck is the result of a single agent health check.
+type HealthCheck struct {
+	Name   string
+	Status string
+	Output string
+}
+
+// AgentSelfResponse is used for the Agent.Self response.
+type AgentSelfResponse struct {
+	// Name is the host name of the agent and Version the Replicator version.
+	Name    string
+	Version string
+
+	// HTTPAddr and RPCAddr are the addresses the agent is serving the API and
+	// RPC requests at.
+	HTTPAddr string
+	RPCAddr  string
+
+	// Leader indicates whether the agent is the leader and LeaderAddr is the
+	// advertised RPC address of the current leader.
+	Leader     bool
+	LeaderAddr string
+
+	ClusterScalingEnabled bool
+	JobScalingEnabled     bool
+	StateBackend          string
+
+	// Pools are the names of the worker pools discovered by the agent.
+	Pools []string
+
+	Watchers  []*WatcherStatus
+	Notifiers []*NotifierStatus
+
+	// Health is the result of the agent health checks.
+	Health *AgentHealthResponse
+}
+
+// WatcherStatus describes the progress of a Nomad watcher. LastIndex is the
+// Nomad index of the last change processed and LastPoll the time the watcher
+// last received a response from the Nomad API.
+type WatcherStatus struct {
+	Name      string
+	Running   bool
+	LastIndex uint64
+	LastPoll  time.Time
+}
+
+// NotifierStatus describes the outcome of the notifications sent by a
+// configured notifier.
+type NotifierStatus struct {
+	Name        string
+	LastAttempt time.Time
+	LastError   string
+	LastSuccess time.Time
+}
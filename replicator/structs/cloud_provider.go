@@ -1,5 +1,7 @@
 package structs
 
+import "context"
+
 // ScalingProvider provides a standardized interface for implementing
 // scaling support across different cloud providers or scaling technologies.
 type ScalingProvider interface {
@@ -10,6 +12,7 @@ type ScalingProvider interface {
 	// Scale is the primary entry point for provider specific scaling
 	// operations and is responsible for calling the appropriate
 	// provider internal methods for scale-out and scale-in operations,
-	// verification and rety (where applicable).
-	Scale(*WorkerPool, *Config, *NodeRegistry) error
+	// verification and rety (where applicable). Long running verification
+	// stops when the context is cancelled.
+	Scale(context.Context, *WorkerPool, *Config, *NodeRegistry) error
 }
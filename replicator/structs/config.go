@@ -9,6 +9,10 @@ import (
 // Config is the main configuration struct used to configure the replicator
 // application.
 type Config struct {
+	// ACL contains the configuration of the ACL system which authorizes
+	// requests to the HTTP API.
+	ACL *ACL `mapstructure:"acl"`
+
 	// BindAddress is the TCP IP address which Replicator will bind to for both
 	// HTTP and RPC.
 	BindAddress string `mapstructure:"bind_address"`
@@ -36,6 +40,10 @@ type Config struct {
 	// secure Consul installation.
 	ConsulToken string `mapstructure:"consul_token"`
 
+	// EventBroker publishes scaling evaluations, scaling events and leadership
+	// changes to the subscribers of the event stream.
+	EventBroker *EventBroker
+
 	// HTTPPort is the port to which the HPPT API will bind and listen.
 	HTTPPort string `mapstructure:"http_port"`
 
@@ -68,6 +76,14 @@ type Config struct {
 	// initialized backends.
 	Notification *Notification `mapstructure:"notification"`
 
+	// History contains the retention configuration of the scaling event
+	// history.
+	History *History `mapstructure:"history"`
+
+	// RightSizing contains the configuration used when calculating task
+	// resource right-sizing recommendations.
+	RightSizing *RightSizing `mapstructure:"right_sizing"`
+
 	RPCAddr      *net.TCPAddr
 	RPCAdvertise *net.TCPAddr
 
@@ -78,19 +94,121 @@ type Config struct {
 	// operations allowed for both job and worker pool scaling.
 	ScalingConcurrency int `mapstructure:"scaling_concurrency"`
 
+	// State contains the configuration of the backend used to persist
+	// scaling state.
+	State *State `mapstructure:"state"`
+
+	// StateStore provides the backend used to persist scaling state.
+	StateStore StateStore
+
 	// Telemetry is the configuration struct that controls the telemetry settings.
 	Telemetry *Telemetry `mapstructure:"telemetry"`
+
+	// TLS contains the configuration used to serve the HTTP API and RPC
+	// listener using TLS.
+	TLS *TLS `mapstructure:"tls"`
+}
+
+// TLS is the configuration struct that controls whether the HTTP API and RPC
+// listener are served using TLS and whether clients must present a
+// certificate signed by the CA. The certificates are reloaded when the agent
+// receives SIGHUP.
+type TLS struct {
+	// CAFile is the path to the PEM encoded CA certificate used to verify
+	// client certificates and the certificates of other servers.
+	CAFile string `mapstructure:"ca_file"`
+
+	// CertFile and KeyFile are the paths to the PEM encoded certificate and
+	// private key presented by the agent.
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+
+	// EnableHTTP serves the HTTP API using TLS.
+	EnableHTTP bool `mapstructure:"http"`
+
+	// EnableRPC serves the RPC listener using TLS and uses TLS when forwarding
+	// requests and replicating Raft traffic to other servers.
+	EnableRPC bool `mapstructure:"rpc"`
+
+	// VerifyIncoming requires clients of the HTTP API and RPC listener to
+	// present a certificate signed by the CA.
+	VerifyIncoming bool `mapstructure:"verify_incoming"`
+}
+
+// State is the configuration struct that controls where Replicator persists
+// scaling state.
+type State struct {
+	// Backend is the state storage backend to use, either consul, bolt or
+	// raft.
+	Backend string `mapstructure:"backend"`
+
+	// GCDisable disables garbage collection of the state of worker pools and
+	// job groups which no longer exist.
+	GCDisable bool `mapstructure:"gc_disable"`
+
+	// GCGracePeriod is the time in seconds a worker pool or job group must be
+	// absent before its state is deleted.
+	GCGracePeriod int `mapstructure:"gc_grace_period"`
+
+	// GCInterval is the time period in seconds between state garbage
+	// collection runs.
+	GCInterval int `mapstructure:"gc_interval"`
+
+	// Path is the location of the BoltDB database file used by the bolt
+	// backend, or of the Raft log database used by the raft backend.
+	Path string `mapstructure:"path"`
+
+	// RaftPeers is the list of RPC addresses of the Replicator servers which
+	// form the Raft cluster when using the raft backend.
+	RaftPeers []string `mapstructure:"raft_peers"`
 }
 
 // Telemetry is the struct that control the telemetry configuration. If a value
-// is present then telemetry is enabled. Currently statsd is only supported for
-// sending telemetry.
+// is present then telemetry is enabled. Metrics can be forwarded to statsd and
+// exposed to Prometheus through the HTTP API.
 type Telemetry struct {
+	// PrometheusMetrics enables the Prometheus metrics sink which is served at
+	// /v1/metrics?format=prometheus.
+	PrometheusMetrics bool `mapstructure:"prometheus_metrics"`
+
 	// StatsdAddress specifies the address of a statsd server to forward metrics
 	// to and should include the port.
 	StatsdAddress string `mapstructure:"statsd_address"`
 }
 
+// History is the configuration struct that controls how long scaling events
+// are retained in the event history of each worker pool and job group.
+type History struct {
+	// MaxEvents is the maximum number of events retained for each resource.
+	MaxEvents int `mapstructure:"max_events"`
+
+	// RetentionHours is the number of hours events are retained for.
+	RetentionHours int `mapstructure:"retention_hours"`
+}
+
+// RightSizing is the configuration struct that controls how task resource
+// right-sizing recommendations are calculated from utilization history.
+type RightSizing struct {
+	// CPUPercentile is the percentile of observed CPU utilization used as the
+	// basis for CPU recommendations.
+	CPUPercentile float64 `mapstructure:"cpu_percentile"`
+
+	// HeadroomPercent is the percentage added to the observed utilization to
+	// provide a safety margin in recommendations.
+	HeadroomPercent float64 `mapstructure:"headroom_percent"`
+
+	// HistorySize is the number of utilization samples retained for each task.
+	HistorySize int `mapstructure:"history_size"`
+
+	// MemoryPercentile is the percentile of observed memory utilization used as
+	// the basis for memory recommendations.
+	MemoryPercentile float64 `mapstructure:"memory_percentile"`
+
+	// MinSamples is the minimum number of utilization samples required before
+	// a recommendation is made for a task.
+	MinSamples int `mapstructure:"min_samples"`
+}
+
 // Notification is the control struct for Replicator notifications.
 type Notification struct {
 	// ClusterIdentifier is a friendly name which is used when sending
@@ -171,6 +289,14 @@ func (c *Config) Merge(b *Config) *Config {
 		config.ScalingConcurrency = b.ScalingConcurrency
 	}
 
+	// Apply the ACL config
+	if config.ACL == nil && b.ACL != nil {
+		acl := *b.ACL
+		config.ACL = &acl
+	} else if b.ACL != nil {
+		config.ACL = config.ACL.Merge(b.ACL)
+	}
+
 	// Apply the Telemetry config
 	if config.Telemetry == nil && b.Telemetry != nil {
 		telemetry := *b.Telemetry
@@ -179,6 +305,38 @@ func (c *Config) Merge(b *Config) *Config {
 		config.Telemetry = config.Telemetry.Merge(b.Telemetry)
 	}
 
+	// Apply the TLS config
+	if config.TLS == nil && b.TLS != nil {
+		tls := *b.TLS
+		config.TLS = &tls
+	} else if b.TLS != nil {
+		config.TLS = config.TLS.Merge(b.TLS)
+	}
+
+	// Apply the History config
+	if config.History == nil && b.History != nil {
+		history := *b.History
+		config.History = &history
+	} else if b.History != nil {
+		config.History = config.History.Merge(b.History)
+	}
+
+	// Apply the RightSizing config
+	if config.RightSizing == nil && b.RightSizing != nil {
+		rightSizing := *b.RightSizing
+		config.RightSizing = &rightSizing
+	} else if b.RightSizing != nil {
+		config.RightSizing = config.RightSizing.Merge(b.RightSizing)
+	}
+
+	// Apply the State config
+	if config.State == nil && b.State != nil {
+		state := *b.State
+		config.State = &state
+	} else if b.State != nil {
+		config.State = config.State.Merge(b.State)
+	}
+
 	// Apply the Notification config
 	if config.Notification == nil && b.Notification != nil {
 		notification := *b.Notification
@@ -190,10 +348,25 @@ func (c *Config) Merge(b *Config) *Config {
 	return &config
 }
 
+// Merge is used to merge two ACL configurations together.
+func (a *ACL) Merge(b *ACL) *ACL {
+	config := *a
+
+	if b.Enabled {
+		config.Enabled = true
+	}
+
+	return &config
+}
+
 // Merge is used to merge two Telemetry configurations together.
 func (t *Telemetry) Merge(b *Telemetry) *Telemetry {
 	config := *t
 
+	if b.PrometheusMetrics {
+		config.PrometheusMetrics = true
+	}
+
 	if b.StatsdAddress != "" {
 		config.StatsdAddress = b.StatsdAddress
 	}
@@ -201,6 +374,110 @@ func (t *Telemetry) Merge(b *Telemetry) *Telemetry {
 	return &config
 }
 
+// Merge is used to merge two TLS configurations together.
+func (t *TLS) Merge(b *TLS) *TLS {
+	config := *t
+
+	if b.CAFile != "" {
+		config.CAFile = b.CAFile
+	}
+
+	if b.CertFile != "" {
+		config.CertFile = b.CertFile
+	}
+
+	if b.EnableHTTP {
+		config.EnableHTTP = true
+	}
+
+	if b.EnableRPC {
+		config.EnableRPC = true
+	}
+
+	if b.KeyFile != "" {
+		config.KeyFile = b.KeyFile
+	}
+
+	if b.VerifyIncoming {
+		config.VerifyIncoming = true
+	}
+
+	return &config
+}
+
+// Merge is used to merge two History configurations together.
+func (h *History) Merge(b *History) *History {
+	config := *h
+
+	if b.MaxEvents > 0 {
+		config.MaxEvents = b.MaxEvents
+	}
+
+	if b.RetentionHours > 0 {
+		config.RetentionHours = b.RetentionHours
+	}
+
+	return &config
+}
+
+// Merge is used to merge two RightSizing configurations together.
+func (r *RightSizing) Merge(b *RightSizing) *RightSizing {
+	config := *r
+
+	if b.CPUPercentile > 0 {
+		config.CPUPercentile = b.CPUPercentile
+	}
+
+	if b.HeadroomPercent > 0 {
+		config.HeadroomPercent = b.HeadroomPercent
+	}
+
+	if b.HistorySize > 0 {
+		config.HistorySize = b.HistorySize
+	}
+
+	if b.MemoryPercentile > 0 {
+		config.MemoryPercentile = b.MemoryPercentile
+	}
+
+	if b.MinSamples > 0 {
+		config.MinSamples = b.MinSamples
+	}
+
+	return &config
+}
+
+// Merge is used to merge two State configurations together.
+func (s *State) Merge(b *State) *State {
+	config := *s
+
+	if b.Backend != "" {
+		config.Backend = b.Backend
+	}
+
+	if b.GCDisable {
+		config.GCDisable = b.GCDisable
+	}
+
+	if b.GCGracePeriod > 0 {
+		config.GCGracePeriod = b.GCGracePeriod
+	}
+
+	if b.GCInterval > 0 {
+		config.GCInterval = b.GCInterval
+	}
+
+	if b.Path != "" {
+		config.Path = b.Path
+	}
+
+	if len(b.RaftPeers) > 0 {
+		config.RaftPeers = b.RaftPeers
+	}
+
+	return &config
+}
+
 // Merge is used to merge two Notification configurations together.
 func (n *Notification) Merge(b *Notification) *Notification {
 	config := *n
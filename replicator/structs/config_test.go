@@ -15,6 +15,21 @@ func TestStructs_Merge(t *testing.T) {
 		JobScalingInterval:     10,
 		Telemetry:              &Telemetry{},
 		Notification:           &Notification{},
+		History: &History{
+			MaxEvents:      500,
+			RetentionHours: 168,
+		},
+		RightSizing: &RightSizing{
+			CPUPercentile:    95,
+			HeadroomPercent:  15,
+			HistorySize:      8640,
+			MemoryPercentile: 99,
+			MinSamples:       60,
+		},
+		State: &State{
+			Backend: "consul",
+			Path:    "replicator.db",
+		},
 	}
 
 	partialConfig := &Config{
@@ -26,10 +41,25 @@ func TestStructs_Merge(t *testing.T) {
 		Telemetry: &Telemetry{
 			StatsdAddress: "8.8.8.8:8125",
 		},
+		TLS: &TLS{
+			CertFile:   "agent.pem",
+			EnableHTTP: true,
+			KeyFile:    "agent-key.pem",
+		},
 		Notification: &Notification{
 			ClusterIdentifier:   "nomad-rocks",
 			PagerDutyServiceKey: "onlyopsoncall",
 		},
+		History: &History{
+			MaxEvents: 50,
+		},
+		RightSizing: &RightSizing{
+			HeadroomPercent: 25,
+		},
+		State: &State{
+			Backend:   "bolt",
+			GCDisable: true,
+		},
 	}
 
 	fullConfig := &Config{
@@ -42,8 +72,12 @@ func TestStructs_Merge(t *testing.T) {
 		JobScalingDisable:      true,
 		JobScalingInterval:     5,
 		ClusterScalingInterval: 60,
+		ACL: &ACL{
+			Enabled: true,
+		},
 		Telemetry: &Telemetry{
-			StatsdAddress: "8.8.8.8:8125",
+			PrometheusMetrics: true,
+			StatsdAddress:     "8.8.8.8:8125",
 		},
 		Notification: &Notification{
 			ClusterIdentifier:   "nomad-rocks",
@@ -63,10 +97,31 @@ func TestStructs_Merge(t *testing.T) {
 		Telemetry: &Telemetry{
 			StatsdAddress: "8.8.8.8:8125",
 		},
+		TLS: &TLS{
+			CertFile:   "agent.pem",
+			EnableHTTP: true,
+			KeyFile:    "agent-key.pem",
+		},
 		Notification: &Notification{
 			ClusterIdentifier:   "nomad-rocks",
 			PagerDutyServiceKey: "onlyopsoncall",
 		},
+		History: &History{
+			MaxEvents:      50,
+			RetentionHours: 168,
+		},
+		RightSizing: &RightSizing{
+			CPUPercentile:    95,
+			HeadroomPercent:  25,
+			HistorySize:      8640,
+			MemoryPercentile: 99,
+			MinSamples:       60,
+		},
+		State: &State{
+			Backend:   "bolt",
+			GCDisable: true,
+			Path:      "replicator.db",
+		},
 	}
 
 	fullExpected := &Config{
@@ -79,14 +134,33 @@ func TestStructs_Merge(t *testing.T) {
 		JobScalingDisable:      true,
 		JobScalingInterval:     5,
 		ClusterScalingInterval: 60,
+		ACL: &ACL{
+			Enabled: true,
+		},
 		Telemetry: &Telemetry{
-			StatsdAddress: "8.8.8.8:8125",
+			PrometheusMetrics: true,
+			StatsdAddress:     "8.8.8.8:8125",
 		},
 		Notification: &Notification{
 			ClusterIdentifier:   "nomad-rocks",
 			OpsGenieAPIKey:      "onlygenieoncall",
 			PagerDutyServiceKey: "onlyopsoncall",
 		},
+		History: &History{
+			MaxEvents:      500,
+			RetentionHours: 168,
+		},
+		RightSizing: &RightSizing{
+			CPUPercentile:    95,
+			HeadroomPercent:  15,
+			HistorySize:      8640,
+			MemoryPercentile: 99,
+			MinSamples:       60,
+		},
+		State: &State{
+			Backend: "consul",
+			Path:    "replicator.db",
+		},
 	}
 
 	partialResult := c.Merge(partialConfig)
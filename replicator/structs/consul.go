@@ -4,9 +4,10 @@ package structs
 // interacting with the Consul API.
 type ConsulClient interface {
 	// AcquireLeadership attempts to acquire a Consul leadersip lock using the
-	// provided session. If the lock is already taken this will return false in
+	// provided session, publishing the advertised RPC address of the agent as
+	// the lock value. If the lock is already taken this will return false in
 	// a show that there is already a leader.
-	AcquireLeadership(string, *string) bool
+	AcquireLeadership(string, *string, string) bool
 
 	// CreateSession creates a Consul session for use in the Leadership locking
 	// process and will spawn off the renewing of the session in order to ensure
@@ -17,18 +18,14 @@ type ConsulClient interface {
 	// details of the Replicator agent currently holding leadership.
 	GetLeaderInfo(*LeaderResponse, *string, string) error
 
+	// Leader returns the address of the Consul cluster leader and is used to
+	// verify connectivity to the Consul API.
+	Leader() (string, error)
+
 	// LoadPoolConfig is responsible for performing fallback loading of a
 	// worker pool configuration from Consul.
 	LoadPoolConfig(string) (map[string]string, error)
 
-	// PersistState is responsible for persistently storing scaling
-	// state information in the Consul Key/Value Store.
-	PersistState(*ScalingState) error
-
-	// ReadState attempts to read state tracking information from the Consul
-	// Key/Value Store from the path provided.
-	ReadState(*ScalingState, bool)
-
 	// ResignLeadership attempts to remove the leadership lock upon shutdown of the
 	// replicator daemon. If this is unsuccessful there is not too much we can do
 	// therefore there is no return.
@@ -0,0 +1,417 @@
+package structs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/helper/uuid"
+)
+
+// Define the topics of the event stream. Worker pool events are keyed by the
+// name of the worker pool, job events by the job and group joined by a slash
+// and leader events by the advertised RPC address of the agent.
+const (
+	TopicJob    = "job"
+	TopicLeader = "leader"
+	TopicPool   = "pool"
+)
+
+// Define the types of event published to the event stream.
+const (
+	StreamEventEvaluation = "evaluation"
+	StreamEventFailsafe   = "failsafe"
+	StreamEventLeadership = "leadership"
+	StreamEventScaling    = "scaling"
+)
+
+// QueryIndexHeader is the HTTP header in which the HTTP API returns the index
+// of the most recent event of the leader which relates to the resource
+// queried. Blocking queries wait for the index to exceed the index provided
+// by the client.
+const QueryIndexHeader = "X-Replicator-Index"
+
+// eventBufferSize is the number of events buffered for each subscriber before
+// the subscriber is considered too slow and is closed.
+const eventBufferSize = 256
+
+// eventHistorySize is the number of the most recent events retained by the
+// broker so that agents relaying the event stream of the leader can catch up
+// on the events published between their requests.
+const eventHistorySize = 1024
+
+// StreamEvent is an event published to the event stream.
+type StreamEvent struct {
+	// Index is the position of the event in the stream of the agent.
+	Index uint64 `json:"index"`
+
+	// Key identifies the worker pool, job group or agent the event belongs to.
+	Key string `json:"key"`
+
+	// Payload is the event, either an EvaluationEvent, a ScalingEvent or a
+	// LeadershipEvent depending on the type of event.
+	Payload interface{} `json:"payload"`
+
+	// Timestamp is the time the event was published.
+	Timestamp time.Time `json:"timestamp"`
+
+	Topic string `json:"topic"`
+	Type  string `json:"type"`
+}
+
+// EvaluationEvent is the result of a scaling evaluation of a worker pool or
+// job group.
+type EvaluationEvent struct {
+	// Direction is the scaling direction required by the evaluation.
+	Direction string `json:"direction"`
+
+	// Error is the reason the evaluation failed, if it failed.
+	Error string `json:"error,omitempty"`
+
+	// Metrics records the metric values used by the evaluation.
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// LeadershipEvent is published when an agent gains or loses leadership.
+type LeadershipEvent struct {
+	Leader  bool   `json:"leader"`
+	RPCAddr string `json:"rpc_addr"`
+}
+
+// EventIndexRequest is used for the Event.Index request.
+type EventIndexRequest struct {
+	// Topics restricts the index to the events of the topics, every event is
+	// included if no topics are specified.
+	Topics []EventTopic
+
+	// MinQueryIndex is the index the request blocks until exceeded, for up to
+	// MaxQueryTime. The request does not block if MaxQueryTime is zero.
+	MinQueryIndex uint64
+	MaxQueryTime  time.Duration
+
+	QueryOptions
+}
+
+// EventIndexResponse is used for the Event.Index response.
+type EventIndexResponse struct {
+	Index uint64
+}
+
+// EventListRequest is used for the Event.List request. Events are returned
+// from the position in the event stream identified by StreamID and
+// MinQueryIndex, blocking for up to MaxQueryTime until a matching event is
+// published. A request without a StreamID returns the current position of
+// the event stream without any events.
+type EventListRequest struct {
+	Topics []EventTopic
+
+	StreamID      string
+	MinQueryIndex uint64
+	MaxQueryTime  time.Duration
+
+	QueryOptions
+}
+
+// EventListResponse is used for the Event.List response. Events are encoded
+// as JSON as their payloads are not preserved by the RPC encoding. Index is
+// the position of the event stream the next request should be made from.
+type EventListResponse struct {
+	Events   [][]byte
+	Index    uint64
+	StreamID string
+}
+
+// EventTopic filters the events delivered to a subscriber by topic and key.
+// An empty or wildcard key matches every key of the topic. The key of a job
+// topic matches every group of the job.
+type EventTopic struct {
+	Topic string
+	Key   string
+}
+
+// ParseEventTopic parses a topic filter in the form topic or topic:key.
+func ParseEventTopic(filter string) (EventTopic, error) {
+	parts := strings.SplitN(filter, ":", 2)
+
+	topic := EventTopic{Topic: parts[0]}
+	if len(parts) == 2 {
+		topic.Key = parts[1]
+	}
+
+	switch topic.Topic {
+	case TopicJob, TopicLeader, TopicPool, "*":
+	default:
+		return topic, fmt.Errorf("invalid event topic %q", topic.Topic)
+	}
+
+	return topic, nil
+}
+
+// matches indicates whether the event matches the topic filter.
+func (t EventTopic) matches(event *StreamEvent) bool {
+	if t.Topic != "*" && t.Topic != event.Topic {
+		return false
+	}
+
+	if t.Key == "" || t.Key == "*" || t.Key == event.Key {
+		return true
+	}
+
+	return event.Topic == TopicJob && strings.HasPrefix(event.Key, t.Key+"/")
+}
+
+// EventSubscription receives the events matching its topic filters.
+type EventSubscription struct {
+	events chan *StreamEvent
+	topics []EventTopic
+}
+
+// Events returns the channel events are delivered on. The channel is closed
+// when the subscription ends, including when the subscriber falls too far
+// behind the stream.
+func (s *EventSubscription) Events() <-chan *StreamEvent {
+	return s.events
+}
+
+// matches indicates whether the event matches any topic of the subscription.
+// A subscription without topics matches every event.
+func (s *EventSubscription) matches(event *StreamEvent) bool {
+	if len(s.topics) == 0 {
+		return true
+	}
+
+	for _, topic := range s.topics {
+		if topic.matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// EventBroker publishes events to the subscribers of the event stream. A nil
+// broker discards published events.
+type EventBroker struct {
+	index       uint64
+	lock        sync.Mutex
+	subscribers map[*EventSubscription]struct{}
+
+	// id identifies the event stream of the broker, whose indexes are only
+	// meaningful within the stream.
+	id string
+
+	// history retains the most recently published events, oldest first.
+	history []*StreamEvent
+
+	// keyIndex tracks the index of the most recent event of each topic and
+	// key so that blocking queries only wake for the resources they query.
+	keyIndex map[EventTopic]uint64
+
+	// changed is closed and replaced each time an event is published to wake
+	// blocking queries.
+	changed chan struct{}
+}
+
+// NewEventBroker returns a new event broker.
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		id:          uuid.Generate(),
+		keyIndex:    make(map[EventTopic]uint64),
+		subscribers: make(map[*EventSubscription]struct{}),
+		changed:     make(chan struct{}),
+	}
+}
+
+// Publish delivers an event to every matching subscriber. Publishing never
+// blocks; a subscriber whose buffer is full is closed so that it can
+// reconnect rather than silently missing events.
+func (b *EventBroker) Publish(topic, key, eventType string, payload interface{}) {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.index++
+	b.keyIndex[EventTopic{Topic: topic, Key: key}] = b.index
+	close(b.changed)
+	b.changed = make(chan struct{})
+	event := &StreamEvent{
+		Index:     b.index,
+		Key:       key,
+		Payload:   payload,
+		Timestamp: time.Now(),
+		Topic:     topic,
+		Type:      eventType,
+	}
+
+	if len(b.history) == eventHistorySize {
+		b.history = append(b.history[:0], b.history[1:]...)
+	}
+	b.history = append(b.history, event)
+
+	for sub := range b.subscribers {
+		if !sub.matches(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe returns a subscription to the events matching any of the topics,
+// or every event if no topics are specified.
+func (b *EventBroker) Subscribe(topics []EventTopic) *EventSubscription {
+	sub := &EventSubscription{
+		events: make(chan *StreamEvent, eventBufferSize),
+		topics: topics,
+	}
+
+	b.lock.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.lock.Unlock()
+
+	return sub
+}
+
+// Unsubscribe ends the subscription.
+func (b *EventBroker) Unsubscribe(sub *EventSubscription) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Index returns the index of the most recently published event matching any
+// of the topics, or of any event if no topics are specified.
+func (b *EventBroker) Index(topics []EventTopic) uint64 {
+	if b == nil {
+		return 0
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.topicIndex(topics)
+}
+
+// topicIndex returns the index of the most recently published event matching
+// any of the topics. The lock must be held by the caller.
+func (b *EventBroker) topicIndex(topics []EventTopic) uint64 {
+	if len(topics) == 0 {
+		return b.index
+	}
+
+	var index uint64
+	for key, i := range b.keyIndex {
+		if i <= index {
+			continue
+		}
+
+		event := &StreamEvent{Topic: key.Topic, Key: key.Key}
+		for _, topic := range topics {
+			if topic.matches(event) {
+				index = i
+				break
+			}
+		}
+	}
+	return index
+}
+
+// WaitIndex blocks until an event matching any of the topics is published
+// with an index greater than the index provided, or the context is done,
+// returning the index of the most recent matching event. An index greater
+// than that of the most recently published event was not issued by this
+// broker, for example because the agent has restarted or leadership has
+// changed, so it is returned immediately.
+func (b *EventBroker) WaitIndex(ctx context.Context, topics []EventTopic,
+	index uint64) uint64 {
+
+	if b == nil {
+		return 0
+	}
+
+	for {
+		b.lock.Lock()
+		current, latest, changed := b.topicIndex(topics), b.index, b.changed
+		b.lock.Unlock()
+
+		if current > index || index > latest {
+			return current
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return current
+		}
+	}
+}
+
+// ID returns the identifier of the event stream of the broker.
+func (b *EventBroker) ID() string {
+	if b == nil {
+		return ""
+	}
+	return b.id
+}
+
+// WaitEvents blocks until an event matching any of the topics is published
+// with an index greater than the index provided, or the context is done. The
+// retained events matching the topics are returned along with the index of
+// the most recently published event. An error is returned if events after the
+// index are no longer retained or the index was not issued by this broker.
+func (b *EventBroker) WaitEvents(ctx context.Context, topics []EventTopic,
+	index uint64) ([]*StreamEvent, uint64, error) {
+
+	if b == nil {
+		return nil, 0, nil
+	}
+
+	sub := &EventSubscription{topics: topics}
+
+	for {
+		b.lock.Lock()
+		latest, changed := b.index, b.changed
+
+		if index > latest {
+			b.lock.Unlock()
+			return nil, latest, fmt.Errorf("event index %v is ahead of the event "+
+				"stream at index %v", index, latest)
+		}
+
+		if len(b.history) > 0 && b.history[0].Index > index+1 {
+			b.lock.Unlock()
+			return nil, latest, fmt.Errorf("events after index %v are no longer "+
+				"retained", index)
+		}
+
+		var events []*StreamEvent
+		for _, event := range b.history {
+			if event.Index > index && sub.matches(event) {
+				events = append(events, event)
+			}
+		}
+		b.lock.Unlock()
+
+		if len(events) > 0 {
+			return events, latest, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, latest, nil
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package structs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventBroker_Subscribe(t *testing.T) {
+	broker := NewEventBroker()
+
+	pool, err := ParseEventTopic("pool:default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	job, err := ParseEventTopic("job:web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	filtered := broker.Subscribe([]EventTopic{pool, job})
+	all := broker.Subscribe(nil)
+
+	broker.Publish(TopicPool, "default", StreamEventEvaluation, nil)
+	broker.Publish(TopicPool, "gpu", StreamEventEvaluation, nil)
+	broker.Publish(TopicJob, "web/frontend", StreamEventScaling, nil)
+	broker.Publish(TopicJob, "webhooks/api", StreamEventScaling, nil)
+	broker.Publish(TopicLeader, "10.0.0.1:1314", StreamEventLeadership, nil)
+
+	var keys []string
+	for len(filtered.Events()) > 0 {
+		keys = append(keys, (<-filtered.Events()).Key)
+	}
+
+	if len(keys) != 2 || keys[0] != "default" || keys[1] != "web/frontend" {
+		t.Fatalf("expected the events of the default pool and web job but got %v", keys)
+	}
+
+	if n := len(all.Events()); n != 5 {
+		t.Fatalf("expected 5 events without a filter but got %v", n)
+	}
+
+	// A subscriber which falls behind is closed once its buffer is full and
+	// the remaining subscriptions are closed when unsubscribed.
+	for i := 0; i < eventBufferSize; i++ {
+		broker.Publish(TopicPool, "default", StreamEventEvaluation, nil)
+	}
+
+	var received int
+	for range all.Events() {
+		received++
+	}
+
+	if received != eventBufferSize {
+		t.Fatalf("expected %v buffered events but got %v", eventBufferSize, received)
+	}
+
+	broker.Unsubscribe(all)
+	broker.Unsubscribe(filtered)
+
+	for range filtered.Events() {
+	}
+
+	if _, err := ParseEventTopic("node:example"); err == nil {
+		t.Fatalf("expected an error parsing an invalid topic")
+	}
+}
+
+func TestEventBroker_WaitIndex(t *testing.T) {
+	broker := NewEventBroker()
+	broker.Publish(TopicPool, "default", StreamEventEvaluation, nil)
+
+	// The index already exceeds the index provided.
+	if index := broker.WaitIndex(context.Background(), nil, 0); index != 1 {
+		t.Fatalf("expected index 1 but got %v", index)
+	}
+
+	// A timeout returns the current index.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if index := broker.WaitIndex(ctx, nil, 1); index != 1 {
+		t.Fatalf("expected index 1 after the timeout but got %v", index)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		broker.Publish(TopicJob, "web/frontend", StreamEventScaling, nil)
+	}()
+
+	if index := broker.WaitIndex(context.Background(), nil, 1); index != 2 {
+		t.Fatalf("expected index 2 but got %v", index)
+	}
+
+	// Events of other resources do not wake a query of a topic.
+	pool := []EventTopic{{Topic: TopicPool, Key: "default"}}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		broker.Publish(TopicJob, "web/frontend", StreamEventScaling, nil)
+		time.Sleep(10 * time.Millisecond)
+		broker.Publish(TopicPool, "default", StreamEventScaling, nil)
+	}()
+
+	if index := broker.WaitIndex(context.Background(), pool, 1); index != 4 {
+		t.Fatalf("expected index 4 but got %v", index)
+	}
+
+	// An index which was not issued by the broker is returned immediately.
+	if index := broker.WaitIndex(context.Background(), pool, 10); index != 4 {
+		t.Fatalf("expected index 4 for an unknown index but got %v", index)
+	}
+}
+
+func TestEventBroker_WaitEvents(t *testing.T) {
+	broker := NewEventBroker()
+	broker.Publish(TopicPool, "default", StreamEventScaling, nil)
+	broker.Publish(TopicJob, "web/frontend", StreamEventScaling, nil)
+
+	pool := []EventTopic{{Topic: TopicPool}}
+	events, index, err := broker.WaitEvents(context.Background(), pool, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].Key != "default" || index != 2 {
+		t.Fatalf("expected the pool event up to index 2 but got %v events up to %v",
+			len(events), index)
+	}
+
+	// A timeout returns no events and the current index.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if events, index, err = broker.WaitEvents(ctx, pool, 2); err != nil ||
+		len(events) != 0 || index != 2 {
+		t.Fatalf("expected no events at index 2 but got %v events at %v: %v",
+			len(events), index, err)
+	}
+
+	// Events which are no longer retained cannot be returned.
+	for i := 0; i < eventHistorySize; i++ {
+		broker.Publish(TopicPool, "default", StreamEventEvaluation, nil)
+	}
+	if _, _, err = broker.WaitEvents(context.Background(), pool, 1); err == nil {
+		t.Fatalf("expected an error for events which are no longer retained")
+	}
+
+	if _, _, err = broker.WaitEvents(context.Background(), pool, index+eventHistorySize+1); err == nil {
+		t.Fatalf("expected an error for an index ahead of the event stream")
+	}
+}
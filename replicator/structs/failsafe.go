@@ -1,5 +1,7 @@
 package structs
 
+import "time"
+
 // FailsafeMode is the configuration struct for administratively interacting
 // with the distributed failsafe lock.
 type FailsafeMode struct {
@@ -18,3 +20,51 @@ type FailsafeMode struct {
 	// Verb represents the action to be displayed during confirmation prompts.
 	Verb string
 }
+
+// FailsafeRequest is used for the Failsafe.Set request. The resource is
+// either a worker pool identified by name or a job group identified by the
+// job and group name joined by a slash.
+type FailsafeRequest struct {
+	// ResourceType is the type of resource, either worker_pool or job_group.
+	ResourceType string
+
+	// ResourceID identifies the worker pool or job group.
+	ResourceID string
+
+	// Enable indicates whether failsafe mode should be enabled or disabled.
+	Enable bool
+
+	// Actor identifies the operator making the change.
+	Actor string
+
+	WriteRequest
+}
+
+// FailsafeResponse is used for the Failsafe.Set response. Status is nil if
+// no state exists for the requested resource.
+type FailsafeResponse struct {
+	Status *FailsafeStatus
+}
+
+// FailsafeListResponse is used for the Failsafe.List response.
+type FailsafeListResponse struct {
+	Resources []*FailsafeStatus
+}
+
+// FailsafeStatus describes the failsafe mode of a worker pool or job group.
+type FailsafeStatus struct {
+	ResourceType string
+	ResourceID   string
+	FailsafeMode bool
+	FailureCount int
+
+	// Reason describes why failsafe mode was last changed and ChangedBy the
+	// operator who changed it, if it was changed administratively.
+	Reason    string
+	ChangedBy string
+
+	// Updated is the time failsafe mode was last changed, LastScalingEvent the
+	// time of the last scaling operation against the resource.
+	Updated          time.Time
+	LastScalingEvent time.Time
+}
@@ -10,8 +10,10 @@ import (
 func NewGroupScalingPolicy() *GroupScalingPolicy {
 	// Return a new group scaling policy object with default values set.
 	return &GroupScalingPolicy{
-		Cooldown:       60,
-		RetryThreshold: 1,
+		Cooldown:          60,
+		FollowRatio:       1,
+		RetryThreshold:    1,
+		VerticalThreshold: 10,
 	}
 }
 
@@ -21,23 +23,98 @@ type JobScalingPolicies struct {
 	LastChangeIndex uint64
 	Lock            sync.RWMutex
 	Policies        map[string][]*GroupScalingPolicy
+
+	// LastPoll is the time the job watcher last received a response from the
+	// Nomad API.
+	LastPoll time.Time
 }
 
 // GroupScalingPolicy represents all the information needed to make
 // JobTaskGroup scaling decisions.
 type GroupScalingPolicy struct {
-	Cooldown       time.Duration `mapstructure:"replicator_cooldown"`
-	Enabled        bool          `mapstructure:"replicator_enabled"`
-	RetryThreshold int           `mapstructure:"replicator_retry_threshold"`
-	GroupName      string
-	Max            int            `mapstructure:"replicator_max"`
-	Min            int            `mapstructure:"replicator_min"`
-	ScaleDirection string         `hash:"ignore"`
-	ScaleInCPU     float64        `mapstructure:"replicator_scalein_cpu"`
-	ScaleInMem     float64        `mapstructure:"replicator_scalein_mem"`
-	ScalingMetric  string         `hash:"ignore"`
-	ScaleOutCPU    float64        `mapstructure:"replicator_scaleout_cpu"`
-	ScaleOutMem    float64        `mapstructure:"replicator_scaleout_mem"`
-	Tasks          TaskAllocation `hash:"ignore"`
-	UID            string         `mapstructure:"replicator_notification_uid"`
+	Cooldown         time.Duration `mapstructure:"replicator_cooldown"`
+	EmergencySlope   float64       `mapstructure:"replicator_emergency_slope"`
+	Enabled          bool          `mapstructure:"replicator_enabled"`
+	RetryThreshold   int           `mapstructure:"replicator_retry_threshold"`
+	GroupName        string
+	Max              int            `mapstructure:"replicator_max"`
+	Min              int            `mapstructure:"replicator_min"`
+	ScaleDirection   string         `hash:"ignore"`
+	ScaleInCooldown  time.Duration  `mapstructure:"replicator_scalein_cooldown"`
+	ScaleInCPU       float64        `mapstructure:"replicator_scalein_cpu"`
+	ScaleInMem       float64        `mapstructure:"replicator_scalein_mem"`
+	ScalingMetric    string         `hash:"ignore"`
+	ScaleOutCooldown time.Duration  `mapstructure:"replicator_scaleout_cooldown"`
+	ScaleOutCPU      float64        `mapstructure:"replicator_scaleout_cpu"`
+	ScaleOutMem      float64        `mapstructure:"replicator_scaleout_mem"`
+	Tasks            TaskAllocation `hash:"ignore"`
+	UID              string         `mapstructure:"replicator_notification_uid"`
+
+	// DesiredCount is the count of the group in the job specification and
+	// RunningCount the number of running allocations of the group, as observed
+	// during the last scaling evaluation.
+	DesiredCount int `hash:"ignore"`
+	RunningCount int `hash:"ignore"`
+
+	// Follow couples the count of the group to the current count of another
+	// job group, referenced as job/group. The desired count is calculated as
+	// the followed count multiplied by FollowRatio, rounded up, plus
+	// FollowOffset and is always bounded by Min and Max.
+	Follow       string  `mapstructure:"replicator_follow"`
+	FollowOffset int     `mapstructure:"replicator_follow_offset"`
+	FollowRatio  float64 `mapstructure:"replicator_follow_ratio"`
+
+	// Vertical scaling allows Replicator to adjust the CPU and memory resources
+	// of the group's tasks within the declared bounds, based on the
+	// utilization history of each task. VerticalThreshold is the minimum
+	// percentage change required before a resource is adjusted.
+	VerticalCPUMax    int     `mapstructure:"replicator_vertical_cpu_max"`
+	VerticalCPUMin    int     `mapstructure:"replicator_vertical_cpu_min"`
+	VerticalEnabled   bool    `mapstructure:"replicator_vertical_enabled"`
+	VerticalMemMax    int     `mapstructure:"replicator_vertical_mem_max"`
+	VerticalMemMin    int     `mapstructure:"replicator_vertical_mem_min"`
+	VerticalThreshold float64 `mapstructure:"replicator_vertical_threshold"`
+}
+
+// TaskResources describes the desired CPU and memory resources of a task
+// during a vertical scaling operation.
+type TaskResources struct {
+	TaskName string
+	CPUMHz   int
+	MemoryMB int
+}
+
+// JobRequest is used to query the scaling status of a single job.
+type JobRequest struct {
+	JobID string
+
+	QueryOptions
+}
+
+// JobListResponse is used for the Jobs.List and Jobs.Get responses.
+type JobListResponse struct {
+	Groups []*JobGroupStatus
+}
+
+// JobGroupStatus describes the scaling policy of a job group alongside the
+// result of its last scaling evaluation and its scaling state.
+type JobGroupStatus struct {
+	// JobID is the ID of the job the group belongs to.
+	JobID string
+
+	// Policy is the scaling policy of the group. The task resource
+	// percentages and scale direction are those computed by the last scaling
+	// evaluation performed by the leader.
+	Policy GroupScalingPolicy
+
+	// CooldownRemaining is the number of seconds until the scaling cooldown
+	// of the group expires in the currently requested scale direction.
+	CooldownRemaining int
+
+	// FailsafeMode indicates whether the failsafe circuit breaker of the
+	// group has been tripped.
+	FailsafeMode bool
+
+	// FailureCount is the number of consecutive scaling failures of the group.
+	FailureCount int
 }
@@ -39,12 +39,18 @@ type NodeRegistry struct {
 	RegisteredNodes     map[string]string
 	RegisteredNodesHash uint64
 	WorkerPools         map[string]*WorkerPool
+
+	// LastPoll is the time the node watcher last received a response from the
+	// Nomad API.
+	LastPoll time.Time
 }
 
 // WorkerPool represents the scaling configuration of a discovered
 // worker pool and its associated node membership.
 type WorkerPool struct {
+	Capacity          *ClusterCapacity       `hash:"ignore"`
 	Cooldown          int                    `mapstructure:"replicator_cooldown"`
+	EmergencySlope    float64                `mapstructure:"replicator_emergency_slope"`
 	FaultTolerance    int                    `mapstructure:"replicator_node_fault_tolerance"`
 	Name              string                 `mapstructure:"replicator_worker_pool"`
 	NodeRegistrations map[string]time.Time   `hash:"ignore"`
@@ -54,12 +60,73 @@ type WorkerPool struct {
 	ProviderName      string                 `hash:"ignore" mapstructure:"replicator_provider"`
 	Region            string                 `mapstructure:"replicator_region"`
 	RetryThreshold    int                    `mapstructure:"replicator_retry_threshold"`
+	ScaleInCooldown   int                    `mapstructure:"replicator_scalein_cooldown"`
+	ScaleOutCooldown  int                    `mapstructure:"replicator_scaleout_cooldown"`
 	ScalingEnabled    bool                   `mapstructure:"replicator_enabled"`
 	ScalingProvider   ScalingProvider        `hash:"ignore"`
 	ScalingThreshold  int                    `mapstructure:"replicator_scaling_threshold"`
 	State             *ScalingState          `hash:"ignore"`
 }
 
+// PoolRequest is used to query the status of a single worker pool.
+type PoolRequest struct {
+	Name string
+
+	QueryOptions
+}
+
+// PoolListResponse is used for the Pools.List and Pools.Get responses.
+type PoolListResponse struct {
+	Pools []*PoolStatus
+}
+
+// PoolStatus describes a worker pool as tracked by the node registry
+// alongside the result of its last scaling evaluation and its scaling state.
+type PoolStatus struct {
+	Name             string
+	ProtectedNode    string
+	ProviderName     string
+	Region           string
+	ScalingEnabled   bool
+	Cooldown         int
+	ScaleInCooldown  int
+	ScaleOutCooldown int
+
+	// Nodes are the worker nodes registered with the pool, sorted by the time
+	// of their registration.
+	Nodes []*PoolNode
+
+	// Capacity is the capacity computed by the last scaling evaluation of the
+	// pool performed by the leader. It is nil until the pool is evaluated.
+	Capacity *ClusterCapacity
+
+	// ScaleInRequests and ScaleOutRequests are the number of consecutive
+	// scaling requests made, which must reach ScalingThreshold before a
+	// scaling operation is performed.
+	ScaleInRequests  int
+	ScaleOutRequests int
+	ScalingThreshold int
+
+	// CooldownRemaining is the number of seconds until the scaling cooldown
+	// of the pool expires in the last requested scaling direction.
+	CooldownRemaining int
+
+	// FailsafeMode indicates whether the failsafe circuit breaker of the pool
+	// has been tripped.
+	FailsafeMode bool
+
+	// FailureCount is the number of consecutive scaling failures of the pool.
+	FailureCount int
+}
+
+// PoolNode describes a worker node registered with a worker pool.
+type PoolNode struct {
+	ID         string
+	Name       string
+	Address    string
+	Registered time.Time
+}
+
 // MostRecentNode represents the most recently launched node in a
 // worker pool after a scale-out operation.
 type MostRecentNode struct {
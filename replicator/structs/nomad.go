@@ -1,6 +1,8 @@
 package structs
 
 import (
+	"context"
+
 	nomad "github.com/hashicorp/nomad/api"
 )
 
@@ -21,11 +23,16 @@ const (
 // NomadClient exposes all API methods needed to interact with the Nomad API,
 // evaluate cluster capacity and allocations and make scaling decisions.
 type NomadClient interface {
+	// CancelDrain removes a worker node from drain mode so that it is eligible
+	// to receive allocations again.
+	CancelDrain(string) error
+
 	ClusterScalingSafe(*ClusterCapacity, *WorkerPool) bool
 
 	// DrainNode places a worker node in drain mode to stop future allocations
-	// and migrate existing allocations to other worker nodes.
-	DrainNode(string) error
+	// and migrate existing allocations to other worker nodes. Waiting for the
+	// migration stops when the context is cancelled.
+	DrainNode(context.Context, string) error
 
 	// EvaluatePoolScaling evaluates a worker pool capacity and utilization,
 	// and determines whether a scaling operation is required and safe to
@@ -34,27 +41,50 @@ type NomadClient interface {
 
 	// EvaluateJobScaling compares the consumed resource percentages of a Job
 	// group against its scaling policy to determine whether a scaling event is
-	// required.
-	EvaluateJobScaling(string, []*GroupScalingPolicy) error
+	// required. Task utilization is recorded in the supplied history.
+	EvaluateJobScaling(string, []*GroupScalingPolicy, *UtilizationHistory) error
 
 	// GetAllocationStats discovers the resources consumed by a particular Nomad
-	// allocation.
-	GetAllocationStats(*nomad.Allocation, *GroupScalingPolicy) (float64, float64)
+	// allocation and records the utilization of each task in the history.
+	GetAllocationStats(*nomad.Allocation, *GroupScalingPolicy, *UtilizationHistory) (float64, float64)
 
-	// GetJobAllocations identifies all allocations for an active job.
-	GetJobAllocations([]*nomad.AllocationListStub, *GroupScalingPolicy)
+	// GetJobGroupCount returns the current count of a job group.
+	GetJobGroupCount(string, string) (int, error)
+
+	// GetJobAllocations identifies all allocations for an active job group.
+	GetJobAllocations([]*nomad.AllocationListStub, *GroupScalingPolicy, *UtilizationHistory)
 
 	// IsJobInDeployment checks to see whether the supplied Nomad job is currently
 	// in the process of a deployment.
 	IsJobInDeployment(string) bool
 
 	// JobGroupScale scales a particular job group, confirming that the action
-	// completes successfully.
-	JobGroupScale(string, *GroupScalingPolicy, *ScalingState)
+	// completes successfully. The returned event describes the outcome of the
+	// operation and is nil if no operation was attempted. Confirmation stops
+	// when the context is cancelled.
+	JobGroupScale(context.Context, string, *GroupScalingPolicy, *ScalingState) *ScalingEvent
+
+	// JobGroupScaleTo sets the count of a particular job group, confirming
+	// that the action completes successfully. The returned event describes the
+	// outcome of the operation and is nil if no operation was attempted.
+	// Confirmation stops when the context is cancelled.
+	JobGroupScaleTo(context.Context, string, *GroupScalingPolicy, int, *ScalingState) *ScalingEvent
+
+	// JobGroupResize updates the resources of the tasks within a job group,
+	// confirming that the resulting rollout completes successfully. The
+	// returned event describes the outcome of the operation and is nil if no
+	// operation was attempted. Confirmation stops when the context is
+	// cancelled.
+	JobGroupResize(context.Context, string, *GroupScalingPolicy, []*TaskResources, *ScalingState) *ScalingEvent
 
 	// JobWatcher is the main entry point into Replicators process of reading and
-	// updating its JobScalingPolicies tracking.
-	JobWatcher(*JobScalingPolicies)
+	// updating its JobScalingPolicies tracking. It returns once the context is
+	// cancelled.
+	JobWatcher(context.Context, *JobScalingPolicies)
+
+	// Leader returns the address of the Nomad cluster leader and is used to
+	// verify connectivity to the Nomad API.
+	Leader() (string, error)
 
 	// LeastAllocatedNode determines which worker pool node is consuming the
 	// least amount of the cluster's most-utilized resource.
@@ -65,8 +95,9 @@ type NomadClient interface {
 	NodeReverseLookup(string) (string, error)
 
 	// NodeWatcher provides an automated mechanism to discover worker pools and
-	// nodes and populate the node registry.
-	NodeWatcher(*NodeRegistry, *Config)
+	// nodes and populate the node registry. It returns once the context is
+	// cancelled.
+	NodeWatcher(context.Context, *NodeRegistry, *Config)
 
 	// MostUtilizedResource calculates which resource is most-utilized across the
 	// cluster. The worst-case allocation resource is prioritized when making
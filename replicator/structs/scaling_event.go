@@ -0,0 +1,163 @@
+package structs
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Define the types of event recorded in the scaling event history.
+const (
+	EventTypeFailsafe = "failsafe"
+	EventTypeManual   = "manual"
+	EventTypeResize   = "resize"
+	EventTypeScale    = "scale"
+)
+
+// Define the outcomes of events recorded in the scaling event history.
+const (
+	EventOutcomeFailsafeDisabled = "disabled"
+	EventOutcomeFailsafeEnabled  = "enabled"
+	EventOutcomeFailure          = "failure"
+	EventOutcomeInterrupted      = "interrupted"
+	EventOutcomeSuccess          = "success"
+)
+
+// ScalingEvent is an entry in the append-only scaling event history of a
+// worker pool or job group.
+type ScalingEvent struct {
+	// CountAfter is the number of nodes or allocations the resource was
+	// scaled to.
+	CountAfter int `json:"count_after"`
+
+	// CountBefore is the number of nodes or allocations the resource had prior
+	// to the event.
+	CountBefore int `json:"count_before"`
+
+	// Direction is the scaling direction of the event, if applicable.
+	Direction string `json:"direction"`
+
+	// Metrics records the metric values which triggered the event.
+	Metrics map[string]float64 `json:"metrics"`
+
+	// NodeID identifies the Replicator instance which recorded the event.
+	NodeID string `json:"node_id"`
+
+	// Outcome is the result of the event.
+	Outcome string `json:"outcome"`
+
+	// Reason provides the failure reason or additional detail on the outcome.
+	Reason string `json:"reason"`
+
+	// ResourceID identifies the worker pool or job group the event belongs to.
+	ResourceID string `json:"resource_id"`
+
+	// ResourceType represents the type of resource the event belongs to.
+	ResourceType string `json:"resource_type"`
+
+	// Timestamp is the time the event was recorded.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Type is the type of event.
+	Type string `json:"type"`
+}
+
+// HistoryRequest is used for the History.List request. Events are returned
+// for a worker pool if Pool is set, otherwise for the groups of a job.
+type HistoryRequest struct {
+	Group string
+	Job   string
+	Pool  string
+
+	QueryOptions
+}
+
+// HistoryListResponse is used for the History.List response.
+type HistoryListResponse struct {
+	Events []*ScalingEvent
+}
+
+// ScaleRequest is used for the Scaling.Job and Scaling.Pool requests made
+// by an operator. The resource is scaled one step in Direction if set,
+// otherwise to Count.
+type ScaleRequest struct {
+	// JobID and Group identify the job group to scale.
+	JobID string
+	Group string
+
+	// Pool identifies the worker pool to scale.
+	Pool string
+
+	// Count is the desired count of the resource, it must be set if no
+	// Direction is specified.
+	Count *int
+
+	// Direction is the scaling direction, either in or out.
+	Direction string
+
+	// Force overrides the min and max bounds, the scaling provider safety
+	// check and failsafe mode.
+	Force bool
+
+	// Actor identifies the operator requesting the operation.
+	Actor string
+
+	WriteRequest `json:"-"`
+}
+
+// ScaleResponse is used for the Scaling.Job and Scaling.Pool responses.
+// Events describe each scaling operation performed, no events are returned
+// if the resource is already at the desired count.
+type ScaleResponse struct {
+	Events []*ScalingEvent
+}
+
+// HistoryPath returns the location of the scaling event history of the
+// resource whose state is stored at the provided path, along with the ID of
+// the resource: the worker pool name or the job and group name joined by a
+// slash. History is stored under a separate prefix to state so that listing
+// state is unaffected. An empty path is returned if the state path is not
+// recognised.
+func HistoryPath(statePath string) (path, resourceID string) {
+	for _, resource := range []string{"/state/nodes/", "/state/jobs/"} {
+		if i := strings.Index(statePath, resource); i >= 0 {
+			path = statePath[:i] + "/history/" + statePath[i+len("/state/"):]
+			resourceID = statePath[i+len(resource):]
+			return
+		}
+	}
+
+	return
+}
+
+// SortEvents sorts scaling events by timestamp, oldest first.
+func SortEvents(events []*ScalingEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+}
+
+// Prune removes events which fall outside the configured retention,
+// returning the retained events sorted oldest first.
+func (h *History) Prune(events []*ScalingEvent, now time.Time) []*ScalingEvent {
+	SortEvents(events)
+
+	if h == nil {
+		return events
+	}
+
+	if h.RetentionHours > 0 {
+		cutoff := now.Add(-time.Duration(h.RetentionHours) * time.Hour)
+
+		i := sort.Search(len(events), func(i int) bool {
+			return !events[i].Timestamp.Before(cutoff)
+		})
+		events = events[i:]
+	}
+
+	if h.MaxEvents > 0 && len(events) > h.MaxEvents {
+		events = events[len(events)-h.MaxEvents:]
+	}
+
+	return events
+}
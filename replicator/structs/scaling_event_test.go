@@ -0,0 +1,48 @@
+package structs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScalingEvent_HistoryPath(t *testing.T) {
+	cases := map[string][2]string{
+		"replicator/config/state/nodes/example-pool": {
+			"replicator/config/history/nodes/example-pool", "example-pool"},
+		"replicator/config/state/jobs/example/cache": {
+			"replicator/config/history/jobs/example/cache", "example/cache"},
+		"replicator/config/state/unknown/example/web": {"", ""},
+	}
+
+	for statePath, expected := range cases {
+		path, resourceID := HistoryPath(statePath)
+		if path != expected[0] || resourceID != expected[1] {
+			t.Fatalf("expected history path %q and resource %q for %q but got "+
+				"%q and %q", expected[0], expected[1], statePath, path, resourceID)
+		}
+	}
+}
+
+func TestScalingEvent_Prune(t *testing.T) {
+	now := time.Now()
+
+	events := []*ScalingEvent{
+		{CountAfter: 3, Timestamp: now.Add(-1 * time.Hour)},
+		{CountAfter: 1, Timestamp: now.Add(-48 * time.Hour)},
+		{CountAfter: 2, Timestamp: now.Add(-2 * time.Hour)},
+		{CountAfter: 4, Timestamp: now},
+	}
+
+	retention := &History{MaxEvents: 2, RetentionHours: 24}
+	pruned := retention.Prune(events, now)
+
+	if len(pruned) != 2 || pruned[0].CountAfter != 3 || pruned[1].CountAfter != 4 {
+		t.Fatalf("expected the two most recent events to be retained but got %v",
+			pruned)
+	}
+
+	retention = &History{RetentionHours: 24}
+	if pruned = retention.Prune(events, now); len(pruned) != 3 || pruned[0].CountAfter != 2 {
+		t.Fatalf("expected events older than the retention period to be removed")
+	}
+}
@@ -1,6 +1,7 @@
 package structs
 
 import (
+	"reflect"
 	"sync"
 	"time"
 )
@@ -15,11 +16,22 @@ type ScalingState struct {
 	// tools.
 	FailsafeAdmin bool `json:"failsafe_admin"`
 
+	// FailsafeChangedBy records the operator who last administratively changed
+	// the failsafe mode of the resource. It is empty if failsafe mode was
+	// last changed automatically.
+	FailsafeChangedBy string `json:"failsafe_changed_by"`
+
 	// FailsafeMode represents the status of the failsafe circuit breaker. This
 	// will be tripped automatically when enough consecutive failures are
 	// encountered.
 	FailsafeMode bool `json:"failsafe_mode"`
 
+	// FailsafeReason describes why failsafe mode was last changed.
+	FailsafeReason string `json:"failsafe_reason"`
+
+	// FailsafeUpdated tracks the time failsafe mode was last changed.
+	FailsafeUpdated time.Time `json:"failsafe_updated"`
+
 	// FailureCount tracks the number of worker nodes that have failed to
 	// successfully join the worker pool after a scale-out operation.
 	FailureCount int `json:"failure_count"`
@@ -32,13 +44,44 @@ type ScalingState struct {
 	// completed a cluster scaling action.
 	LastScalingEvent time.Time `json:"last_scaling_event"`
 
+	// LastScaleInEvent represents the last time a scale-in operation was
+	// performed against the resource.
+	LastScaleInEvent time.Time `json:"last_scalein_event"`
+
+	// LastScaleOutEvent represents the last time a scale-out operation was
+	// performed against the resource.
+	LastScaleOutEvent time.Time `json:"last_scaleout_event"`
+
+	// LastUtilization tracks the utilization percentage of the resource at the
+	// last evaluation and is used to calculate the rate of change.
+	LastUtilization float64 `json:"last_utilization"`
+
+	// LastUtilizationSample tracks the time LastUtilization was recorded.
+	LastUtilizationSample time.Time `json:"last_utilization_sample"`
+
 	// LastUpdated tracks the last time the state tracking data was updated.
 	LastUpdated time.Time `json:"last_updated"`
 
+	// ModifyIndex is the index of the state object in persistent storage when
+	// it was last read or written and is used to perform check-and-set writes.
+	ModifyIndex uint64 `json:"-"`
+
 	// Lock provides a mutex lock to protect concurrent read/write
 	// access to the object.
 	Lock sync.RWMutex `json:"-"`
 
+	// PendingOperation records a scaling operation which has been started but
+	// not completed. Worker pool operations are recorded as a workflow whose
+	// current step is persisted before it is performed, job group operations
+	// are recorded when they are interrupted. If present when read by a newly
+	// elected leader, the operation was interrupted by leadership loss or
+	// shutdown and is completed, resumed or rolled back.
+	PendingOperation *PendingOperation `json:"pending_operation"`
+
+	// Pinned indicates the state has been administratively pinned and will be
+	// retained by garbage collection when the resource no longer exists.
+	Pinned bool `json:"pinned"`
+
 	// ResourceName provides a shortcut method for identifying the resource
 	// this state is associated with.
 	ResourceName string `json:"resource_name"`
@@ -58,6 +101,177 @@ type ScalingState struct {
 	// when a scaling operation is being requested.
 	ScalingDirection string `json:"-"`
 
+	// SchemaVersion is the version of the schema the state object was stored
+	// with. It is set to the current schema version when the state is
+	// persisted.
+	SchemaVersion int `json:"schema_version"`
+
 	// StatePath stores the path where the object should be persisted.
 	StatePath string `json:"state_path"`
+
+	// Tombstone records the time garbage collection first found the resource
+	// no longer exists. The state is deleted once the resource has been absent
+	// beyond the grace period.
+	Tombstone time.Time `json:"tombstone"`
+
+	// snapshot is a copy of the state object as it was last read from or
+	// written to persistent storage and is used to merge conflicting writes.
+	snapshot *ScalingState
+}
+
+// Define the steps of a worker pool scaling workflow. A scale-out operation
+// consists of the launch step, a scale-in operation of the drain, detach and
+// terminate steps performed in that order.
+const (
+	OperationStepLaunch    = "launch"
+	OperationStepDrain     = "drain"
+	OperationStepDetach    = "detach"
+	OperationStepTerminate = "terminate"
+)
+
+// PendingOperation describes a scaling operation which is in progress or was
+// interrupted before it completed.
+type PendingOperation struct {
+	// Direction is the scaling direction of the operation, if applicable.
+	Direction string `json:"direction"`
+
+	// NodeAddress is the address of the worker node targeted by a worker pool
+	// scale-in operation.
+	NodeAddress string `json:"node_address"`
+
+	// NodeID is the ID of the worker node targeted by a worker pool scale-in
+	// operation.
+	NodeID string `json:"node_id"`
+
+	// Started is the time the operation was started.
+	Started time.Time `json:"started"`
+
+	// Step is the step of a worker pool scaling workflow currently being
+	// performed. It is empty until the first step has started.
+	Step string `json:"step"`
+
+	// Type is the type of operation, either scale or resize.
+	Type string `json:"type"`
+}
+
+// AdvanceOperation records the step of the pending operation which is about
+// to be performed. The state object must be persisted before the step is
+// started for an interrupted operation to be recoverable.
+func (s *ScalingState) AdvanceOperation(step string) {
+	if s.PendingOperation != nil {
+		s.PendingOperation.Step = step
+	}
+}
+
+// RecordScalingEvent updates the last scaling event timestamps for the
+// supplied scaling direction.
+func (s *ScalingState) RecordScalingEvent(direction string) {
+	now := time.Now()
+	s.LastScalingEvent = now
+
+	switch direction {
+	case ScalingDirectionIn:
+		s.LastScaleInEvent = now
+	case ScalingDirectionOut:
+		s.LastScaleOutEvent = now
+	}
+}
+
+// UtilizationSlope records a utilization sample and returns the rate of
+// change in percentage points per minute since the previous sample. Zero is
+// returned if there is no previous sample to compare against.
+func (s *ScalingState) UtilizationSlope(utilization float64, now time.Time) (slope float64) {
+	if !s.LastUtilizationSample.IsZero() && now.After(s.LastUtilizationSample) {
+		elapsed := now.Sub(s.LastUtilizationSample).Minutes()
+		slope = (utilization - s.LastUtilization) / elapsed
+	}
+
+	s.LastUtilization = utilization
+	s.LastUtilizationSample = now
+
+	return
+}
+
+// Snapshot records a copy of the persisted fields of the state object. This
+// should be called whenever the state object is read from or written to
+// persistent storage.
+func (s *ScalingState) Snapshot() {
+	s.snapshot = &ScalingState{}
+	copyPersistedFields(s.snapshot, s)
+}
+
+// Merge performs a three-way merge of a conflicting state object read from
+// persistent storage. Fields modified locally since the last snapshot are
+// retained, all other fields take the value of the remote object. If no
+// snapshot has been taken all local fields are retained.
+func (s *ScalingState) Merge(remote *ScalingState) {
+	if s.snapshot == nil {
+		return
+	}
+
+	local := reflect.ValueOf(s).Elem()
+	base := reflect.ValueOf(s.snapshot).Elem()
+	latest := reflect.ValueOf(remote).Elem()
+
+	for i := 0; i < local.NumField(); i++ {
+		if !persistedField(local.Type().Field(i)) {
+			continue
+		}
+
+		if reflect.DeepEqual(local.Field(i).Interface(), base.Field(i).Interface()) {
+			local.Field(i).Set(latest.Field(i))
+		}
+	}
+}
+
+// Reset clears the persisted fields of the state object so that a resource
+// which has been recreated starts with a clean slate. Fields identifying the
+// resource and the pinned flag are retained.
+func (s *ScalingState) Reset() {
+	state := reflect.ValueOf(s).Elem()
+
+	for i := 0; i < state.NumField(); i++ {
+		field := state.Type().Field(i)
+		if !persistedField(field) {
+			continue
+		}
+
+		switch field.Name {
+		case "Pinned", "ResourceName", "ResourceType", "SchemaVersion", "StatePath":
+			continue
+		}
+
+		state.Field(i).Set(reflect.Zero(field.Type))
+	}
+}
+
+// copyPersistedFields copies the fields of a state object which are written
+// to persistent storage.
+func copyPersistedFields(dst, src *ScalingState) {
+	to := reflect.ValueOf(dst).Elem()
+	from := reflect.ValueOf(src).Elem()
+
+	for i := 0; i < from.NumField(); i++ {
+		if !persistedField(from.Type().Field(i)) {
+			continue
+		}
+
+		value := from.Field(i)
+		switch {
+		case value.Kind() == reflect.Slice && !value.IsNil():
+			value = reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0,
+				value.Len()), value)
+		case value.Kind() == reflect.Ptr && !value.IsNil():
+			ptr := reflect.New(value.Elem().Type())
+			ptr.Elem().Set(value.Elem())
+			value = ptr
+		}
+		to.Field(i).Set(value)
+	}
+}
+
+// persistedField determines whether a state object field is written to
+// persistent storage.
+func persistedField(field reflect.StructField) bool {
+	return field.PkgPath == "" && field.Tag.Get("json") != "-"
 }
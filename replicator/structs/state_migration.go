@@ -0,0 +1,82 @@
+package structs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StateSchemaVersion is the current version of the persisted scaling state
+// schema. State documents written before versioning was introduced have no
+// schema version and are treated as version 1.
+const StateSchemaVersion = 2
+
+// StateMigration upgrades a decoded state document by a single schema
+// version.
+type StateMigration func(map[string]interface{}) error
+
+// stateMigrations registers the migration which upgrades a state document
+// from the schema version used as the key to the next version.
+var stateMigrations = map[int]StateMigration{
+	1: migrateStateV1,
+}
+
+// MigrateState upgrades a raw state document to the current schema version.
+// The upgraded document is returned along with the schema version the
+// document was stored with. Documents written with a newer schema version
+// than is supported are rejected to avoid misreading them.
+func MigrateState(data []byte) ([]byte, int, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	version := 1
+	if v, ok := doc["schema_version"].(float64); ok && v > 0 {
+		version = int(v)
+	}
+
+	if version > StateSchemaVersion {
+		return nil, version, fmt.Errorf("state schema version %v is newer than "+
+			"the supported version %v", version, StateSchemaVersion)
+	}
+
+	if version == StateSchemaVersion {
+		return data, version, nil
+	}
+
+	for v := version; v < StateSchemaVersion; v++ {
+		migration, ok := stateMigrations[v]
+		if !ok {
+			return nil, version, fmt.Errorf("no migration is registered for "+
+				"state schema version %v", v)
+		}
+
+		if err := migration(doc); err != nil {
+			return nil, version, fmt.Errorf("unable to migrate state from schema "+
+				"version %v: %v", v, err)
+		}
+	}
+
+	// The stored schema version is retained so callers can determine that the
+	// document requires rewriting; it is updated when the state is persisted.
+	upgraded, err := json.Marshal(doc)
+	return upgraded, version, err
+}
+
+// migrateStateV1 upgrades unversioned state documents, which predate
+// direction-specific scaling events. The last scaling event is used for both
+// directions so cooldowns continue to be honoured after an upgrade.
+func migrateStateV1(doc map[string]interface{}) error {
+	last, ok := doc["last_scaling_event"]
+	if !ok {
+		return nil
+	}
+
+	for _, key := range []string{"last_scalein_event", "last_scaleout_event"} {
+		if _, ok := doc[key]; !ok {
+			doc[key] = last
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package structs
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStateMigration_MigrateState(t *testing.T) {
+	legacy := []byte(`{"failsafe_mode":true,` +
+		`"last_scaling_event":"2018-01-01T00:00:00Z",` +
+		`"state_path":"replicator/config/state/nodes/example-pool"}`)
+
+	upgraded, version, err := MigrateState(legacy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != 1 {
+		t.Fatalf("expected stored version 1 but got %v", version)
+	}
+
+	state := &ScalingState{}
+	if err := json.Unmarshal(upgraded, state); err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.FailsafeMode {
+		t.Fatalf("expected existing fields to be retained: %+v", state)
+	}
+	if state.LastScaleInEvent != state.LastScalingEvent ||
+		state.LastScaleOutEvent != state.LastScalingEvent {
+		t.Fatalf("expected directional events to be populated: %+v", state)
+	}
+
+	// Documents at the current version are returned unmodified.
+	current := []byte(`{"schema_version":2,"failure_count":1}`)
+	upgraded, version, err = MigrateState(current)
+	if err != nil || version != StateSchemaVersion || string(upgraded) != string(current) {
+		t.Fatalf("expected current document to be unmodified: %s, %v, %v",
+			upgraded, version, err)
+	}
+
+	// Documents from a newer schema version are rejected.
+	if _, _, err := MigrateState([]byte(`{"schema_version":99}`)); err == nil {
+		t.Fatalf("expected error migrating a newer schema version")
+	}
+}
@@ -0,0 +1,49 @@
+package structs
+
+// Define the supported state storage backends.
+const (
+	StateBackendBolt   = "bolt"
+	StateBackendConsul = "consul"
+	StateBackendRaft   = "raft"
+)
+
+// The StateStore interface is used to provide common method signatures for
+// persisting and retrieving scaling state regardless of the backend used.
+type StateStore interface {
+	// AppendEvent adds an event to the scaling event history stored at the
+	// provided path, removing events which fall outside the retention policy.
+	AppendEvent(string, *ScalingEvent, *History) error
+
+	// DeleteState removes the state object and scaling event history of a
+	// resource from persistent storage. The delete is only performed if the
+	// state has not been modified since it was last read or written.
+	DeleteState(*ScalingState) error
+
+	// ListEvents returns all scaling events stored at locations beginning with
+	// the provided path prefix, sorted oldest first.
+	ListEvents(string) ([]*ScalingEvent, error)
+
+	// ListState returns all state objects stored at locations beginning with
+	// the provided path prefix. Each state object tracks its modify index so
+	// that it may be subsequently written or deleted.
+	ListState(string) ([]*ScalingState, error)
+
+	// PersistACL stores the ACL tokens and policies at the path referenced by
+	// the ACL state. The write is only performed if the ACL state has not been
+	// modified since it was last read or written, a modify index of zero
+	// requiring that no ACL state exists.
+	PersistACL(*ACLState) error
+
+	// PersistState is responsible for persistently storing scaling state
+	// information at the path referenced by the state object.
+	PersistState(*ScalingState) error
+
+	// ReadACL reads the ACL tokens and policies from the path referenced by
+	// the ACL state. The ACL state is left unmodified if none exists.
+	ReadACL(*ACLState) error
+
+	// ReadState attempts to read state tracking information from the path
+	// referenced by the state object. If no state exists and the boolean is
+	// true, an initial state object is persisted.
+	ReadState(*ScalingState, bool)
+}
@@ -0,0 +1,71 @@
+package structs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestState_Merge(t *testing.T) {
+	local := &ScalingState{
+		FailsafeMode: true,
+		FailureCount: 3,
+	}
+	local.Snapshot()
+
+	// The local copy records a scaling event while the remote copy has had
+	// failsafe mode administratively disabled.
+	local.RecordScalingEvent(ScalingDirectionOut)
+
+	remote := &ScalingState{
+		FailsafeAdmin: true,
+		FailsafeMode:  false,
+		FailureCount:  0,
+	}
+
+	local.Merge(remote)
+
+	if local.FailsafeMode || !local.FailsafeAdmin || local.FailureCount != 0 {
+		t.Fatalf("expected remote failsafe changes to be retained: %+v", local)
+	}
+	if local.LastScaleOutEvent.IsZero() || local.LastScalingEvent.IsZero() {
+		t.Fatalf("expected local scaling event to be retained: %+v", local)
+	}
+}
+
+func TestState_MergeWithoutSnapshot(t *testing.T) {
+	now := time.Now()
+	local := &ScalingState{FailsafeMode: true, LastScalingEvent: now}
+
+	local.Merge(&ScalingState{})
+
+	if !local.FailsafeMode || local.LastScalingEvent != now {
+		t.Fatalf("expected local state to be retained without a snapshot: %+v", local)
+	}
+}
+
+func TestState_Reset(t *testing.T) {
+	state := &ScalingState{
+		EligibleNodes: []string{"10.0.0.1"},
+		FailsafeMode:  true,
+		FailureCount:  3,
+		ModifyIndex:   42,
+		Pinned:        true,
+		ResourceName:  "cache",
+		ResourceType:  "job_group",
+		SchemaVersion: StateSchemaVersion,
+		StatePath:     "replicator/config/state/jobs/example/cache",
+		Tombstone:     time.Now(),
+	}
+
+	state.Reset()
+
+	if state.FailsafeMode || state.FailureCount != 0 ||
+		state.EligibleNodes != nil || !state.Tombstone.IsZero() {
+		t.Fatalf("expected state to be cleared: %+v", state)
+	}
+	if !state.Pinned || state.ResourceName != "cache" ||
+		state.ResourceType != "job_group" || state.ModifyIndex != 42 ||
+		state.StatePath != "replicator/config/state/jobs/example/cache" {
+		t.Fatalf("expected resource identity to be retained: %+v", state)
+	}
+}
@@ -4,4 +4,8 @@ package structs
 type LeaderResponse struct {
 	SessionID string
 	NodeID    string
+
+	// RPCAddr is the advertised RPC address of the leader, used by followers
+	// to forward requests.
+	RPCAddr string
 }
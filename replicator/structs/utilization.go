@@ -0,0 +1,153 @@
+package structs
+
+import (
+	"sync"
+	"time"
+)
+
+// NewUtilizationHistory returns a new UtilizationHistory object which retains
+// up to the specified number of samples for each task.
+func NewUtilizationHistory(maxSamples int) *UtilizationHistory {
+	return &UtilizationHistory{
+		MaxSamples: maxSamples,
+		Tasks:      make(map[string]*TaskUtilization),
+	}
+}
+
+// UtilizationHistory tracks a rolling history of resource utilization samples
+// for each task Replicator evaluates. The object contains a lock to provide
+// mutual exclusion protection.
+type UtilizationHistory struct {
+	// Lock provides a mutex lock to protect concurrent read/write access to
+	// the object.
+	Lock sync.RWMutex
+
+	// MaxSamples is the number of samples retained for each task, once this is
+	// reached the oldest sample is discarded.
+	MaxSamples int
+
+	// Tasks stores the utilization history of each task keyed by
+	// job/group/task.
+	Tasks map[string]*TaskUtilization
+}
+
+// TaskUtilization represents the resources allocated to a task along with a
+// rolling history of the resources it has consumed.
+type TaskUtilization struct {
+	// JobID is the ID of the job the task belongs to.
+	JobID string
+
+	// GroupName is the name of the task group the task belongs to.
+	GroupName string
+
+	// TaskName is the name given to the task within the job specification.
+	TaskName string
+
+	// CPUMHz is the CPU resource currently defined in the job specification.
+	CPUMHz int
+
+	// MemoryMB is the memory resource currently defined in the job
+	// specification.
+	MemoryMB int
+
+	// Samples is the rolling history of utilization samples, oldest first.
+	Samples []UtilizationSample
+}
+
+// UtilizationSample is a point in time measurement of the resources consumed
+// by a single task allocation.
+type UtilizationSample struct {
+	// Timestamp is the time at which the sample was taken.
+	Timestamp time.Time
+
+	// CPUMHz is the CPU consumed by the task in MHz.
+	CPUMHz float64
+
+	// MemoryMB is the resident memory consumed by the task in MB.
+	MemoryMB float64
+}
+
+// Recommendation is a right-sizing recommendation for the resources of a
+// single task based on its utilization history.
+type Recommendation struct {
+	// JobID is the ID of the job the task belongs to.
+	JobID string
+
+	// GroupName is the name of the task group the task belongs to.
+	GroupName string
+
+	// TaskName is the name given to the task within the job specification.
+	TaskName string
+
+	// Samples is the number of utilization samples the recommendation was
+	// calculated from.
+	Samples int
+
+	// CPUMHz is the CPU resource currently defined in the job specification.
+	CPUMHz int
+
+	// MemoryMB is the memory resource currently defined in the job
+	// specification.
+	MemoryMB int
+
+	// ObservedCPUMHz is the CPU utilization at the configured percentile.
+	ObservedCPUMHz float64
+
+	// ObservedMemoryMB is the memory utilization at the configured percentile.
+	ObservedMemoryMB float64
+
+	// RecommendedCPUMHz is the suggested CPU resource for the task.
+	RecommendedCPUMHz int
+
+	// RecommendedMemoryMB is the suggested memory resource for the task.
+	RecommendedMemoryMB int
+}
+
+// RecommendationListResponse is used for the Recommendations.List response.
+type RecommendationListResponse struct {
+	Recommendations []*Recommendation
+}
+
+// Record adds a utilization sample to the history of the specified task,
+// discarding the oldest sample if the history is full.
+func (u *UtilizationHistory) Record(jobID, groupName, taskName string,
+	cpu, memory int, sample UtilizationSample) {
+
+	u.Lock.Lock()
+	defer u.Lock.Unlock()
+
+	key := jobID + "/" + groupName + "/" + taskName
+
+	task, ok := u.Tasks[key]
+	if !ok {
+		task = &TaskUtilization{
+			JobID:     jobID,
+			GroupName: groupName,
+			TaskName:  taskName,
+		}
+		u.Tasks[key] = task
+	}
+
+	// Always track the most recently observed resource definition so changes
+	// to the job specification are reflected in recommendations.
+	task.CPUMHz = cpu
+	task.MemoryMB = memory
+
+	task.Samples = append(task.Samples, sample)
+	if u.MaxSamples > 0 && len(task.Samples) > u.MaxSamples {
+		task.Samples = task.Samples[len(task.Samples)-u.MaxSamples:]
+	}
+}
+
+// Remove deletes the utilization history of all tasks belonging to the
+// specified job.
+func (u *UtilizationHistory) Remove(jobID string) {
+	u.Lock.Lock()
+	defer u.Lock.Unlock()
+
+	for key, task := range u.Tasks {
+		if task.JobID == jobID {
+			delete(u.Tasks, key)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/elsevier-core-engineering/replicator/logging"
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+// verticalScaling evaluates whether the tasks of a job group require their
+// resources to be adjusted and, if so, submits the resize request.
+func (s *Server) verticalScaling(ctx context.Context, jobName string,
+	group *structs.GroupScalingPolicy, state *structs.ScalingState) {
+
+	targets, err := verticalScalingTargets(jobName, group, s.utilization,
+		s.Config().RightSizing)
+	if err != nil {
+		logging.Error("core/vertical_scaling: unable to evaluate vertical scaling "+
+			"for job \"%v\" and group \"%v\": %v", jobName, group.GroupName, err)
+		return
+	}
+
+	if len(targets) == 0 {
+		logging.Debug("core/vertical_scaling: resources of job \"%v\" and group "+
+			"\"%v\" are within the change threshold of %v%%, no resize required",
+			jobName, group.GroupName, group.VerticalThreshold)
+		return
+	}
+
+	logging.Debug("core/vertical_scaling: vertical scaling for job \"%v\" and "+
+		"group \"%v\" is enabled; a resize of %v task(s) will be requested",
+		jobName, group.GroupName, len(targets))
+
+	event := s.Config().NomadClient.JobGroupResize(ctx, jobName, group, targets,
+		state)
+	if event != nil {
+		event.Metrics = jobGroupMetrics(group, 0)
+		recordJobGroupEvent(s.Config(), state, event)
+	}
+}
+
+// verticalScalingTargets calculates the resources each task in a job group
+// should be resized to. Recommendations are clamped to the bounds declared
+// in the group policy and only tasks whose resources would change by at least
+// the policy threshold are returned.
+func verticalScalingTargets(jobName string, group *structs.GroupScalingPolicy,
+	history *structs.UtilizationHistory,
+	config *structs.RightSizing) (targets []*structs.TaskResources, err error) {
+
+	if group.VerticalCPUMax == 0 || group.VerticalMemMax == 0 {
+		return nil, fmt.Errorf("replicator_vertical_cpu_max and " +
+			"replicator_vertical_mem_max must be declared to enable vertical scaling")
+	}
+
+	cpuMin := group.VerticalCPUMin
+	if cpuMin < minimumCPUMHz {
+		cpuMin = minimumCPUMHz
+	}
+
+	memMin := group.VerticalMemMin
+	if memMin < minimumMemoryMB {
+		memMin = minimumMemoryMB
+	}
+
+	if cpuMin > group.VerticalCPUMax || memMin > group.VerticalMemMax {
+		return nil, fmt.Errorf("vertical scaling minimum bounds exceed the " +
+			"maximum bounds")
+	}
+
+	for _, r := range buildRecommendations(history, config) {
+		if r.JobID != jobName || r.GroupName != group.GroupName {
+			continue
+		}
+
+		cpu := clamp(r.RecommendedCPUMHz, cpuMin, group.VerticalCPUMax)
+		mem := clamp(r.RecommendedMemoryMB, memMin, group.VerticalMemMax)
+
+		if !exceedsThreshold(r.CPUMHz, cpu, group.VerticalThreshold) &&
+			!exceedsThreshold(r.MemoryMB, mem, group.VerticalThreshold) {
+			continue
+		}
+
+		targets = append(targets, &structs.TaskResources{
+			TaskName: r.TaskName,
+			CPUMHz:   cpu,
+			MemoryMB: mem,
+		})
+	}
+
+	return
+}
+
+// clamp restricts a value to the supplied inclusive bounds.
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// exceedsThreshold determines whether the change from the current to the
+// target value is at least the threshold percentage of the current value.
+func exceedsThreshold(current, target int, threshold float64) bool {
+	if current == target {
+		return false
+	}
+	if current == 0 {
+		return true
+	}
+
+	change := math.Abs(float64(target-current)) / float64(current) * 100
+	return change >= threshold
+}
@@ -0,0 +1,60 @@
+package replicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elsevier-core-engineering/replicator/replicator/structs"
+)
+
+func TestVerticalScaling_verticalScalingTargets(t *testing.T) {
+	config := &structs.RightSizing{
+		CPUPercentile:    100,
+		HeadroomPercent:  0,
+		MemoryPercentile: 100,
+		MinSamples:       1,
+	}
+
+	group := &structs.GroupScalingPolicy{
+		GroupName:         "cache",
+		VerticalCPUMax:    400,
+		VerticalCPUMin:    100,
+		VerticalMemMax:    512,
+		VerticalMemMin:    64,
+		VerticalThreshold: 10,
+	}
+
+	history := structs.NewUtilizationHistory(10)
+
+	// Task requires CPU below the minimum bound and memory above the maximum.
+	history.Record("example", "cache", "redis", 500, 256,
+		structs.UtilizationSample{Timestamp: time.Now(), CPUMHz: 50, MemoryMB: 900})
+
+	// Task whose resources are within the change threshold.
+	history.Record("example", "cache", "sidecar", 100, 128,
+		structs.UtilizationSample{Timestamp: time.Now(), CPUMHz: 105, MemoryMB: 120})
+
+	// Task belonging to another group.
+	history.Record("example", "web", "nginx", 500, 256,
+		structs.UtilizationSample{Timestamp: time.Now(), CPUMHz: 50, MemoryMB: 50})
+
+	targets, err := verticalScalingTargets("example", group, history, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(targets) != 1 {
+		t.Fatalf("expected 1 target but got %v", len(targets))
+	}
+
+	target := targets[0]
+	if target.TaskName != "redis" || target.CPUMHz != 100 || target.MemoryMB != 512 {
+		t.Fatalf("expected redis resized to 100 MHz and 512 MB but got %v: %v MHz "+
+			"and %v MB", target.TaskName, target.CPUMHz, target.MemoryMB)
+	}
+
+	group.VerticalMemMax = 0
+	if _, err := verticalScalingTargets("example", group, history, config); err == nil {
+		t.Fatalf("expected error when maximum bounds are not declared")
+	}
+}